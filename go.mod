@@ -5,9 +5,11 @@ go 1.22
 toolchain go1.23.2
 
 require (
+	filippo.io/age v1.2.1
 	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/pterm/pterm v0.12.80
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/term v0.28.0
 )
 
 require (
@@ -22,7 +24,7 @@ require (
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
+	golang.org/x/crypto v0.24.0 // indirect
 	golang.org/x/sys v0.29.0 // indirect
-	golang.org/x/term v0.28.0 // indirect
 	golang.org/x/text v0.20.0 // indirect
 )
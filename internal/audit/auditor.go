@@ -0,0 +1,242 @@
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"nrmgo/internal/config"
+	"nrmgo/internal/latency"
+)
+
+const (
+	// defaultTimeout 单个请求的超时时间
+	defaultTimeout = 10 * time.Second
+	// defaultConcurrency 默认的并发审计数量
+	defaultConcurrency = 5
+	// maxRedirects 跟随重定向的最大次数
+	maxRedirects = 10
+)
+
+// Auditor 传输安全审计器
+type Auditor struct {
+	opts        *latency.Options
+	expiryDays  int
+	concurrency int
+	now         func() time.Time
+}
+
+// NewAuditor 创建审计器，证书在 expiryDays 天内过期时给出警告
+func NewAuditor(opts *latency.Options, expiryDays int) *Auditor {
+	if opts == nil {
+		opts = latency.DefaultOptions()
+	}
+	auditOpts := *opts
+	auditOpts.Timeout = defaultTimeout
+
+	return &Auditor{
+		opts:        &auditOpts,
+		expiryDays:  expiryDays,
+		concurrency: defaultConcurrency,
+		now:         time.Now,
+	}
+}
+
+// NewAuditorFromConfig 从配置创建审计器，opts 为与延迟测试相同的网络选项
+func NewAuditorFromConfig(cfg *config.Config, opts *latency.Options) *Auditor {
+	expiryDays := config.DefaultCertExpiryDays
+	if cfg != nil && cfg.Security.CertExpiryDays > 0 {
+		expiryDays = cfg.Security.CertExpiryDays
+	}
+
+	a := NewAuditor(opts, expiryDays)
+	if cfg != nil && cfg.LatencyConcurrency() > 0 {
+		a.concurrency = cfg.LatencyConcurrency()
+	}
+	return a
+}
+
+// WithExpiryDays 设置证书即将过期的提醒天数
+func (a *Auditor) WithExpiryDays(days int) *Auditor {
+	a.expiryDays = days
+	return a
+}
+
+// Audit 并发审计多个 registry，结果顺序与 targets 一致
+func (a *Auditor) Audit(ctx context.Context, targets []Target) []*Result {
+	results := make([]*Result, len(targets))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, a.concurrency)
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(index int, tgt Target) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // 获取信号量
+			defer func() { <-semaphore }() // 释放信号量
+
+			results[index] = a.auditOne(ctx, tgt)
+		}(i, target)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// auditOne 审计单个 registry
+func (a *Auditor) auditOne(ctx context.Context, target Target) *Result {
+	result := &Result{
+		Name: target.Name,
+		URL:  target.URL,
+	}
+
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid url: %v", err)
+		return result
+	}
+	if u.Scheme == "http" {
+		result.add(CheckPlainHTTP, SeverityCritical, "registry uses plain http, traffic and tokens can be intercepted")
+	}
+
+	// 证书由审计器自行校验，以便区分具体的问题
+	client := a.client(&tls.Config{InsecureSkipVerify: true})
+	resp, err := a.get(ctx, client, target.URL)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	resp.Body.Close()
+
+	if resp.TLS != nil {
+		a.checkTLS(ctx, result, u.Hostname(), resp)
+	}
+	a.checkRedirects(ctx, client, result, resp)
+
+	return result
+}
+
+// client 创建不跟随重定向的 HTTP 客户端
+func (a *Auditor) client(tlsConfig *tls.Config) *http.Client {
+	client := latency.NewHTTPClient(a.opts)
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		transport.TLSClientConfig = tlsConfig
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// get 发送 GET 请求，调用方负责关闭响应体
+func (a *Auditor) get(ctx context.Context, client *http.Client, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", a.opts.UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp, nil
+}
+
+// checkTLS 检查证书、TLS 版本与 HSTS
+// 客户端默认只接受 TLS 1.2 及以上，旧版本协议由单独的请求检查
+func (a *Auditor) checkTLS(ctx context.Context, result *Result, host string, resp *http.Response) {
+	state := resp.TLS
+	result.TLSVersion = tls.VersionName(state.Version)
+
+	a.checkCertificate(result, host, state.PeerCertificates)
+
+	if resp.Header.Get("Strict-Transport-Security") == "" {
+		result.add(CheckMissingHSTS, SeverityWarning, "no Strict-Transport-Security header")
+	}
+
+	// 检查服务器是否仍接受 TLS 1.0/1.1，这里只关心协议版本
+	legacy := a.client(&tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS10,
+		MaxVersion:         tls.VersionTLS11,
+	})
+	if legacyResp, err := a.get(ctx, legacy, result.URL); err == nil {
+		legacyResp.Body.Close()
+		result.add(CheckLegacyTLS, SeverityWarning,
+			fmt.Sprintf("server still accepts %s", tls.VersionName(legacyResp.TLS.Version)))
+	}
+}
+
+// checkCertificate 校验证书链、主机名与有效期
+func (a *Auditor) checkCertificate(result *Result, host string, certs []*x509.Certificate) {
+	if len(certs) == 0 {
+		result.add(CheckUntrustedCert, SeverityCritical, "server sent no certificate")
+		return
+	}
+	leaf := certs[0]
+	result.CertExpiry = leaf.NotAfter
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	now := a.now()
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+
+	var (
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+		authErr    x509.UnknownAuthorityError
+	)
+	switch {
+	case err == nil:
+	case errors.As(err, &hostErr):
+		result.add(CheckHostnameMismatch, SeverityCritical, fmt.Sprintf("certificate is not valid for %s", host))
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		result.add(CheckCertExpired, SeverityCritical, fmt.Sprintf("certificate expired on %s", leaf.NotAfter.Format(time.DateOnly)))
+		return
+	case errors.As(err, &authErr):
+		result.add(CheckUntrustedCert, SeverityCritical, "certificate is signed by an unknown authority")
+	default:
+		result.add(CheckUntrustedCert, SeverityCritical, fmt.Sprintf("certificate verification failed: %v", err))
+	}
+
+	if left := leaf.NotAfter.Sub(now); left < time.Duration(a.expiryDays)*24*time.Hour {
+		result.add(CheckCertExpiring, SeverityWarning,
+			fmt.Sprintf("certificate expires in %d days (%s)", int(left.Hours()/24), leaf.NotAfter.Format(time.DateOnly)))
+	}
+}
+
+// checkRedirects 跟随重定向，检查是否从 HTTPS 降级到 HTTP
+func (a *Auditor) checkRedirects(ctx context.Context, client *http.Client, result *Result, resp *http.Response) {
+	for hops := 0; hops < maxRedirects; hops++ {
+		location, err := resp.Location()
+		if err != nil {
+			return
+		}
+		if resp.Request.URL.Scheme == "https" && location.Scheme == "http" {
+			result.add(CheckDowngrade, SeverityCritical, fmt.Sprintf("redirects from https to %s", location.Redacted()))
+			return
+		}
+
+		next, err := a.get(ctx, client, location.String())
+		if err != nil {
+			return
+		}
+		next.Body.Close()
+		resp = next
+	}
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"time"
+)
+
+// Target 表示一个待审计的 registry
+type Target struct {
+	Name string // registry 名称
+	URL  string // registry 地址
+}
+
+// Severity 表示问题的严重程度
+type Severity int
+
+const (
+	// SeverityWarning 存在风险，建议处理
+	SeverityWarning Severity = iota
+	// SeverityCritical 传输不安全，应当避免使用
+	SeverityCritical
+)
+
+// String 实现 fmt.Stringer 接口
+func (s Severity) String() string {
+	if s == SeverityCritical {
+		return "critical"
+	}
+	return "warning"
+}
+
+// 审计检查项
+const (
+	CheckPlainHTTP        = "plain-http"         // 使用明文 HTTP
+	CheckCertExpired      = "cert-expired"       // 证书已过期
+	CheckCertExpiring     = "cert-expiring"      // 证书即将过期
+	CheckHostnameMismatch = "hostname-mismatch"  // 证书与主机名不匹配
+	CheckUntrustedCert    = "untrusted-cert"     // 证书不受信任
+	CheckLegacyTLS        = "legacy-tls"         // 服务器仍接受 TLS 1.0/1.1
+	CheckMissingHSTS      = "missing-hsts"       // 缺少 Strict-Transport-Security 响应头
+	CheckDowngrade        = "downgrade-redirect" // 重定向到 HTTP
+)
+
+// Finding 表示一个审计发现的问题
+type Finding struct {
+	Check    string   // 检查项
+	Severity Severity // 严重程度
+	Message  string   // 说明
+}
+
+// Result 表示单个 registry 的审计结果
+type Result struct {
+	Name       string    // registry 名称
+	URL        string    // registry 地址
+	TLSVersion string    // 协商的 TLS 版本，HTTP 时为空
+	CertExpiry time.Time // 证书过期时间，HTTP 时为零值
+	Findings   []Finding // 发现的问题
+	Error      string    // 无法完成审计时的错误信息
+}
+
+// Critical 判断是否存在严重问题
+func (r *Result) Critical() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
+
+// Passed 判断是否通过审计（允许存在警告）
+func (r *Result) Passed() bool {
+	return r.Error == "" && !r.Critical()
+}
+
+// add 记录一个问题
+func (r *Result) add(check string, severity Severity, message string) {
+	r.Findings = append(r.Findings, Finding{Check: check, Severity: severity, Message: message})
+}
@@ -0,0 +1,236 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 归档备份的扩展名，加密的归档命名为 <ID>.<触发方式>.tar.gz.age
+const (
+	archiveExt   = ".tar.gz"
+	encryptedExt = ".tar.gz.age"
+)
+
+// maxArchiveFileSize 归档中单个文件的最大大小，配置文件不会超过该大小
+const maxArchiveFileSize = 64 << 20
+
+// writeArchive 将备份写入单个 .tar.gz，设置了接收者时加密为 age 文件，返回归档文件路径
+// 归档中第一个文件为 manifest.json，配置文件按清单中的相对路径保存
+func (s *DirStore) writeArchive(manifest *Manifest, entries []Entry) (string, error) {
+	data, err := buildArchive(manifest, entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	target := filepath.Join(s.Root, manifest.ID+archiveExt)
+	if len(s.Recipients) > 0 {
+		// 清单只保存在加密的归档中，文件名中的触发方式供保留策略在不解密时使用
+		if data, err = Encrypt(data, s.Recipients); err != nil {
+			return "", fmt.Errorf("failed to encrypt backup: %w", err)
+		}
+		target = filepath.Join(s.Root, fmt.Sprintf("%s.%s%s", manifest.ID, manifest.Trigger, encryptedExt))
+	}
+
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Base(target), err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		_ = os.Remove(target)
+		return "", fmt.Errorf("failed to write %s: %w", filepath.Base(target), err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(target)
+		return "", fmt.Errorf("failed to write %s: %w", filepath.Base(target), err)
+	}
+	return target, nil
+}
+
+// buildArchive 在内存中生成 .tar.gz
+func buildArchive(manifest *Manifest, entries []Entry) ([]byte, error) {
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    fileMode,
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+			Format:  tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := write(manifestFile, append(manifestData, '\n')); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if err := write(entry.Path, entry.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readArchive 读取 .tar.gz 中的所有文件
+func readArchive(data []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxArchiveFileSize {
+			return nil, fmt.Errorf("invalid archive: %s is too large", header.Name)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+		files[path.Clean(header.Name)] = content
+	}
+}
+
+// parseManifest 解析清单内容
+func parseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", manifestFile, err)
+	}
+	return &manifest, nil
+}
+
+// parseArchiveName 从归档文件名中解析备份 ID、是否加密以及加密归档的触发方式
+func parseArchiveName(name string) (Snapshot, bool) {
+	if rest, ok := strings.CutSuffix(name, encryptedExt); ok {
+		id, trigger, _ := strings.Cut(rest, ".")
+		return Snapshot{ID: id, Archive: true, Encrypted: true, trigger: Trigger(trigger)}, true
+	}
+	if id, ok := strings.CutSuffix(name, archiveExt); ok {
+		return Snapshot{ID: id, Archive: true}, true
+	}
+	return Snapshot{}, false
+}
+
+// readArchiveSnapshot 读取归档备份中的 manifest.json
+// 加密的归档只有在本次命令中已经解密过时才有清单，否则保持锁定，需要时由 Unlock 解密
+func (s *DirStore) readArchiveSnapshot(snapshot *Snapshot) error {
+	if snapshot.Encrypted {
+		files, ok := s.archives[snapshot.Path]
+		if !ok {
+			return nil
+		}
+		content, ok := files[manifestFile]
+		if !ok {
+			return fmt.Errorf("archive has no %s", manifestFile)
+		}
+		var err error
+		snapshot.Manifest, err = parseManifest(content)
+		return err
+	}
+
+	data, err := os.ReadFile(snapshot.Path)
+	if err != nil {
+		return err
+	}
+	files, err := readArchive(data)
+	if err != nil {
+		return err
+	}
+	content, ok := files[manifestFile]
+	if !ok {
+		return fmt.Errorf("archive has no %s", manifestFile)
+	}
+	if snapshot.Manifest, err = parseManifest(content); err != nil {
+		return err
+	}
+	s.cacheArchive(snapshot.Path, files)
+	return nil
+}
+
+// cacheArchive 缓存已读取的归档内容，同一次命令中不必重复解压或解密
+func (s *DirStore) cacheArchive(path string, files map[string][]byte) {
+	if s.archives == nil {
+		s.archives = make(map[string]map[string][]byte)
+	}
+	s.archives[path] = files
+}
+
+// archiveFiles 返回归档备份中的所有文件，加密的归档使用 Identities 解密
+func (s *DirStore) archiveFiles(snapshot *Snapshot) (map[string][]byte, error) {
+	if files, ok := s.archives[snapshot.Path]; ok {
+		return files, nil
+	}
+
+	data, err := os.ReadFile(snapshot.Path)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Encrypted {
+		if len(s.Identities) == 0 {
+			return nil, fmt.Errorf("backup %s is encrypted, configure [backup] identity_file or a passphrase to read it", snapshot.ID)
+		}
+		if data, err = Decrypt(data, s.Identities); err != nil {
+			return nil, fmt.Errorf("failed to decrypt backup %s: %w", snapshot.ID, err)
+		}
+	}
+
+	files, err := readArchive(data)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheArchive(snapshot.Path, files)
+	return files, nil
+}
+
+// readArchiveFile 读取归档备份中的文件，归档中没有该文件时返回的错误满足 os.IsNotExist
+func (s *DirStore) readArchiveFile(snapshot *Snapshot, rel string) ([]byte, error) {
+	files, err := s.archiveFiles(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := files[path.Clean(rel)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: rel, Err: fs.ErrNotExist}
+	}
+	return data, nil
+}
@@ -1,109 +1,283 @@
 package backup
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"nrmgo/internal/checker"
+)
+
+// 备份目录与文件的权限，配置文件中常有 registry 凭据，只允许当前用户访问
+const (
+	dirMode  = 0700
+	fileMode = 0600
 )
 
 // Backup 执行备份
-func (bm *BackupManager) Backup(managers []string) ([]BackupResult, error) {
-	// 创建 backups 根目录
-	backupsRoot := filepath.Join(bm.ExecPath, "backups")
-	if err := os.MkdirAll(backupsRoot, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create backups directory: %w", err)
+// 每个包管理器各层级的配置文件保存在以其名称与层级命名的子目录中，并写入 manifest.json 记录来源与校验信息
+// 备份的保存方式由 Store 决定
+func (bm *BackupManager) Backup(managers []string, trigger Trigger) ([]BackupResult, error) {
+	return bm.backup(managers, trigger, "")
+}
+
+// AutoBackup 在 command 修改配置文件前自动备份即将被修改的文件
+// 不存在的文件在清单中记录为缺失，所有文件都不存在时同样创建备份，clean 据此删除之后才创建的文件
+func (bm *BackupManager) AutoBackup(command string, managers []string) ([]BackupResult, error) {
+	return bm.backup(managers, TriggerAuto, command)
+}
+
+// AutoBackupItems 在 command 覆盖恢复项的目标文件前备份这些文件
+// 只备份恢复项的目标文件，它们不必在包管理器当前发现的配置文件中，例如在其他目录记录的项目配置文件
+func (bm *BackupManager) AutoBackupItems(command string, items []*RestoreItem) ([]BackupResult, error) {
+	targets := &BackupManager{
+		ExecPath: bm.ExecPath,
+		Managers: make(map[string]*Manager),
+		Version:  bm.Version,
+		Store:    bm.Store,
 	}
 
-	// 生成备份目录
-	timestamp := time.Now().Format("20060102_150405")
-	backupDir := filepath.Join(backupsRoot, timestamp)
+	var managers []string
+	for _, item := range items {
+		name := item.Managers[0]
+		manager, ok := targets.Managers[name]
+		if !ok {
+			manager = &Manager{Name: name}
+			if current := bm.GetManager(name); current != nil {
+				manager.Version = current.Version
+				manager.Registry = current.Registry
+				manager.Installed = current.Installed
+			}
+			targets.Managers[name] = manager
+			managers = append(managers, name)
+		}
+		manager.Files = append(manager.Files, checker.ConfigFile{
+			Manager: name,
+			Scope:   checker.ConfigScope(item.Scope),
+			Path:    item.TargetPath,
+			Exists:  item.Exists,
+		})
+	}
+	if len(managers) == 0 {
+		return nil, nil
+	}
+	return targets.backup(managers, TriggerAuto, command)
+}
 
-	// 创建备份目录
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+// backup 执行备份，command 为触发自动备份的命令
+func (bm *BackupManager) backup(managers []string, trigger Trigger, command string) ([]BackupResult, error) {
+	// 生成备份 ID，同一秒内已有备份时等到下一秒，避免覆盖
+	now := time.Now()
+	id := now.Format(timestampLayout)
+	if bm.Store.Exists(id) {
+		time.Sleep(now.Truncate(time.Second).Add(time.Second).Sub(now))
+		id = time.Now().Format(timestampLayout)
 	}
 
 	results := make([]BackupResult, 0)
+	manifest := &Manifest{
+		ID:           id,
+		CreatedAt:    time.Now(),
+		Trigger:      trigger,
+		Command:      command,
+		NrmgoVersion: bm.Version,
+	}
 
 	// 如果没有指定包管理器，则备份所有
 	if len(managers) == 0 {
 		managers = bm.GetAllManagers()
 	}
 
-	// 执行备份
+	// 读取配置文件，npm 与 pnpm 共用的 .npmrc 只保存一次
+	var entries []Entry
+	stored := make(map[string]string) // 源文件路径 -> 在备份中的相对路径
+	used := make(map[string]bool)     // 已使用的相对路径
 	for _, name := range managers {
 		manager := bm.GetManager(name)
 		if manager == nil {
 			continue
 		}
 
-		// 查找配置文件
-		var sourcePath string
-		for _, path := range manager.Paths {
-			if _, err := os.Stat(path); err == nil {
-				sourcePath = path
-				break
+		found := false
+		for _, config := range manager.Files {
+			if bm.excluded(config.Path) {
+				continue
 			}
-		}
+			if _, err := os.Stat(config.Path); err != nil {
+				// 记录备份时不存在的文件
+				if os.IsNotExist(err) {
+					file := ManifestFile{Source: absPath(config.Path), Missing: true}
+					manifest.Files = append(manifest.Files, bm.describeFile(manager, config, file))
+				}
+				continue
+			}
+			found = true
+
+			result := BackupResult{
+				Snapshot:   id,
+				Manager:    manager.Name,
+				SourcePath: config.Path,
+			}
+
+			rel, ok := stored[config.Path]
+			var file ManifestFile
+			if ok {
+				// 其他包管理器已经保存过该文件
+				for _, f := range manifest.Files {
+					if f.Path == rel {
+						file = f
+						break
+					}
+				}
+			} else {
+				// 不同包管理器、不同层级的同名文件保存在各自的子目录中，不会相互覆盖
+				rel = uniqueBackupPath(used, backupRelPath(manager.Name, string(config.Scope), config.Path))
+				entry, f, err := readBackupEntry(config.Path, rel)
+				if err != nil {
+					result.Success = false
+					result.Error = err
+					results = append(results, result)
+					continue
+				}
+				used[rel] = true
+				stored[config.Path] = rel
+				entries = append(entries, entry)
+				file = f
+			}
+
+			manifest.Files = append(manifest.Files, bm.describeFile(manager, config, file))
 
-		result := BackupResult{
-			Manager:    manager.Name,
-			SourcePath: sourcePath,
+			result.Success = true
+			results = append(results, result)
 		}
 
 		// 如果找不到配置文件
-		if sourcePath == "" {
-			result.Success = false
-			result.Error = fmt.Errorf("config file not found")
-			results = append(results, result)
-			continue
+		if !found {
+			results = append(results, BackupResult{
+				Snapshot: id,
+				Manager:  manager.Name,
+				Success:  false,
+				Error:    fmt.Errorf("config file not found"),
+			})
 		}
+	}
 
-		// 设置备份路径
-		backupPath := filepath.Join(backupDir, filepath.Base(sourcePath))
-		result.BackupPath = backupPath
+	// 没有备份任何文件时不创建备份，自动备份只要记录了不存在的文件就创建
+	if len(entries) == 0 && (trigger != TriggerAuto || len(manifest.Files) == 0) {
+		return results, nil
+	}
 
-		// 复制文件
-		if err := copyFile(sourcePath, backupPath); err != nil {
-			result.Success = false
-			result.Error = fmt.Errorf("failed to copy file: %w", err)
-		} else {
-			result.Success = true
+	// 写入备份
+	target, err := bm.Store.Save(manifest, entries)
+	if err != nil {
+		for i := range results {
+			if results[i].Success {
+				results[i].Success = false
+				results[i].Error = err
+			}
 		}
-
-		results = append(results, result)
+		return results, err
 	}
 
+	for i := range results {
+		if results[i].Success {
+			results[i].BackupPath = target
+		}
+	}
 	return results, nil
 }
 
-// copyFile 复制文件
-func copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
+// describeFile 为清单项补充包管理器、版本、registry 与层级
+func (bm *BackupManager) describeFile(manager *Manager, config checker.ConfigFile, file ManifestFile) ManifestFile {
+	file.Manager = manager.Name
+	file.ManagerVersion = manager.Version
+	if manager.Name == ConfigManager {
+		file.ManagerVersion = bm.Version
 	}
-	defer srcFile.Close()
+	file.Registry = manager.Registry
+	file.Scope = string(config.Scope)
+	return file
+}
 
-	// 获取源文件权限
-	srcInfo, err := srcFile.Stat()
-	if err != nil {
-		return err
+// absPath 返回绝对路径，无法获取时返回原路径
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// backupRelPath 返回配置文件在备份中的相对路径
+// 包管理器的配置文件保存在 {manager}/{scope}/ 下，额外指定的文件按原始路径保存在 include/ 下
+func backupRelPath(manager, scope, source string) string {
+	if manager == IncludeManager {
+		rel := strings.TrimLeft(filepath.ToSlash(strings.TrimPrefix(source, filepath.VolumeName(source))), "/")
+		return path.Join(manager, rel)
+	}
+	return path.Join(manager, scope, filepath.Base(source))
+}
+
+// uniqueBackupPath 同一层级有多个同名文件时，为后面的文件加上序号
+func uniqueBackupPath(used map[string]bool, rel string) string {
+	if !used[rel] {
+		return rel
+	}
+	dir, base := path.Split(rel)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s%d-%s", dir, n, base)
+		if !used[candidate] {
+			return candidate
+		}
 	}
+}
 
-	// 创建目标文件
-	dstFile, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, srcInfo.Mode())
+// readBackupEntry 读取单个配置文件，返回备份内容与清单项
+func readBackupEntry(sourcePath, relPath string) (Entry, ManifestFile, error) {
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return Entry{}, ManifestFile{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	info, err := os.Stat(sourcePath)
 	if err != nil {
-		return err
+		return Entry{}, ManifestFile{}, err
 	}
-	defer dstFile.Close()
 
-	// 复制文件内容
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return err
+	sum := sha256.Sum256(data)
+	entry := Entry{Path: relPath, Data: data, Mode: info.Mode().Perm()}
+	file := ManifestFile{
+		Source: absPath(sourcePath),
+		Path:   relPath,
+		SHA256: hex.EncodeToString(sum[:]),
+		Size:   int64(len(data)),
+		Mode:   info.Mode().Perm(),
 	}
+	return entry, file, nil
+}
 
-	return nil
+// writeDir 将备份写入目录，返回备份目录路径
+func writeDir(dir string, manifest *Manifest, entries []Entry) (string, error) {
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	for _, entry := range entries {
+		target := filepath.Join(dir, filepath.FromSlash(entry.Path))
+		if err := os.MkdirAll(filepath.Dir(target), dirMode); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(target, entry.Data, fileMode); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	if err := writeManifest(dir, manifest); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return dir, nil
 }
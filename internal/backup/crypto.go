@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+// 加密的备份是标准的 age 文件（https://age-encryption.org/v1），可以直接用 age 命令解密：
+//
+//	age -d -i key.txt 20240101_120000.manual.tar.gz.age | tar xz
+//
+// 密码使用 age 的 scrypt 接收者，X25519 公钥与私钥使用 age 的编码（age1... 与 AGE-SECRET-KEY-1...）。
+// 清单只保存在加密的归档中，文件名只包含备份 ID 与触发方式。
+
+// ErrNoIdentity 没有可以解密备份的密钥或密码
+var ErrNoIdentity = errors.New("no matching key or passphrase")
+
+// Recipient 加密备份的接收者
+type Recipient = age.Recipient
+
+// Identity 解密备份使用的密钥或密码
+type Identity = age.Identity
+
+// X25519Identity 基于 X25519 私钥的解密身份
+type X25519Identity = age.X25519Identity
+
+// X25519Recipient 基于 X25519 公钥的接收者
+type X25519Recipient = age.X25519Recipient
+
+// GenerateX25519Identity 生成新的 X25519 私钥
+func GenerateX25519Identity() (*X25519Identity, error) {
+	return age.GenerateX25519Identity()
+}
+
+// ParseX25519Recipient 解析 age 格式的公钥（age1...）
+func ParseX25519Recipient(s string) (*X25519Recipient, error) {
+	return age.ParseX25519Recipient(s)
+}
+
+// ParseIdentities 解析 age 格式的密钥文件，每行一个私钥，忽略空行与 # 开头的注释
+func ParseIdentities(data []byte) ([]Identity, error) {
+	return age.ParseIdentities(bytes.NewReader(data))
+}
+
+// Passphrase 基于密码的加密，密码在第一次使用时才读取
+// 同时实现 Recipient 与 Identity，加密与解密使用 age 的 scrypt 接收者
+type Passphrase struct {
+	get   func(confirm bool) (string, error)
+	value string
+	ok    bool
+}
+
+// NewPassphrase 创建基于密码的接收者与解密身份，get 在第一次需要密码时调用
+// 加密时 confirm 为 true，交互输入的密码应当确认一次
+func NewPassphrase(get func(confirm bool) (string, error)) *Passphrase {
+	return &Passphrase{get: get}
+}
+
+// passphrase 读取并缓存密码
+func (p *Passphrase) passphrase(confirm bool) (string, error) {
+	if !p.ok {
+		value, err := p.get(confirm)
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			return "", fmt.Errorf("empty passphrase")
+		}
+		p.value, p.ok = value, true
+	}
+	return p.value, nil
+}
+
+// Wrap 使用密码包装文件密钥
+func (p *Passphrase) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	recipient, err := p.recipient()
+	if err != nil {
+		return nil, err
+	}
+	return recipient.Wrap(fileKey)
+}
+
+// WrapWithLabels 使用密码包装文件密钥，age 据此拒绝与其他接收者一起加密
+func (p *Passphrase) WrapWithLabels(fileKey []byte) ([]*age.Stanza, []string, error) {
+	recipient, err := p.recipient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return recipient.WrapWithLabels(fileKey)
+}
+
+// recipient 读取密码，创建 age 的 scrypt 接收者
+func (p *Passphrase) recipient() (*age.ScryptRecipient, error) {
+	passphrase, err := p.passphrase(true)
+	if err != nil {
+		return nil, err
+	}
+	return age.NewScryptRecipient(passphrase)
+}
+
+// Unwrap 使用密码解开文件密钥，只有备份使用密码加密时才读取密码
+func (p *Passphrase) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	scrypt := false
+	for _, s := range stanzas {
+		scrypt = scrypt || s.Type == "scrypt"
+	}
+	if !scrypt {
+		return nil, age.ErrIncorrectIdentity
+	}
+
+	passphrase, err := p.passphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return identity.Unwrap(stanzas)
+}
+
+// Encrypt 为所有接收者加密数据，生成 age 文件
+// 使用密码加密时密码必须是唯一的接收者
+func Encrypt(plaintext []byte, recipients []Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decrypt 使用任一匹配的身份解密 age 文件
+func Decrypt(data []byte, identities []Identity) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, ErrNoIdentity
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,149 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffOp 表示差异行的类型
+type DiffOp byte
+
+const (
+	// DiffEqual 两边相同的行
+	DiffEqual DiffOp = ' '
+	// DiffDelete 只在旧文件中的行
+	DiffDelete DiffOp = '-'
+	// DiffInsert 只在新文件中的行
+	DiffInsert DiffOp = '+'
+)
+
+// DiffLine 表示差异中的一行
+type DiffLine struct {
+	Op   DiffOp // 差异类型
+	Text string // 行内容，不含换行符
+}
+
+// String 以 unified diff 的格式返回该行
+func (l DiffLine) String() string {
+	return string(l.Op) + l.Text
+}
+
+// DiffHunk 表示一段连续的差异及其上下文
+type DiffHunk struct {
+	OldStart, OldLines int // 旧文件中的起始行号（从 1 开始）与行数
+	NewStart, NewLines int // 新文件中的起始行号（从 1 开始）与行数
+	Lines              []DiffLine
+}
+
+// Header 返回 unified diff 格式的段落标题
+func (h DiffHunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// Diff 基于最长公共子序列逐行比较两段文本
+func Diff(oldText, newText string) []DiffLine {
+	a, b := splitLines(oldText), splitLines(newText)
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]DiffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Op: DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+	return lines
+}
+
+// Hunks 将差异分组为带有 context 行上下文的段落，没有差异时返回 nil
+func Hunks(lines []DiffLine, context int) []DiffHunk {
+	// 每一行在旧文件与新文件中的行号
+	oldNo := make([]int, len(lines))
+	newNo := make([]int, len(lines))
+	oldLine, newLine := 1, 1
+	for i, line := range lines {
+		oldNo[i], newNo[i] = oldLine, newLine
+		if line.Op != DiffInsert {
+			oldLine++
+		}
+		if line.Op != DiffDelete {
+			newLine++
+		}
+	}
+
+	var hunks []DiffHunk
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Op == DiffEqual {
+			continue
+		}
+
+		// 向后扩展，直到与下一个差异之间的相同行超过两倍上下文
+		start := max(i-context, 0)
+		end := i
+		for k := i + 1; k < len(lines) && k <= end+2*context+1; k++ {
+			if lines[k].Op != DiffEqual {
+				end = k
+			}
+		}
+		stop := min(end+context+1, len(lines))
+
+		hunk := DiffHunk{OldStart: oldNo[start], NewStart: newNo[start], Lines: lines[start:stop]}
+		for _, line := range hunk.Lines {
+			if line.Op != DiffInsert {
+				hunk.OldLines++
+			}
+			if line.Op != DiffDelete {
+				hunk.NewLines++
+			}
+		}
+
+		// 与 diff -u 一致，空段落的起始行号为其前一行
+		if hunk.OldLines == 0 {
+			hunk.OldStart--
+		}
+		if hunk.NewLines == 0 {
+			hunk.NewStart--
+		}
+		hunks = append(hunks, hunk)
+		i = stop - 1
+	}
+	return hunks
+}
+
+// splitLines 将文本拆分为行，忽略末尾的换行符
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lines 以 unified diff 的格式返回差异行
+func lines(diff []DiffLine) []string {
+	var result []string
+	for _, line := range diff {
+		result = append(result, line.String())
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     []string
+	}{
+		{old: "", new: "", want: nil},
+		{old: "a\nb\n", new: "a\nb", want: []string{" a", " b"}},
+		{old: "", new: "a\n", want: []string{"+a"}},
+		{old: "a\n", new: "", want: []string{"-a"}},
+		{old: "a\r\nb\r\n", new: "a\nb\n", want: []string{" a", " b"}},
+		{old: "a\nb\nc\n", new: "a\nx\nc\n", want: []string{" a", "-b", "+x", " c"}},
+		{old: "a\nb\nc\n", new: "b\nc\nd\n", want: []string{"-a", " b", " c", "+d"}},
+		{
+			old:  "registry=https://a/\nstrict-ssl=false\n",
+			new:  "registry=https://b/\nstrict-ssl=false\nalways-auth=false\n",
+			want: []string{"-registry=https://a/", "+registry=https://b/", " strict-ssl=false", "+always-auth=false"},
+		},
+	}
+	for _, tt := range tests {
+		if got := lines(Diff(tt.old, tt.new)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Diff(%q, %q) = %q, want %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestHunks(t *testing.T) {
+	// 1 到 10 共 10 行
+	numbers := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     []string // 每个段落的标题与差异行，以空格连接
+	}{
+		{
+			name:    "unchanged",
+			old:     numbers,
+			new:     numbers,
+			context: 3,
+			want:    nil,
+		},
+		{
+			name:    "middle",
+			old:     numbers,
+			new:     strings.Replace(numbers, "5\n", "five\n", 1),
+			context: 1,
+			want:    []string{"@@ -4,3 +4,3 @@  4 -5 +five  6"},
+		},
+		{
+			// 差异之间的相同行不超过两倍上下文时合并为一个段落
+			name:    "merged",
+			old:     numbers,
+			new:     strings.NewReplacer("3\n", "x\n", "6\n", "y\n").Replace(numbers),
+			context: 1,
+			want:    []string{"@@ -2,6 +2,6 @@  2 -3 +x  4  5 -6 +y  7"},
+		},
+		{
+			name:    "separate",
+			old:     numbers,
+			new:     strings.NewReplacer("2\n", "x\n", "9\n", "y\n").Replace(numbers),
+			context: 1,
+			want:    []string{"@@ -1,3 +1,3 @@  1 -2 +x  3", "@@ -8,3 +8,3 @@  8 -9 +y  10"},
+		},
+		{
+			// 与 diff -u 一致，空段落的起始行号为其前一行
+			name:    "new file",
+			old:     "",
+			new:     "a\nb\n",
+			context: 3,
+			want:    []string{"@@ -0,0 +1,2 @@ +a +b"},
+		},
+		{
+			name:    "deleted file",
+			old:     "a\n",
+			new:     "",
+			context: 3,
+			want:    []string{"@@ -1,1 +0,0 @@ -a"},
+		},
+		{
+			name:    "append",
+			old:     "a\nb\n",
+			new:     "a\nb\nc\n",
+			context: 0,
+			want:    []string{"@@ -2,0 +3,1 @@ +c"},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, hunk := range Hunks(Diff(tt.old, tt.new), tt.context) {
+			got = append(got, strings.Join(append([]string{hunk.Header()}, lines(hunk.Lines)...), " "))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Hunks(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
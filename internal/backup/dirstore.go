@@ -0,0 +1,146 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DirStore 默认的存储，将每个备份保存在 Root 下以备份 ID 命名的目录中
+// 设置 Archive 时整个备份保存为一个 .tar.gz，设置 Recipients 时再加密为 age 格式的 .tar.gz.age
+type DirStore struct {
+	Root       string      // 备份的根目录，即数据目录下的 backups
+	Archive    bool        // 是否将备份保存为单个 .tar.gz
+	Recipients []Recipient // 加密备份的接收者，为空时不加密
+	Identities []Identity  // 读取加密备份使用的密钥与密码
+
+	archives map[string]map[string][]byte // 已读取的归档内容
+}
+
+// NewDirStore 创建保存在 root 下的目录存储
+func NewDirStore(root string) *DirStore {
+	return &DirStore{Root: root}
+}
+
+// Save 将备份写入以备份 ID 命名的目录或归档文件
+func (s *DirStore) Save(manifest *Manifest, entries []Entry) (string, error) {
+	if len(s.Recipients) > 0 && !s.Archive {
+		return "", fmt.Errorf("encrypted backups must be archives")
+	}
+
+	// 创建 backups 根目录，已有的目录同样收紧权限
+	if err := os.MkdirAll(s.Root, dirMode); err != nil {
+		return "", fmt.Errorf("failed to create backups directory: %w", err)
+	}
+	if err := os.Chmod(s.Root, dirMode); err != nil {
+		return "", fmt.Errorf("failed to restrict backups directory: %w", err)
+	}
+
+	if s.Archive {
+		return s.writeArchive(manifest, entries)
+	}
+	return writeDir(filepath.Join(s.Root, manifest.ID), manifest, entries)
+}
+
+// List 列出 Root 下的所有备份
+func (s *DirStore) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.Root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backups directory: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		// 备份可以是目录，也可以是 .tar.gz 或加密的 .tar.gz.age
+		snapshot, archive := parseArchiveName(entry.Name())
+		switch {
+		case entry.IsDir():
+			snapshot = Snapshot{ID: entry.Name()}
+		case !archive || !entry.Type().IsRegular():
+			continue
+		}
+
+		// 跳过无法解析时间戳的备份
+		backupTime, err := snapshotTime(snapshot.ID)
+		if err != nil {
+			continue
+		}
+		snapshot.Time = backupTime
+		snapshot.Path = filepath.Join(s.Root, entry.Name())
+
+		// 优先使用清单中的文件列表，旧版本的备份直接保存在备份目录下
+		// 加密的归档不解密时没有清单与文件列表
+		if snapshot.Archive {
+			err = s.readArchiveSnapshot(&snapshot)
+		} else {
+			snapshot.Manifest, err = readManifest(snapshot.Path)
+		}
+		if err != nil {
+			snapshot.Err = err
+			snapshots = append(snapshots, snapshot)
+			continue
+		}
+		switch {
+		case snapshot.Manifest != nil:
+			snapshot.Files = manifestPaths(snapshot.Manifest)
+		case !snapshot.Archive:
+			files, err := os.ReadDir(snapshot.Path)
+			if err != nil {
+				continue
+			}
+			for _, file := range files {
+				if !file.IsDir() {
+					snapshot.Files = append(snapshot.Files, file.Name())
+				}
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// Exists 判断指定 ID 的备份目录或归档文件是否已存在
+func (s *DirStore) Exists(id string) bool {
+	for _, name := range []string{id, id + archiveExt} {
+		if _, err := os.Stat(filepath.Join(s.Root, name)); err == nil {
+			return true
+		}
+	}
+	matches, _ := filepath.Glob(filepath.Join(s.Root, id+".*"+encryptedExt))
+	return len(matches) > 0
+}
+
+// Manifest 返回备份的完整清单，加密的归档使用 Identities 解密后读取
+func (s *DirStore) Manifest(snapshot *Snapshot) (*Manifest, error) {
+	if !snapshot.Archive {
+		return snapshot.Manifest, nil
+	}
+	files, err := s.archiveFiles(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("archive has no %s", manifestFile)
+	}
+	return parseManifest(data)
+}
+
+// ReadFile 读取备份目录或归档中的文件
+func (s *DirStore) ReadFile(snapshot *Snapshot, rel string) ([]byte, error) {
+	if !snapshot.Archive {
+		return os.ReadFile(filepath.Join(snapshot.Path, filepath.FromSlash(rel)))
+	}
+	return s.readArchiveFile(snapshot, rel)
+}
+
+// Remove 删除备份目录或归档文件
+func (s *DirStore) Remove(snapshot *Snapshot) error {
+	if err := os.RemoveAll(snapshot.Path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", snapshot.Path, err)
+	}
+	return nil
+}
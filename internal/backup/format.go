@@ -0,0 +1,391 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Format 配置文件格式
+type Format string
+
+const (
+	// FormatINI npm 风格的 key=value 配置，包括 .npmrc 与 yarn classic 的 .yarnrc
+	FormatINI Format = "ini"
+	// FormatTOML TOML 配置，包括 bunfig.toml 与 nrmgo 的 config.toml
+	FormatTOML Format = "toml"
+	// FormatYAML YAML 配置，包括 yarn berry 的 .yarnrc.yml
+	FormatYAML Format = "yaml"
+	// FormatText 无法识别格式的配置，按行比较
+	FormatText Format = "text"
+)
+
+// DetectFormat 根据文件名判断配置文件格式
+func DetectFormat(path string) Format {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.HasSuffix(name, ".toml"):
+		return FormatTOML
+	case strings.HasSuffix(name, ".yml"), strings.HasSuffix(name, ".yaml"):
+		return FormatYAML
+	case name == ".npmrc", name == "npmrc", name == ".yarnrc", name == "rc":
+		return FormatINI
+	default:
+		return FormatText
+	}
+}
+
+// sensitiveKeys 值需要隐藏的配置项，比较时忽略大小写与前缀下划线
+var sensitiveKeys = map[string]bool{
+	"auth":         true,
+	"authtoken":    true,
+	"password":     true,
+	"token":        true,
+	"key":          true,
+	"secret":       true,
+	"npmauthtoken": true,
+	"npmauthident": true,
+}
+
+// urlUserinfo 匹配 URL 中的 user:password@
+var urlUserinfo = regexp.MustCompile(`(://[^/\s:@]*):[^/\s@]+@`)
+
+// isSensitive 判断配置项的值是否需要隐藏
+// key 可以带有 //host/:、表路径等前缀，只比较最后一段
+func isSensitive(key string) bool {
+	if i := strings.LastIndexAny(key, ":."); i >= 0 {
+		key = key[i+1:]
+	}
+	return sensitiveKeys[strings.TrimLeft(strings.ToLower(key), "_")]
+}
+
+// Redactor 隐藏配置中的敏感值
+// 同一个 Redactor 对相同的值使用相同的占位符，比较两个文件时仍能看出凭据是否发生变化
+type Redactor struct {
+	seen map[string]int
+}
+
+// NewRedactor 创建敏感值隐藏器
+func NewRedactor() *Redactor {
+	return &Redactor{seen: make(map[string]int)}
+}
+
+// value 返回敏感值的占位符
+func (r *Redactor) value(value string) string {
+	n, ok := r.seen[value]
+	if !ok {
+		n = len(r.seen) + 1
+		r.seen[value] = n
+	}
+	return fmt.Sprintf("[redacted-%d]", n)
+}
+
+// entry 隐藏配置项中的敏感值，以及 URL 中的密码
+func (r *Redactor) entry(key, value string) string {
+	if isSensitive(key) {
+		return r.value(value)
+	}
+	return urlUserinfo.ReplaceAllStringFunc(value, func(match string) string {
+		parts := urlUserinfo.FindStringSubmatch(match)
+		password := strings.TrimSuffix(match[len(parts[1])+1:], "@")
+		return parts[1] + ":" + r.value(password) + "@"
+	})
+}
+
+// Line 隐藏单行文本中的敏感值，保留行的其余部分
+// 支持 key=value、YAML 的 key: value、yarn classic 的 key value 以及 TOML 的内联表
+func (r *Redactor) Line(line string) string {
+	// 跨行数组中的内联表
+	if trimmed := strings.TrimLeft(line, " \t"); strings.HasPrefix(trimmed, "{") {
+		return line[:len(line)-len(trimmed)] + r.inline("", trimmed)
+	}
+
+	at := -1
+	for _, sep := range []string{"=", ": "} {
+		if i := strings.Index(line, sep); i > 0 && (at < 0 || i < at) {
+			at = i
+		}
+	}
+	if at < 0 {
+		trimmed := strings.TrimLeft(line, " \t")
+		if i := strings.IndexAny(trimmed, " \t"); i > 0 {
+			at = len(line) - len(trimmed) + i
+		}
+	}
+	if at < 0 {
+		return r.entry("", line)
+	}
+
+	// 值之前保留分隔符与空白
+	key := strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[:at]), "- ")), "\"'")
+	rest := line[at:]
+	value := strings.TrimLeft(rest, "=: \t")
+	sep := rest[:len(rest)-len(value)]
+	if value == "" {
+		return line
+	}
+	return line[:at] + sep + r.inline(key, value)
+}
+
+// inline 隐藏 TOML 内联表与数组中的敏感值，例如 myorg = { token = "...", url = "..." }
+// 内联表中的每一项按自身的名称判断，数组中的元素沿用数组的名称，其他值按 entry 处理
+func (r *Redactor) inline(key, value string) string {
+	end := -1
+	if !isSensitive(key) && (value[0] == '{' || value[0] == '[') {
+		walkInline(value, func(i, depth int) bool {
+			if i > 0 && depth == 0 && (value[i] == '}' || value[i] == ']') {
+				end = i
+				return false
+			}
+			return true
+		})
+	}
+	if end < 0 {
+		return r.entry(key, value)
+	}
+
+	// 按顶层的逗号拆分各项，保留原有的空白
+	inner := value[1:end]
+	var parts []string
+	start := 0
+	walkInline(inner, func(i, depth int) bool {
+		if depth == 0 && inner[i] == ',' {
+			parts = append(parts, inner[start:i])
+			start = i + 1
+		}
+		return true
+	})
+	parts = append(parts, inner[start:])
+
+	for i, part := range parts {
+		itemKey, prefix, item := key, "", part
+		if value[0] == '{' {
+			sep := -1
+			walkInline(part, func(j, depth int) bool {
+				if depth == 0 && (part[j] == '=' || part[j] == ':') {
+					sep = j
+					return false
+				}
+				return true
+			})
+			if sep < 0 {
+				continue
+			}
+			itemKey = strings.Trim(strings.TrimSpace(part[:sep]), "\"'")
+			prefix, item = part[:sep+1], part[sep+1:]
+		}
+		trimmed := strings.TrimSpace(item)
+		if trimmed == "" {
+			continue
+		}
+		lead := item[:strings.Index(item, trimmed)]
+		parts[i] = prefix + lead + r.inline(itemKey, trimmed) + item[len(lead)+len(trimmed):]
+	}
+	return value[:1] + strings.Join(parts, ",") + value[end:]
+}
+
+// walkInline 依次访问 s 中不在字符串内的字符，depth 为字符所在的括号深度，visit 返回 false 时停止
+// 左括号按进入前的深度访问，右括号按离开后的深度访问
+func walkInline(s string, visit func(i, depth int) bool) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+			continue
+		case c == '}' || c == ']':
+			depth--
+		}
+		if !visit(i, depth) {
+			return
+		}
+		if c == '{' || c == '[' {
+			depth++
+		}
+	}
+}
+
+// Redact 逐行隐藏文本中的敏感值，保留原有的行结构
+func (r *Redactor) Redact(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = r.Line(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Normalize 将配置文件转换为规范的 key = value 行，并隐藏敏感值
+// 注释、空行与配置项的顺序不影响结果，只比较实际生效的配置；无法解析的文件按原始行返回
+func Normalize(format Format, data []byte, r *Redactor) []string {
+	switch format {
+	case FormatINI:
+		return normalizeINI(data, r)
+	case FormatTOML:
+		if lines, err := normalizeTOML(data, r); err == nil {
+			return lines
+		}
+	}
+	return splitLines(r.Redact(string(data)))
+}
+
+// normalizeINI 规范化 npm 风格的配置，后出现的同名配置覆盖前面的，key[] 表示数组
+// yarn classic 的 .yarnrc 使用空格分隔 key 与 value，同样支持
+func normalizeINI(data []byte, r *Redactor) []string {
+	type section struct {
+		name   string
+		values map[string][]string
+	}
+	sections := []*section{{values: make(map[string][]string)}}
+	current := sections[0]
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = &section{name: strings.TrimSpace(line[1 : len(line)-1]), values: make(map[string][]string)}
+			sections = append(sections, current)
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, _ = strings.Cut(line, " ")
+		}
+		key = strings.Trim(strings.TrimSpace(key), "\"'")
+		value = strings.Trim(strings.TrimSpace(value), "\"'")
+		if name, ok := strings.CutSuffix(key, "[]"); ok {
+			current.values[name+"[]"] = append(current.values[name+"[]"], value)
+		} else {
+			current.values[key] = []string{value}
+		}
+	}
+
+	var lines []string
+	for _, s := range sections {
+		if len(s.values) == 0 {
+			continue
+		}
+		if s.name != "" {
+			lines = append(lines, "["+s.name+"]")
+		}
+		keys := make([]string, 0, len(s.values))
+		for key := range s.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, value := range s.values[key] {
+				lines = append(lines, key+" = "+r.entry(strings.TrimSuffix(key, "[]"), value))
+			}
+		}
+	}
+	return lines
+}
+
+// normalizeTOML 将 TOML 配置展开为按名称排序的 table.key = value 行
+func normalizeTOML(data []byte, r *Redactor) ([]string, error) {
+	var values map[string]any
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				name := key
+				if prefix != "" {
+					name = prefix + "." + key
+				}
+				walk(name, v[key])
+			}
+		case []any:
+			for i, item := range v {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), item)
+			}
+		case string:
+			encoded, _ := json.Marshal(r.entry(prefix, v))
+			lines = append(lines, prefix+" = "+string(encoded))
+		default:
+			text := fmt.Sprint(v)
+			if isSensitive(prefix) {
+				text = r.value(text)
+			}
+			lines = append(lines, prefix+" = "+text)
+		}
+	}
+	walk("", values)
+	return lines, nil
+}
+
+// Validate 按配置文件格式解析文件，检查文件是否损坏
+// TOML 完整解析；npm 风格的配置与 YAML 没有严格的语法，只检查编码与明显的结构错误
+func Validate(format Format, data []byte) error {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return fmt.Errorf("contains NUL bytes")
+	}
+	if !utf8.Valid(data) {
+		return fmt.Errorf("not valid UTF-8")
+	}
+
+	switch format {
+	case FormatTOML:
+		var values map[string]any
+		return toml.Unmarshal(data, &values)
+	case FormatINI:
+		for n, line := range splitLines(string(data)) {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			case strings.HasPrefix(line, "[") && !strings.HasSuffix(line, "]"):
+				return fmt.Errorf("line %d: unterminated section", n+1)
+			case strings.HasPrefix(line, "="):
+				return fmt.Errorf("line %d: missing key", n+1)
+			}
+		}
+	case FormatYAML:
+		for n, line := range splitLines(string(data)) {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			if strings.Contains(indent, "\t") {
+				return fmt.Errorf("line %d: tab in indentation", n+1)
+			}
+		}
+	}
+	return nil
+}
+
+// DiffConfig 按配置文件格式比较两个版本，返回带有 context 行上下文的差异段落
+// 两个版本共用同一个 Redactor，敏感值被隐藏但仍能看出是否发生变化
+func DiffConfig(format Format, oldData, newData []byte, context int) []DiffHunk {
+	r := NewRedactor()
+	oldLines := Normalize(format, oldData, r)
+	newLines := Normalize(format, newData, r)
+	return Hunks(Diff(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")), context)
+}
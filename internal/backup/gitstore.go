@@ -0,0 +1,311 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitSnapshotDir 备份在 git 仓库中的目录，仓库中的其他文件不受影响
+const gitSnapshotDir = "nrmgo"
+
+// GitStore 将每个备份提交到本地 git 仓库，通过提交历史读取以前的备份
+// 备份文件保存在仓库的 nrmgo/ 目录下，每次提交替换该目录的全部内容，提交信息描述触发备份的 nrmgo 命令
+// 只调用本地的 git 命令，不会访问网络
+type GitStore struct {
+	Repo string // 仓库的根目录，不是 git 仓库时在第一次备份时初始化
+}
+
+// NewGitStore 创建提交到 repo 的 git 存储
+func NewGitStore(repo string) *GitStore {
+	return &GitStore{Repo: repo}
+}
+
+// Save 将备份写入 nrmgo/ 目录并提交，返回提交的哈希
+func (s *GitStore) Save(manifest *Manifest, entries []Entry) (string, error) {
+	if err := s.init(); err != nil {
+		return "", err
+	}
+
+	// 每次提交替换整个目录，不再存在的文件同样记录在历史中
+	dir := filepath.Join(s.Repo, gitSnapshotDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to clean %s: %w", dir, err)
+	}
+	if _, err := writeDir(dir, manifest, entries); err != nil {
+		return "", err
+	}
+	if _, err := s.git(nil, "add", "--all", "--force", "--", gitSnapshotDir); err != nil {
+		return "", err
+	}
+
+	// 只提交 nrmgo/ 目录，不运行仓库的钩子，也不签名
+	args := []string{"-c", "commit.gpgSign=false"}
+	// 没有配置提交者时使用 nrmgo 的身份
+	for _, identity := range [][2]string{{"user.name", "nrmgo"}, {"user.email", "nrmgo@localhost"}} {
+		if _, err := s.git(nil, "config", identity[0]); err != nil {
+			args = append(args, "-c", identity[0]+"="+identity[1])
+		}
+	}
+	args = append(args, "commit", "--quiet", "--no-verify", "--file=-", "--", gitSnapshotDir)
+	if _, err := s.git(strings.NewReader(commitMessage(manifest)), args...); err != nil {
+		return "", err
+	}
+
+	out, err := s.git(nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// List 按提交历史列出所有备份
+func (s *GitStore) List() ([]Snapshot, error) {
+	if !s.initialized() {
+		return nil, nil
+	}
+	// 还没有任何提交
+	if _, err := s.git(nil, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil, nil
+	}
+
+	out, err := s.git(nil, "log", "--format=%H %ct", "--", path.Join(gitSnapshotDir, manifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		commits []string
+		times   []time.Time
+		objects []string
+	)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		hash, ct, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(ct, 10, 64)
+		commits = append(commits, hash)
+		times = append(times, time.Unix(seconds, 0))
+		objects = append(objects, hash+":"+path.Join(gitSnapshotDir, manifestFile))
+	}
+
+	// 一次读取所有提交中的清单
+	contents, err := s.catFiles(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(commits))
+	for i, hash := range commits {
+		snapshot := Snapshot{
+			ID:     times[i].Format(timestampLayout),
+			Time:   times[i],
+			Path:   s.Repo,
+			Commit: hash,
+		}
+		if contents[i] == nil {
+			snapshot.Err = fmt.Errorf("commit %s has no %s", shortCommit(hash), manifestFile)
+			snapshots = append(snapshots, snapshot)
+			continue
+		}
+		manifest, err := parseManifest(contents[i])
+		if err != nil {
+			snapshot.Err = err
+			snapshots = append(snapshots, snapshot)
+			continue
+		}
+		snapshot.Manifest = manifest
+		if t, err := snapshotTime(manifest.ID); err == nil {
+			snapshot.ID, snapshot.Time = manifest.ID, t
+		}
+		snapshot.Files = manifestPaths(manifest)
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// Exists 判断指定 ID 的备份是否已提交
+func (s *GitStore) Exists(id string) bool {
+	snapshots, err := s.List()
+	if err != nil {
+		return false
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Manifest 返回提交中的清单，git 存储的清单总是包含校验和
+func (s *GitStore) Manifest(snapshot *Snapshot) (*Manifest, error) {
+	if snapshot.Manifest == nil {
+		return nil, fmt.Errorf("commit %s has no %s", shortCommit(snapshot.Commit), manifestFile)
+	}
+	return snapshot.Manifest, nil
+}
+
+// ReadFile 读取备份对应的提交中的文件
+func (s *GitStore) ReadFile(snapshot *Snapshot, rel string) ([]byte, error) {
+	contents, err := s.catFiles([]string{snapshot.Commit + ":" + path.Join(gitSnapshotDir, path.Clean(rel))})
+	if err != nil {
+		return nil, err
+	}
+	if contents[0] == nil {
+		return nil, &fs.PathError{Op: "open", Path: rel, Err: fs.ErrNotExist}
+	}
+	return contents[0], nil
+}
+
+// Remove git 存储在提交历史中保留所有备份，不能删除单个备份
+func (s *GitStore) Remove(snapshot *Snapshot) error {
+	return fmt.Errorf("cannot remove backup %s, the git store keeps every backup in the history of %s: %w", snapshot.ID, s.Repo, errors.ErrUnsupported)
+}
+
+// initialized 判断 Repo 是否为 git 仓库的根目录
+func (s *GitStore) initialized() bool {
+	_, err := os.Stat(filepath.Join(s.Repo, ".git"))
+	return err == nil
+}
+
+// init 在 Repo 不是 git 仓库时初始化仓库，新建的仓库只允许当前用户访问
+// 只检查 Repo 本身，即使 Repo 位于其他仓库中也不会提交到上层仓库
+func (s *GitStore) init() error {
+	if s.initialized() {
+		return nil
+	}
+	if err := os.MkdirAll(s.Repo, dirMode); err != nil {
+		return fmt.Errorf("failed to create git repository: %w", err)
+	}
+	if err := os.Chmod(s.Repo, dirMode); err != nil {
+		return fmt.Errorf("failed to restrict git repository: %w", err)
+	}
+	_, err := s.git(nil, "init", "--quiet")
+	return err
+}
+
+// git 在 Repo 中执行 git 命令，返回标准输出
+func (s *GitStore) git(stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", s.Repo}, args...)...)
+	cmd.Stdin = stdin
+	// 不询问凭据，并使用固定的语言以便显示错误
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", gitSubcommand(args), message)
+		}
+		return nil, fmt.Errorf("git %s: %w", gitSubcommand(args), err)
+	}
+	return out, nil
+}
+
+// catFiles 使用 git cat-file --batch 读取多个对象，对象不存在时对应的结果为 nil
+func (s *GitStore) catFiles(objects []string) ([][]byte, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	out, err := s.git(strings.NewReader(strings.Join(objects, "\n")+"\n"), "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个对象的输出为 "<hash> <type> <size>\n<content>\n"，不存在的对象为 "<object> missing\n"
+	contents := make([][]byte, len(objects))
+	reader := bufio.NewReader(bytes.NewReader(out))
+	for i := range objects {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: unexpected output")
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: unexpected output %q", strings.TrimSpace(header))
+		}
+		content := make([]byte, size+1)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return nil, fmt.Errorf("git cat-file: unexpected output")
+		}
+		if fields[1] == "blob" {
+			contents[i] = content[:size]
+		}
+	}
+	return contents, nil
+}
+
+// gitSubcommand 返回 git 参数中的子命令，用于错误信息
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-c" {
+			i++
+			continue
+		}
+		return args[i]
+	}
+	return ""
+}
+
+// commitMessage 生成备份提交的信息：标题描述触发备份的 nrmgo 命令，正文列出备份的文件
+// 末尾的 Nrmgo-Backup 与 Nrmgo-Trigger 便于在 git log 中查找备份
+func commitMessage(manifest *Manifest) string {
+	var b strings.Builder
+	if manifest.Trigger == TriggerAuto && manifest.Command != "" {
+		fmt.Fprintf(&b, "Back up before 'nrmgo %s'\n", manifest.Command)
+	} else {
+		var managers []string
+		seen := make(map[string]bool)
+		for _, file := range manifest.Files {
+			if !seen[file.Manager] {
+				seen[file.Manager] = true
+				managers = append(managers, file.Manager)
+			}
+		}
+		fmt.Fprintf(&b, "Back up %s with 'nrmgo backup'\n", strings.Join(managers, ", "))
+	}
+
+	b.WriteString("\n")
+	for _, file := range manifest.Files {
+		scope := file.Scope
+		if scope == "" {
+			scope = "-"
+		}
+		fmt.Fprintf(&b, "%s (%s): %s", file.Manager, scope, file.Source)
+		if file.Missing {
+			b.WriteString(" (missing)")
+		}
+		if file.Registry != "" {
+			// registry 中可能带有凭据
+			fmt.Fprintf(&b, " -> %s", urlUserinfo.ReplaceAllString(file.Registry, "$1:***@"))
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\nNrmgo-Backup: %s\nNrmgo-Trigger: %s\n", manifest.ID, manifest.Trigger)
+	return b.String()
+}
+
+// shortCommit 截取提交哈希的前 12 位用于显示
+func shortCommit(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -1,40 +1,112 @@
 package backup
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"nrmgo/internal/checker"
+	"nrmgo/internal/config"
 )
 
 // NewBackupManager 创建备份管理器
+// 备份所有支持的包管理器在项目、用户与全局层级的配置文件，未安装但留有配置文件的包管理器同样备份
 func NewBackupManager(execPath string) *BackupManager {
 	// 初始化管理器
 	bm := &BackupManager{
 		ExecPath: execPath,
 		Managers: make(map[string]*Manager),
+		Store:    NewDirStore(filepath.Join(execPath, "backups")),
 	}
 
 	// 获取所有可用的包管理器
-	pms := checker.GetAvailableManagers()
-
-	// 根据检查器初始化包管理器配置
-	for _, pm := range pms {
-		bm.Managers[pm.Name] = &Manager{
-			Name:       pm.Name,
-			ConfigFile: filepath.Base(pm.ConfigPath),
-			Paths:      []string{pm.ConfigPath},
+	installed := make(map[string]checker.PackageManager)
+	for _, pm := range checker.GetAvailableManagers() {
+		installed[pm.Name] = pm
+	}
+
+	// 根据配置文件发现的结果初始化包管理器配置
+	for _, name := range checker.SupportedManagers() {
+		manager := &Manager{
+			Name:  name,
+			Files: checker.DiscoverConfigFiles(name),
+		}
+		if pm, ok := installed[name]; ok {
+			manager.Installed = true
+			manager.Version = pm.Version
+			manager.Registry = pm.Registry
+		} else if registry, _, exists, err := checker.GetRegistry(name); err == nil && exists {
+			manager.Registry = registry
 		}
+		bm.Managers[name] = manager
+	}
+
+	// nrmgo 自身的配置文件
+	configPath := filepath.Join(execPath, config.ConfigFileName)
+	_, err := os.Stat(configPath)
+	bm.Managers[ConfigManager] = &Manager{
+		Name:      ConfigManager,
+		Files:     []checker.ConfigFile{{Manager: ConfigManager, Path: configPath, Exists: err == nil}},
+		Installed: true,
 	}
 
 	return bm
 }
 
-// GetAllManagers 获取所有包管理器名称
+// Include 将匹配 patterns 的文件加入备份，模式的语法同 filepath.Glob
+// 这些文件在备份中属于 IncludeManager
+func (bm *BackupManager) Include(patterns []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	manager := &Manager{Name: IncludeManager, Installed: true}
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			path, err := filepath.Abs(match)
+			if err != nil || seen[path] {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			seen[path] = true
+			manager.Files = append(manager.Files, checker.ConfigFile{Manager: IncludeManager, Path: path, Exists: true})
+		}
+	}
+	bm.Managers[IncludeManager] = manager
+	return nil
+}
+
+// excluded 判断文件是否被 Exclude 排除
+func (bm *BackupManager) excluded(path string) bool {
+	for _, pattern := range bm.Exclude {
+		target := path
+		if !strings.ContainsAny(pattern, `/\`) {
+			target = filepath.Base(path)
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAllManagers 获取所有包管理器名称，包含 nrmgo 自身的配置文件与额外指定的文件
 func (bm *BackupManager) GetAllManagers() []string {
 	managers := make([]string, 0, len(bm.Managers))
 	for name := range bm.Managers {
 		managers = append(managers, name)
 	}
+	sort.Strings(managers)
 	return managers
 }
 
@@ -0,0 +1,68 @@
+package backup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// manifestFile 备份清单文件名
+const manifestFile = "manifest.json"
+
+// Manifest 备份清单，记录每个备份文件的来源与校验信息
+type Manifest struct {
+	ID           string         `json:"id"`                // 备份目录名
+	CreatedAt    time.Time      `json:"created_at"`        // 备份时间
+	Trigger      Trigger        `json:"trigger"`           // 触发方式
+	Command      string         `json:"command,omitempty"` // 触发自动备份的命令
+	NrmgoVersion string         `json:"nrmgo_version"`     // 创建备份的 nrmgo 版本
+	Files        []ManifestFile `json:"files"`             // 备份的文件
+}
+
+// ManifestFile 备份清单中的一个文件
+type ManifestFile struct {
+	Manager        string      `json:"manager"`           // 包管理器名称
+	ManagerVersion string      `json:"manager_version"`   // 包管理器版本
+	Registry       string      `json:"registry"`          // 备份时生效的 registry
+	Scope          string      `json:"scope,omitempty"`   // 配置文件的层级：project、user 或 global
+	Source         string      `json:"source"`            // 原始文件路径
+	Path           string      `json:"path"`              // 在备份目录中的相对路径
+	SHA256         string      `json:"sha256"`            // 文件内容的 SHA-256（十六进制）
+	Size           int64       `json:"size"`              // 文件大小
+	Mode           os.FileMode `json:"mode"`              // 文件权限
+	Missing        bool        `json:"missing,omitempty"` // 备份时文件不存在，备份中没有该文件
+}
+
+// FilesOf 返回指定包管理器的所有备份文件
+func (m *Manifest) FilesOf(manager string) []ManifestFile {
+	var files []ManifestFile
+	for _, file := range m.Files {
+		if file.Manager == manager {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// readManifest 读取备份目录中的清单，旧版本创建的备份没有清单，返回 nil
+func readManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return parseManifest(data)
+}
+
+// writeManifest 将清单写入备份目录
+func writeManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, manifestFile), append(data, '\n'), fileMode)
+}
@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// pinsFile 记录固定的备份，固定的备份不会被保留策略删除
+const pinsFile = "pins.json"
+
+// readPins 读取固定的备份
+func (bm *BackupManager) readPins() (map[string]bool, error) {
+	data, err := os.ReadFile(filepath.Join(bm.ExecPath, "backups", pinsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", pinsFile, err)
+	}
+	pins := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		pins[id] = true
+	}
+	return pins, nil
+}
+
+// writePins 保存固定的备份
+func (bm *BackupManager) writePins(pins map[string]bool) error {
+	ids := make([]string, 0, len(pins))
+	for id := range pins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	backupsRoot := filepath.Join(bm.ExecPath, "backups")
+	if err := os.MkdirAll(backupsRoot, dirMode); err != nil {
+		return fmt.Errorf("failed to create backups directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(backupsRoot, pinsFile), append(data, '\n'), fileMode)
+}
+
+// Pin 固定备份，固定的备份不会被保留策略删除
+func (bm *BackupManager) Pin(id string) error {
+	if _, err := bm.GetSnapshot(id); err != nil {
+		return err
+	}
+	pins, err := bm.readPins()
+	if err != nil {
+		return err
+	}
+	pins[id] = true
+	return bm.writePins(pins)
+}
+
+// Unpin 取消固定备份
+func (bm *BackupManager) Unpin(id string) error {
+	pins, err := bm.readPins()
+	if err != nil {
+		return err
+	}
+	if !pins[id] {
+		return fmt.Errorf("backup %s is not pinned", id)
+	}
+	delete(pins, id)
+	return bm.writePins(pins)
+}
@@ -0,0 +1,332 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"nrmgo/internal/checker"
+)
+
+// timestampLayout 备份目录名使用的时间格式
+const timestampLayout = "20060102_150405"
+
+// Snapshot 表示一次备份
+type Snapshot struct {
+	ID        string    // 备份 ID，即时间戳
+	Time      time.Time // 备份时间
+	Path      string    // 备份目录或归档文件路径，Git 存储中为仓库路径
+	Commit    string    // Git 存储中备份对应的提交
+	Files     []string  // 备份的文件在备份中的相对路径
+	Manifest  *Manifest // 备份清单，旧版本创建的备份为 nil
+	Pinned    bool      // 是否固定，固定的备份不会被保留策略删除
+	Archive   bool      // 是否为单个 .tar.gz 归档
+	Encrypted bool      // 归档是否加密
+	Err       error     // 无法读取备份清单的原因，损坏的备份仍会被列出，以便校验与清理
+
+	trigger Trigger // 加密归档文件名中的触发方式，解密前代替清单中的触发方式
+}
+
+// Trigger 返回备份的触发方式，优先使用清单，没有清单的旧版本备份视为手动备份
+func (s *Snapshot) Trigger() Trigger {
+	switch {
+	case s.Manifest != nil:
+		return s.Manifest.Trigger
+	case s.trigger != "":
+		return s.trigger
+	default:
+		return TriggerManual
+	}
+}
+
+// Locked 判断备份是否为尚未解密的加密归档，此时没有清单与文件列表
+func (s *Snapshot) Locked() bool {
+	return s.Encrypted && s.Manifest == nil
+}
+
+// Managers 返回备份中包含的包管理器，没有清单时返回 nil
+func (s *Snapshot) Managers() []string {
+	if s.Manifest == nil {
+		return nil
+	}
+	var managers []string
+	seen := make(map[string]bool)
+	for _, file := range s.Manifest.Files {
+		if !seen[file.Manager] {
+			seen[file.Manager] = true
+			managers = append(managers, file.Manager)
+		}
+	}
+	return managers
+}
+
+// Size 返回备份占用的大小，归档备份为归档文件的大小
+func (s *Snapshot) Size() int64 {
+	var size int64
+	if s.Archive {
+		if info, err := os.Stat(s.Path); err == nil {
+			size = info.Size()
+		}
+		return size
+	}
+	if s.Manifest != nil {
+		// 多个包管理器共用的文件只保存了一次
+		seen := make(map[string]bool)
+		for _, file := range s.Manifest.Files {
+			if !seen[file.Path] {
+				seen[file.Path] = true
+				size += file.Size
+			}
+		}
+		return size
+	}
+	for _, file := range s.Files {
+		if info, err := os.Stat(filepath.Join(s.Path, file)); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// RestoreItem 表示一个待恢复的配置文件
+// npm 与 pnpm 共用 .npmrc，同一个文件只恢复一次
+type RestoreItem struct {
+	Managers   []string    // 使用该文件的包管理器
+	TargetPath string      // 恢复的目标路径
+	Scope      string      // 目标文件的层级：project、user 或 global
+	Current    []byte      // 目标文件当前的内容，文件不存在时为 nil
+	Backup     []byte      // 备份文件的内容
+	Mode       os.FileMode // 新建目标文件时使用的权限
+	Exists     bool        // 目标文件当前是否存在
+	Absent     bool        // 备份时目标文件不存在，恢复即删除目标文件
+}
+
+// Changed 判断恢复是否会修改目标文件
+func (item *RestoreItem) Changed() bool {
+	if item.Absent {
+		return item.Exists
+	}
+	return !item.Exists || !bytes.Equal(item.Current, item.Backup)
+}
+
+// Unlock 解密加密的归档，读取其中的清单与文件列表，未加密或已解密的备份不做处理
+func (bm *BackupManager) Unlock(snapshot *Snapshot) error {
+	if !snapshot.Locked() {
+		return nil
+	}
+	manifest, err := bm.Store.Manifest(snapshot)
+	if err != nil {
+		return err
+	}
+	snapshot.Manifest = manifest
+	snapshot.Files = manifestPaths(manifest)
+	return nil
+}
+
+// GetSnapshot 获取指定时间戳的备份
+func (bm *BackupManager) GetSnapshot(id string) (*Snapshot, error) {
+	snapshots, err := bm.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		if snapshots[i].ID == id {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("backup %s not found", id)
+}
+
+// PlanRestore 读取备份与当前的配置文件，生成恢复计划
+// managers 为空时恢复备份中所有包管理器的配置，备份中没有的文件会被跳过
+// 有清单的备份恢复到原始路径，旧版本的备份恢复到包管理器当前的配置文件路径
+func (bm *BackupManager) PlanRestore(snapshot *Snapshot, managers []string) ([]*RestoreItem, error) {
+	if snapshot.Err != nil {
+		return nil, fmt.Errorf("backup %s is damaged: %w", snapshot.ID, snapshot.Err)
+	}
+	if err := bm.Unlock(snapshot); err != nil {
+		return nil, err
+	}
+	if len(managers) == 0 {
+		managers = snapshot.Managers()
+	}
+	if len(managers) == 0 {
+		managers = bm.GetAllManagers()
+	}
+	sort.Strings(managers)
+
+	byTarget := make(map[string]*RestoreItem)
+	var items []*RestoreItem
+	for _, name := range managers {
+		for _, loc := range bm.locate(snapshot, name) {
+			// 同一个文件只恢复一次
+			if item, ok := byTarget[loc.target]; ok {
+				if !slices.Contains(item.Managers, name) {
+					item.Managers = append(item.Managers, name)
+				}
+				continue
+			}
+
+			// 备份时不存在的文件不恢复
+			if loc.missing {
+				continue
+			}
+
+			// 备份中没有该文件
+			data, err := bm.ReadFile(snapshot, loc.rel)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to read %s: %w", loc.rel, err)
+			}
+
+			item, err := newRestoreItem(name, loc, data)
+			if err != nil {
+				return nil, err
+			}
+			byTarget[loc.target] = item
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Earliest 在最早记录了 target 的备份中读取该文件，生成恢复计划
+// 最早的备份中该文件不存在时，恢复计划为删除该文件（Absent）
+// 损坏的备份与 skip 返回 true 的备份被跳过，没有备份记录该文件时返回 nil
+func (bm *BackupManager) Earliest(target string, skip func(*Snapshot) bool) (*Snapshot, *RestoreItem, error) {
+	snapshots, err := bm.ListSnapshots()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target = filepath.Clean(target)
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := &snapshots[i]
+		if snapshot.Err != nil {
+			continue
+		}
+		if err := bm.Unlock(snapshot); err != nil {
+			return nil, nil, err
+		}
+		if skip != nil && skip(snapshot) {
+			continue
+		}
+		managers := snapshot.Managers()
+		if len(managers) == 0 {
+			managers = bm.GetAllManagers()
+		}
+		for _, name := range managers {
+			for _, loc := range bm.locate(snapshot, name) {
+				if filepath.Clean(loc.target) != target {
+					continue
+				}
+				if loc.missing {
+					item, err := newRestoreItem(name, loc, nil)
+					if err != nil {
+						return nil, nil, err
+					}
+					item.Absent = true
+					return snapshot, item, nil
+				}
+				data, err := bm.ReadFile(snapshot, loc.rel)
+				if os.IsNotExist(err) {
+					continue
+				}
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to read %s from backup %s: %w", loc.rel, snapshot.ID, err)
+				}
+				item, err := newRestoreItem(name, loc, data)
+				if err != nil {
+					return nil, nil, err
+				}
+				return snapshot, item, nil
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// newRestoreItem 读取目标文件当前的内容，生成恢复项
+func newRestoreItem(name string, loc location, data []byte) (*RestoreItem, error) {
+	item := &RestoreItem{
+		Managers:   []string{name},
+		TargetPath: loc.target,
+		Scope:      loc.scope,
+		Backup:     data,
+		Mode:       loc.mode,
+	}
+	current, err := os.ReadFile(loc.target)
+	switch {
+	case err == nil:
+		item.Current = current
+		item.Exists = true
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read %s: %w", loc.target, err)
+	}
+	return item, nil
+}
+
+// location 备份中的一个文件与其恢复的目标
+type location struct {
+	rel     string      // 在备份中的相对路径
+	target  string      // 恢复的目标路径
+	scope   string      // 目标文件的层级
+	mode    os.FileMode // 新建目标文件时使用的权限
+	missing bool        // 备份时目标文件不存在
+}
+
+// locate 返回包管理器在备份中的所有文件
+// 旧版本的备份只包含用户级配置文件，恢复到包管理器当前的用户级配置文件路径
+func (bm *BackupManager) locate(snapshot *Snapshot, name string) []location {
+	if snapshot.Manifest != nil {
+		var locations []location
+		for _, file := range snapshot.Manifest.FilesOf(name) {
+			mode := file.Mode.Perm()
+			if mode == 0 {
+				mode = fileMode
+			}
+			locations = append(locations, location{rel: file.Path, target: file.Source, scope: file.Scope, mode: mode, missing: file.Missing})
+		}
+		return locations
+	}
+
+	manager := bm.GetManager(name)
+	if manager == nil || manager.UserFile() == "" {
+		return nil
+	}
+	target := manager.UserFile()
+	rel := filepath.Base(target)
+	mode := os.FileMode(fileMode)
+	if info, err := os.Stat(filepath.Join(snapshot.Path, rel)); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return []location{{rel: rel, target: target, scope: string(checker.ScopeUser), mode: mode}}
+}
+
+// Restore 将备份文件写回目标路径，新建的文件使用备份时的权限，备份时不存在的文件被删除
+func (bm *BackupManager) Restore(items []*RestoreItem) error {
+	for _, item := range items {
+		if !item.Changed() {
+			continue
+		}
+		if item.Absent {
+			if err := os.Remove(item.TargetPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete %s: %w", item.TargetPath, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(item.TargetPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", item.TargetPath, err)
+		}
+		if err := os.WriteFile(item.TargetPath, item.Backup, item.Mode); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", item.TargetPath, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEarliest(t *testing.T) {
+	dir := t.TempDir()
+	npmrc := filepath.Join(dir, ".npmrc")
+	bunfig := filepath.Join(dir, "bunfig.toml")
+	if err := os.WriteFile(npmrc, []byte("current\n"), fileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &BackupManager{ExecPath: dir}
+	bm.Store = NewDirStore(filepath.Join(dir, "backups"))
+	saveSnapshots(t, bm.Store, TriggerAuto, map[string][]string{
+		"20240101_000000": {"!" + npmrc},
+		"20240102_000000": {npmrc, "!" + bunfig},
+		"20240103_000000": {npmrc},
+	})
+
+	tests := []struct {
+		target   string
+		skip     string // 跳过的备份
+		snapshot string // 为空时没有备份记录该文件
+		backup   string
+		absent   bool
+		exists   bool
+		changed  bool
+	}{
+		// 最早的备份中文件不存在，恢复即删除
+		{target: npmrc, snapshot: "20240101_000000", absent: true, exists: true, changed: true},
+		{target: npmrc, skip: "20240101_000000", snapshot: "20240102_000000", backup: "20240102_000000", exists: true, changed: true},
+		// 文件现在同样不存在，不需要修改
+		{target: bunfig, snapshot: "20240102_000000", absent: true},
+		{target: filepath.Join(dir, ".yarnrc")},
+	}
+	for _, tt := range tests {
+		skip := func(snapshot *Snapshot) bool { return snapshot.ID == tt.skip }
+		snapshot, item, err := bm.Earliest(tt.target, skip)
+		if err != nil {
+			t.Fatalf("Earliest(%q) error: %v", tt.target, err)
+		}
+		if tt.snapshot == "" {
+			if snapshot != nil || item != nil {
+				t.Errorf("Earliest(%q) = %v, %v, want nil", tt.target, snapshot, item)
+			}
+			continue
+		}
+		if snapshot == nil || item == nil {
+			t.Errorf("Earliest(%q) = nil, want %s", tt.target, tt.snapshot)
+			continue
+		}
+		if snapshot.ID != tt.snapshot || string(item.Backup) != tt.backup || item.Absent != tt.absent ||
+			item.Exists != tt.exists || item.Changed() != tt.changed {
+			t.Errorf("Earliest(%q, skip %q) = %s {backup %q absent %v exists %v changed %v}, want %s {backup %q absent %v exists %v changed %v}",
+				tt.target, tt.skip, snapshot.ID, item.Backup, item.Absent, item.Exists, item.Changed(),
+				tt.snapshot, tt.backup, tt.absent, tt.exists, tt.changed)
+		}
+	}
+}
+
+func TestPlanRestoreSkipsMissing(t *testing.T) {
+	dir := t.TempDir()
+	npmrc := filepath.Join(dir, ".npmrc")
+	bunfig := filepath.Join(dir, "bunfig.toml")
+
+	bm := &BackupManager{ExecPath: dir}
+	bm.Store = NewDirStore(filepath.Join(dir, "backups"))
+	saveSnapshots(t, bm.Store, TriggerAuto, map[string][]string{
+		"20240101_000000": {"!" + npmrc, "!" + bunfig},
+		"20240102_000000": {npmrc, "!" + bunfig},
+	})
+
+	tests := []struct {
+		id   string
+		want []string // 恢复的目标文件
+	}{
+		{id: "20240101_000000", want: nil},
+		{id: "20240102_000000", want: []string{npmrc}},
+	}
+	for _, tt := range tests {
+		snapshot, err := bm.GetSnapshot(tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items, err := bm.PlanRestore(snapshot, nil)
+		if err != nil {
+			t.Fatalf("PlanRestore(%s) error: %v", tt.id, err)
+		}
+		var got []string
+		for _, item := range items {
+			got = append(got, item.TargetPath)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PlanRestore(%s) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreAbsent(t *testing.T) {
+	dir := t.TempDir()
+	npmrc := filepath.Join(dir, ".npmrc")
+	if err := os.WriteFile(npmrc, []byte("registry=https://a/\n"), fileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &BackupManager{ExecPath: dir}
+	bm.Store = NewDirStore(filepath.Join(dir, "backups"))
+	saveSnapshots(t, bm.Store, TriggerAuto, map[string][]string{
+		"20240101_000000": {"!" + npmrc},
+	})
+
+	_, item, err := bm.Earliest(npmrc, nil)
+	if err != nil || item == nil {
+		t.Fatalf("Earliest(%q) = %v, %v", npmrc, item, err)
+	}
+	if err := bm.Restore([]*RestoreItem{item}); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if _, err := os.Stat(npmrc); !os.IsNotExist(err) {
+		t.Errorf("Restore() left %s, want it deleted", npmrc)
+	}
+}
@@ -0,0 +1,203 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Policy 备份的保留策略
+// 备份被任一 Keep 规则选中即保留，没有设置任何 Keep 规则时保留所有备份
+type Policy struct {
+	KeepLast    int           // 保留最新的 N 个备份
+	KeepDaily   int           // 保留最近 N 个有备份的天中每天最新的备份
+	KeepWeekly  int           // 保留最近 N 个有备份的周中每周最新的备份
+	KeepMonthly int           // 保留最近 N 个有备份的月中每月最新的备份
+	MaxAge      time.Duration // 删除早于该时间的备份，即使被 Keep 规则选中，0 表示不限制
+}
+
+// hasKeepRules 判断是否设置了 Keep 规则
+func (p Policy) hasKeepRules() bool {
+	return p.KeepLast > 0 || p.KeepDaily > 0 || p.KeepWeekly > 0 || p.KeepMonthly > 0
+}
+
+// Decision 保留策略对一个备份的处理结果
+type Decision struct {
+	Snapshot Snapshot // 备份
+	Size     int64    // 备份文件的总大小
+	Keep     bool     // 是否保留
+	Reasons  []string // 保留或删除的原因
+}
+
+// 保留或删除备份的原因
+const (
+	ReasonPinned   = "pinned"   // 固定的备份
+	ReasonFirst    = "first"    // 最早记录某个文件的自动备份
+	ReasonLast     = "last"     // 被 KeepLast 选中
+	ReasonDaily    = "daily"    // 被 KeepDaily 选中
+	ReasonWeekly   = "weekly"   // 被 KeepWeekly 选中
+	ReasonMonthly  = "monthly"  // 被 KeepMonthly 选中
+	ReasonNoPolicy = "policy"   // 没有适用的保留策略
+	ReasonNoRule   = "unneeded" // 没有被任何 Keep 规则选中
+	ReasonMaxAge   = "too old"  // 超过最长保留时间
+	ReasonMaxSize  = "too big"  // 超过总大小上限
+)
+
+// apply 按保留策略决定每个备份是否保留，snapshots 需按时间从新到旧排列
+func (p Policy) apply(snapshots []Snapshot, now time.Time) []Decision {
+	decisions := make([]Decision, len(snapshots))
+	for i, snapshot := range snapshots {
+		decisions[i] = Decision{Snapshot: snapshot, Size: snapshot.Size(), Keep: true}
+	}
+
+	// 按规则选择需要保留的备份，固定的备份不占用名额
+	rules := []struct {
+		reason string
+		count  int
+		period func(time.Time) string
+	}{
+		{ReasonLast, p.KeepLast, func(t time.Time) string { return t.String() }},
+		{ReasonDaily, p.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{ReasonWeekly, p.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{ReasonMonthly, p.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, rule := range rules {
+		seen := make(map[string]bool)
+		for i := range decisions {
+			if len(seen) >= rule.count {
+				break
+			}
+			if decisions[i].Snapshot.Pinned {
+				continue
+			}
+			period := rule.period(decisions[i].Snapshot.Time)
+			if !seen[period] {
+				seen[period] = true
+				decisions[i].Reasons = append(decisions[i].Reasons, rule.reason)
+			}
+		}
+	}
+
+	for i := range decisions {
+		decision := &decisions[i]
+		switch {
+		case decision.Snapshot.Pinned:
+			decision.Reasons = []string{ReasonPinned}
+		case p.MaxAge > 0 && now.Sub(decision.Snapshot.Time) > p.MaxAge:
+			decision.Keep = false
+			decision.Reasons = []string{ReasonMaxAge}
+		case p.hasKeepRules() && len(decision.Reasons) == 0:
+			decision.Keep = false
+			decision.Reasons = []string{ReasonNoRule}
+		}
+	}
+	return decisions
+}
+
+// Prune 按保留策略清理备份，policies 为每种触发方式的保留策略，旧版本没有清单的备份视为手动备份
+// 最早记录每个文件的自动备份保存着 nrmgo 修改前的内容，nrmgo clean 依赖它们还原，与固定的备份一样不会被删除
+// maxSize 大于 0 时，保留的备份总大小超出上限则从最旧的备份开始删除，但不会删除最新的备份
+// dryRun 为 true 时只返回处理结果，不删除任何备份；返回的结果按时间从新到旧排列
+func (bm *BackupManager) Prune(policies map[Trigger]Policy, maxSize int64, dryRun bool) ([]Decision, error) {
+	snapshots, err := bm.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	// 按触发方式分组应用保留策略
+	groups := make(map[Trigger][]Snapshot)
+	for _, snapshot := range snapshots {
+		trigger := snapshot.Trigger()
+		groups[trigger] = append(groups[trigger], snapshot)
+	}
+	firsts := firstSnapshots(groups[TriggerAuto])
+	byID := make(map[string]Decision, len(snapshots))
+	now := time.Now()
+	for trigger, group := range groups {
+		policy, ok := policies[trigger]
+		for _, decision := range policy.apply(group, now) {
+			switch {
+			case decision.Snapshot.Pinned:
+			case firsts[decision.Snapshot.ID]:
+				decision.Keep = true
+				decision.Reasons = []string{ReasonFirst}
+			case !ok:
+				decision.Reasons = []string{ReasonNoPolicy}
+			}
+			byID[decision.Snapshot.ID] = decision
+		}
+	}
+
+	decisions := make([]Decision, len(snapshots))
+	for i, snapshot := range snapshots {
+		decisions[i] = byID[snapshot.ID]
+	}
+
+	// 总大小超出上限时从最旧的备份开始删除
+	if maxSize > 0 {
+		var total int64
+		for _, decision := range decisions {
+			if decision.Keep {
+				total += decision.Size
+			}
+		}
+		for i := len(decisions) - 1; i > 0 && total > maxSize; i-- {
+			decision := &decisions[i]
+			if !decision.Keep || decision.Snapshot.Pinned || firsts[decision.Snapshot.ID] {
+				continue
+			}
+			decision.Keep = false
+			decision.Reasons = []string{ReasonMaxSize}
+			total -= decision.Size
+		}
+	}
+
+	if dryRun {
+		return decisions, nil
+	}
+	for _, decision := range decisions {
+		if decision.Keep {
+			continue
+		}
+		if err := bm.Store.Remove(&decision.Snapshot); err != nil {
+			return decisions, err
+		}
+	}
+	return decisions, nil
+}
+
+// PruneAuto 清理自动备份：只保留最新的 keep 个，并删除超过 maxAge 的备份
+// keep 或 maxAge 不大于 0 时不限制，手动创建的备份、固定的备份与最早记录每个文件的自动备份不受影响
+// 不能删除备份的存储（例如 GitStore）跳过清理
+func (bm *BackupManager) PruneAuto(keep int, maxAge time.Duration) ([]Decision, error) {
+	decisions, err := bm.Prune(map[Trigger]Policy{
+		TriggerAuto: {KeepLast: keep, MaxAge: maxAge},
+	}, 0, false)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return nil, nil
+	}
+	return decisions, err
+}
+
+// firstSnapshots 返回最早记录每个文件的备份，包括记录文件不存在的备份
+// snapshots 需按时间从新到旧排列；损坏的备份与未解密的加密备份无法读取清单，不参与判断
+func firstSnapshots(snapshots []Snapshot) map[string]bool {
+	firsts := make(map[string]bool)
+	seen := make(map[string]bool)
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		if snapshot.Err != nil || snapshot.Manifest == nil {
+			continue
+		}
+		for _, file := range snapshot.Manifest.Files {
+			if !seen[file.Source] {
+				seen[file.Source] = true
+				firsts[snapshot.ID] = true
+			}
+		}
+	}
+	return firsts
+}
@@ -0,0 +1,260 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyApply(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	// 按时间从新到旧排列，03-10 与 03-14 分属第 10、11 周
+	snapshots := []Snapshot{
+		{ID: "a", Time: at(3, 15, 10)},
+		{ID: "b", Time: at(3, 15, 9)},
+		{ID: "c", Time: at(3, 14, 10)},
+		{ID: "d", Time: at(3, 10, 10)},
+		{ID: "e", Time: at(2, 20, 10)},
+		{ID: "f", Time: at(1, 5, 10)},
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		pinned string
+		want   []string // 每个备份的处理结果：ID、keep 或 delete、原因
+	}{
+		{
+			name:   "no rules",
+			policy: Policy{},
+			want:   []string{"a keep", "b keep", "c keep", "d keep", "e keep", "f keep"},
+		},
+		{
+			name:   "last",
+			policy: Policy{KeepLast: 2},
+			want:   []string{"a keep last", "b keep last", "c delete unneeded", "d delete unneeded", "e delete unneeded", "f delete unneeded"},
+		},
+		{
+			name:   "daily",
+			policy: Policy{KeepDaily: 2},
+			want:   []string{"a keep daily", "b delete unneeded", "c keep daily", "d delete unneeded", "e delete unneeded", "f delete unneeded"},
+		},
+		{
+			name:   "weekly",
+			policy: Policy{KeepWeekly: 2},
+			want:   []string{"a keep weekly", "b delete unneeded", "c delete unneeded", "d keep weekly", "e delete unneeded", "f delete unneeded"},
+		},
+		{
+			name:   "monthly",
+			policy: Policy{KeepMonthly: 3},
+			want:   []string{"a keep monthly", "b delete unneeded", "c delete unneeded", "d delete unneeded", "e keep monthly", "f keep monthly"},
+		},
+		{
+			name:   "combined",
+			policy: Policy{KeepLast: 1, KeepDaily: 2, KeepMonthly: 2},
+			want:   []string{"a keep last,daily,monthly", "b delete unneeded", "c keep daily", "d delete unneeded", "e keep monthly", "f delete unneeded"},
+		},
+		{
+			// 固定的备份不占用名额
+			name:   "pinned",
+			policy: Policy{KeepLast: 1},
+			pinned: "a",
+			want:   []string{"a keep pinned", "b keep last", "c delete unneeded", "d delete unneeded", "e delete unneeded", "f delete unneeded"},
+		},
+		{
+			name:   "max age",
+			policy: Policy{KeepMonthly: 3, MaxAge: 30 * 24 * time.Hour},
+			pinned: "f",
+			want:   []string{"a keep monthly", "b delete unneeded", "c delete unneeded", "d delete unneeded", "e keep monthly", "f keep pinned"},
+		},
+		{
+			name:   "max age without rules",
+			policy: Policy{MaxAge: 30 * 24 * time.Hour},
+			want:   []string{"a keep", "b keep", "c keep", "d keep", "e keep", "f delete too old"},
+		},
+	}
+	for _, tt := range tests {
+		input := append([]Snapshot(nil), snapshots...)
+		for i := range input {
+			input[i].Pinned = input[i].ID == tt.pinned
+		}
+		var got []string
+		for _, decision := range tt.policy.apply(input, now) {
+			action := "delete"
+			if decision.Keep {
+				action = "keep"
+			}
+			got = append(got, strings.TrimSpace(fmt.Sprintf("%s %s %s", decision.Snapshot.ID, action, strings.Join(decision.Reasons, ","))))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("apply(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPruneMaxSize(t *testing.T) {
+	// 每个备份 15 字节
+	snapshots := map[string][]string{
+		"20240101_000000": {"/home/.npmrc"},
+		"20240102_000000": {"/home/.npmrc"},
+		"20240103_000000": {"/home/.npmrc"},
+		"20240104_000000": {"/home/.npmrc"},
+	}
+
+	tests := []struct {
+		maxSize int64
+		pinned  string
+		want    []string // 保留的备份，按时间从新到旧排列
+	}{
+		{maxSize: 0, want: []string{"20240104_000000", "20240103_000000", "20240102_000000", "20240101_000000"}},
+		{maxSize: 40, want: []string{"20240104_000000", "20240103_000000"}},
+		{maxSize: 40, pinned: "20240101_000000", want: []string{"20240104_000000", "20240101_000000"}},
+		// 最新的备份不会被删除
+		{maxSize: 10, want: []string{"20240104_000000"}},
+	}
+	for _, tt := range tests {
+		bm := &BackupManager{ExecPath: t.TempDir()}
+		bm.Store = NewDirStore(bm.ExecPath)
+		saveSnapshots(t, bm.Store, TriggerManual, snapshots)
+		if tt.pinned != "" {
+			if err := bm.Pin(tt.pinned); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		decisions, err := bm.Prune(map[Trigger]Policy{TriggerManual: {}}, tt.maxSize, true)
+		if err != nil {
+			t.Fatalf("Prune(%d) error: %v", tt.maxSize, err)
+		}
+		var got []string
+		for _, decision := range decisions {
+			if decision.Keep {
+				got = append(got, decision.Snapshot.ID)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Prune(%d, pinned %q) kept %v, want %v", tt.maxSize, tt.pinned, got, tt.want)
+		}
+	}
+}
+
+// saveSnapshots 在 store 中保存备份，sources 为每个备份记录的文件，以 ! 开头的文件记录为不存在
+// 每个文件的内容为备份 ID
+func saveSnapshots(t *testing.T, store Store, trigger Trigger, snapshots map[string][]string) {
+	t.Helper()
+	for id, sources := range snapshots {
+		created, err := snapshotTime(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest := &Manifest{ID: id, CreatedAt: created, Trigger: trigger, Command: "use"}
+		var entries []Entry
+		for i, source := range sources {
+			if source[0] == '!' {
+				manifest.Files = append(manifest.Files, ManifestFile{Manager: "npm", Source: source[1:], Missing: true})
+				continue
+			}
+			rel := "npm/user/" + string(rune('a'+i))
+			manifest.Files = append(manifest.Files, ManifestFile{Manager: "npm", Source: source, Path: rel, Size: int64(len(id))})
+			entries = append(entries, Entry{Path: rel, Data: []byte(id), Mode: fileMode})
+		}
+		if _, err := store.Save(manifest, entries); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPruneAutoKeepsFirst(t *testing.T) {
+	snapshots := map[string][]string{
+		"20240101_000000": {"!/home/.npmrc"},
+		"20240102_000000": {"/home/.npmrc"},
+		"20240103_000000": {"/home/.npmrc", "/home/.bunfig.toml"},
+		"20240104_000000": {"/home/.npmrc"},
+		"20240105_000000": {"/home/.npmrc"},
+	}
+
+	// 剩余的备份按时间从新到旧排列
+	tests := []struct {
+		keep int
+		want []string
+	}{
+		{keep: 1, want: []string{"20240105_000000", "20240103_000000", "20240101_000000"}},
+		{keep: 2, want: []string{"20240105_000000", "20240104_000000", "20240103_000000", "20240101_000000"}},
+		{keep: 0, want: []string{"20240105_000000", "20240104_000000", "20240103_000000", "20240102_000000", "20240101_000000"}},
+	}
+	for _, tt := range tests {
+		bm := &BackupManager{ExecPath: t.TempDir()}
+		bm.Store = NewDirStore(bm.ExecPath)
+		saveSnapshots(t, bm.Store, TriggerAuto, snapshots)
+
+		if _, err := bm.PruneAuto(tt.keep, 0); err != nil {
+			t.Fatalf("PruneAuto(%d, 0) error: %v", tt.keep, err)
+		}
+		remaining, err := bm.ListSnapshots()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, snapshot := range remaining {
+			got = append(got, snapshot.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PruneAuto(%d, 0) kept %v, want %v", tt.keep, got, tt.want)
+		}
+	}
+}
+
+func TestFirstSnapshots(t *testing.T) {
+	snapshot := func(id string, sources ...string) Snapshot {
+		manifest := &Manifest{ID: id, Trigger: TriggerAuto}
+		for _, source := range sources {
+			manifest.Files = append(manifest.Files, ManifestFile{Source: source})
+		}
+		return Snapshot{ID: id, Manifest: manifest}
+	}
+
+	tests := []struct {
+		name      string
+		snapshots []Snapshot // 按时间从新到旧排列
+		want      map[string]bool
+	}{
+		{
+			name:      "empty",
+			snapshots: nil,
+			want:      map[string]bool{},
+		},
+		{
+			name: "oldest per file",
+			snapshots: []Snapshot{
+				snapshot("3", "/a", "/b"),
+				snapshot("2", "/b"),
+				snapshot("1", "/a"),
+			},
+			want: map[string]bool{"1": true, "2": true},
+		},
+		{
+			// 无法读取清单的备份不参与判断
+			name: "locked and damaged",
+			snapshots: []Snapshot{
+				snapshot("3", "/a"),
+				{ID: "2", Encrypted: true},
+				{ID: "1", Err: errTest},
+			},
+			want: map[string]bool{"3": true},
+		},
+	}
+	for _, tt := range tests {
+		if got := firstSnapshots(tt.snapshots); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("firstSnapshots(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// errTest 测试中损坏的备份使用的错误
+var errTest = errors.New("damaged")
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"os"
+	"sort"
+	"time"
+)
+
+// Store 备份的存储后端，BackupManager 通过 Store 保存与读取备份
+// 默认使用 DirStore，将每个备份保存为目录或归档文件；GitStore 将每个备份提交到本地 git 仓库
+type Store interface {
+	// Save 保存一个备份，返回备份的位置：目录、归档文件路径或提交
+	Save(manifest *Manifest, entries []Entry) (string, error)
+	// List 列出所有备份，不要求排序，无法读取清单的备份设置 Err 后仍然返回
+	List() ([]Snapshot, error)
+	// Exists 判断指定 ID 的备份是否已存在
+	Exists(id string) bool
+	// Manifest 返回包含校验和的完整清单，旧版本的备份没有清单，返回 nil
+	Manifest(snapshot *Snapshot) (*Manifest, error)
+	// ReadFile 读取备份中的文件，备份中没有该文件时返回的错误满足 os.IsNotExist
+	ReadFile(snapshot *Snapshot, rel string) ([]byte, error)
+	// Remove 删除备份，无法删除备份的存储返回满足 errors.ErrUnsupported 的错误
+	Remove(snapshot *Snapshot) error
+}
+
+// Entry 一个待写入备份的文件
+type Entry struct {
+	Path string      // 在备份中的相对路径，使用 / 分隔
+	Data []byte      // 文件内容
+	Mode os.FileMode // 源文件的权限
+}
+
+// ListSnapshots 列出所有备份，按时间从新到旧排列
+func (bm *BackupManager) ListSnapshots() ([]Snapshot, error) {
+	snapshots, err := bm.Store.List()
+	if err != nil {
+		return nil, err
+	}
+	pins, err := bm.readPins()
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		snapshots[i].Pinned = pins[snapshots[i].ID]
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// ReadFile 读取备份中的文件，rel 为文件在备份中的相对路径
+// 备份中没有该文件时返回的错误满足 os.IsNotExist
+func (bm *BackupManager) ReadFile(snapshot *Snapshot, rel string) ([]byte, error) {
+	return bm.Store.ReadFile(snapshot, rel)
+}
+
+// snapshotTime 从备份 ID 中解析备份时间
+func snapshotTime(id string) (time.Time, error) {
+	return time.ParseInLocation(timestampLayout, id, time.Local)
+}
+
+// manifestPaths 返回清单中的文件在备份中的相对路径，多个包管理器共用的文件只返回一次
+// 备份时不存在的文件不在备份中
+func manifestPaths(manifest *Manifest) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, file := range manifest.Files {
+		if !file.Missing && !seen[file.Path] {
+			seen[file.Path] = true
+			paths = append(paths, file.Path)
+		}
+	}
+	return paths
+}
@@ -1,23 +1,63 @@
 package backup
 
+import "nrmgo/internal/checker"
+
 // Manager 包管理器配置
 type Manager struct {
-	Name       string   // 包管理器名称
-	ConfigFile string   // 配置文件名
-	Paths      []string // 可能的配置文件路径
+	Name      string               // 包管理器名称
+	Files     []checker.ConfigFile // 包管理器读取的所有配置文件，按优先级排列
+	Version   string               // 包管理器版本，未安装时为空
+	Registry  string               // 当前生效的 registry
+	Installed bool                 // 包管理器是否已安装
+}
+
+// UserFile 返回用户级配置文件的路径，没有时返回第一个配置文件
+// 旧版本的备份只包含用户级配置文件
+func (m *Manager) UserFile() string {
+	for _, file := range m.Files {
+		if file.Scope == checker.ScopeUser {
+			return file.Path
+		}
+	}
+	if len(m.Files) > 0 {
+		return m.Files[0].Path
+	}
+	return ""
 }
 
 // BackupManager 备份管理器
 type BackupManager struct {
 	ExecPath string              // 程序所在路径
 	Managers map[string]*Manager // 包管理器配置
+	Version  string              // nrmgo 版本，写入备份清单
+	Exclude  []string            // 不备份的文件，不含路径分隔符的模式匹配文件名，否则匹配完整路径
+	Store    Store               // 备份的存储，默认为数据目录下 backups 中的 DirStore
 }
 
+const (
+	// ConfigManager nrmgo 自身的配置文件在备份中使用的名称
+	ConfigManager = "nrmgo"
+	// IncludeManager [backup] include 额外指定的文件在备份中使用的名称
+	IncludeManager = "include"
+)
+
+// Trigger 备份的触发方式
+type Trigger string
+
+const (
+	// TriggerManual 用户执行 nrmgo backup 创建的备份
+	TriggerManual Trigger = "manual"
+	// TriggerAuto nrmgo 在修改配置文件前自动创建的备份
+	TriggerAuto Trigger = "auto"
+)
+
 // BackupResult 备份结果
+// 每个配置文件一个结果，包管理器没有任何配置文件时记录一个失败的结果
 type BackupResult struct {
+	Snapshot   string // 备份目录名
 	Manager    string // 包管理器名称
 	SourcePath string // 源文件路径
-	BackupPath string // 备份路径
+	BackupPath string // 备份目录或归档文件路径
 	Success    bool   // 是否成功
 	Error      error  // 错误信息
 }
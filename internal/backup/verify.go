@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// CheckStatus 备份文件的校验结果
+type CheckStatus string
+
+const (
+	// CheckOK 文件完整
+	CheckOK CheckStatus = "ok"
+	// CheckUnverified 旧版本的备份没有清单，只检查了文件能否解析
+	CheckUnverified CheckStatus = "unverified"
+	// CheckMissing 清单中的文件不存在
+	CheckMissing CheckStatus = "missing"
+	// CheckTruncated 文件比清单中记录的小
+	CheckTruncated CheckStatus = "truncated"
+	// CheckModified 文件的大小或 SHA-256 与清单不一致
+	CheckModified CheckStatus = "modified"
+	// CheckCorrupt 文件无法按其格式解析
+	CheckCorrupt CheckStatus = "corrupt"
+	// CheckUnreadable 文件无法读取
+	CheckUnreadable CheckStatus = "unreadable"
+)
+
+// FileCheck 单个备份文件的校验结果
+type FileCheck struct {
+	Path   string      // 在备份中的相对路径
+	Source string      // 原始文件路径，旧版本的备份为空
+	Status CheckStatus // 校验结果
+	Detail string      // 失败的原因
+}
+
+// Passed 判断文件是否通过校验
+func (c *FileCheck) Passed() bool {
+	return c.Status == CheckOK || c.Status == CheckUnverified
+}
+
+// VerifyResult 一个备份的校验结果
+type VerifyResult struct {
+	Snapshot *Snapshot   // 校验的备份
+	Files    []FileCheck // 每个文件的校验结果
+	Error    error       // 无法校验整个备份的原因，例如清单损坏、归档损坏或无法解密
+}
+
+// Passed 判断备份是否通过校验
+func (r *VerifyResult) Passed() bool {
+	if r.Error != nil {
+		return false
+	}
+	for i := range r.Files {
+		if !r.Files[i].Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify 校验备份的完整性
+// 按清单重新计算每个文件的 SHA-256，发现缺失、截断与被修改的文件，并按配置文件格式解析每个文件
+// 清单由 Store 提供，归档备份使用归档内的清单，加密的归档先解密
+func (bm *BackupManager) Verify(snapshot *Snapshot) *VerifyResult {
+	result := &VerifyResult{Snapshot: snapshot}
+	if snapshot.Err != nil {
+		result.Error = snapshot.Err
+		return result
+	}
+
+	// 读取清单，加密归档文件头中的公开清单没有校验和，使用归档内的清单
+	manifest, err := bm.Store.Manifest(snapshot)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	// 旧版本的备份没有清单，只能检查文件能否解析
+	if manifest == nil {
+		for _, rel := range snapshot.Files {
+			check := FileCheck{Path: rel, Status: CheckUnverified}
+			data, err := bm.ReadFile(snapshot, rel)
+			if err != nil {
+				check.Status, check.Detail = CheckUnreadable, err.Error()
+			} else if err := Validate(DetectFormat(rel), data); err != nil {
+				check.Status, check.Detail = CheckCorrupt, err.Error()
+			}
+			result.Files = append(result.Files, check)
+		}
+		return result
+	}
+
+	// 多个包管理器共用的文件只校验一次，备份时不存在的文件没有内容可以校验
+	seen := make(map[string]bool)
+	for _, file := range manifest.Files {
+		if file.Missing || seen[file.Path] {
+			continue
+		}
+		seen[file.Path] = true
+		result.Files = append(result.Files, bm.verifyFile(snapshot, file))
+	}
+	return result
+}
+
+// verifyFile 按清单校验单个备份文件
+func (bm *BackupManager) verifyFile(snapshot *Snapshot, file ManifestFile) FileCheck {
+	check := FileCheck{Path: file.Path, Source: file.Source, Status: CheckOK}
+
+	data, err := bm.ReadFile(snapshot, file.Path)
+	switch {
+	case os.IsNotExist(err):
+		check.Status, check.Detail = CheckMissing, "file not found in backup"
+		return check
+	case err != nil:
+		check.Status, check.Detail = CheckUnreadable, err.Error()
+		return check
+	}
+
+	size := int64(len(data))
+	sum := sha256.Sum256(data)
+	switch {
+	case size < file.Size:
+		check.Status, check.Detail = CheckTruncated, fmt.Sprintf("%d of %d bytes", size, file.Size)
+	case size != file.Size:
+		check.Status, check.Detail = CheckModified, fmt.Sprintf("size %d, expected %d", size, file.Size)
+	case hex.EncodeToString(sum[:]) != file.SHA256:
+		check.Status, check.Detail = CheckModified, "SHA-256 mismatch"
+	default:
+		if err := Validate(DetectFormat(file.Source), data); err != nil {
+			check.Status, check.Detail = CheckCorrupt, err.Error()
+		}
+	}
+	return check
+}
@@ -0,0 +1,315 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"nrmgo/internal/latency"
+)
+
+const (
+	// DefaultConcurrency 默认的并发请求数量，与 npm 的 maxsockets 接近
+	DefaultConcurrency = 16
+	// defaultTimeout 单个元数据请求的超时时间，大型包的元数据可能有数 MB
+	defaultTimeout = 30 * time.Second
+	// maxErrors 每个 registry 保留的失败原因数量
+	maxErrors = 5
+	// abbreviatedAccept 请求精简版元数据（只包含安装所需的字段）
+	abbreviatedAccept = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8, */*"
+)
+
+// Bencher 模拟安装测试器：在每个 registry 上解析项目的完整依赖元数据
+type Bencher struct {
+	opts        *latency.Options
+	concurrency int
+}
+
+// NewBencher 创建模拟安装测试器，concurrency 为每个 registry 的并发请求数量
+func NewBencher(opts *latency.Options, concurrency int) *Bencher {
+	if opts == nil {
+		opts = latency.DefaultOptions()
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	benchOpts := *opts
+	benchOpts.Timeout = defaultTimeout
+
+	return &Bencher{
+		opts:        &benchOpts,
+		concurrency: concurrency,
+	}
+}
+
+// Bench 依次在每个 registry 上解析项目的依赖，每完成一个 registry 就通过 channel 返回结果
+// registry 之间不并发，避免相互争抢带宽影响结果
+func (b *Bencher) Bench(ctx context.Context, project *Project, targets []Target) <-chan *Result {
+	results := make(chan *Result)
+	go func() {
+		defer close(results)
+		for _, target := range targets {
+			select {
+			case results <- b.BenchOne(ctx, project, target):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results
+}
+
+// BenchOne 在单个 registry 上解析项目的依赖
+func (b *Bencher) BenchOne(ctx context.Context, project *Project, target Target) *Result {
+	// 每个 registry 使用新的客户端，连接建立的耗时计入结果
+	run := &run{
+		bencher:    b,
+		ctx:        ctx,
+		client:     latency.NewHTTPClient(b.opts),
+		target:     target,
+		semaphore:  make(chan struct{}, b.concurrency),
+		packuments: make(map[string]*packumentEntry),
+		visited:    make(map[Dependency]bool),
+		resolved:   make(map[Dependency]bool),
+		result:     &Result{Name: target.Name, URL: target.URL},
+	}
+
+	start := time.Now()
+	for _, dep := range project.Dependencies {
+		run.resolve(dep, !project.Locked)
+	}
+	run.wg.Wait()
+	run.result.Duration = time.Since(start)
+	run.client.CloseIdleConnections()
+	run.result.Cancelled = ctx.Err() != nil
+	return run.result
+}
+
+// packument 表示精简版的包元数据
+type packument struct {
+	DistTags map[string]string          `json:"dist-tags"`
+	Versions map[string]packageManifest `json:"versions"`
+}
+
+// packageManifest 表示单个版本中解析依赖所需的字段
+type packageManifest struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// packumentEntry 缓存一个包的元数据，同一个包只请求一次
+type packumentEntry struct {
+	done      chan struct{}
+	packument *packument
+	err       error
+}
+
+// run 表示在单个 registry 上的一次模拟安装
+type run struct {
+	bencher   *Bencher
+	ctx       context.Context
+	client    *http.Client
+	target    Target
+	semaphore chan struct{}
+	wg        sync.WaitGroup
+
+	mu         sync.Mutex
+	packuments map[string]*packumentEntry
+	visited    map[Dependency]bool // 已解析的依赖描述
+	resolved   map[Dependency]bool // 已解析到的包版本
+	result     *Result
+}
+
+// resolve 异步解析一个依赖，walk 为 true 时继续解析其依赖
+func (r *run) resolve(dep Dependency, walk bool) {
+	r.mu.Lock()
+	if r.visited[dep] {
+		r.mu.Unlock()
+		return
+	}
+	r.visited[dep] = true
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		doc, err := r.packument(dep.Name)
+		if err != nil {
+			if r.ctx.Err() == nil {
+				r.fail(fmt.Sprintf("%s: %v", dep.Name, err))
+			}
+			return
+		}
+
+		ver, ok := pickVersion(doc, dep.Spec)
+		if !ok {
+			r.fail(fmt.Sprintf("%s: no version matching %q", dep.Name, dep.Spec))
+			return
+		}
+
+		// 不同的版本范围可能解析到同一个版本，只解析一次其依赖
+		resolved := Dependency{Name: dep.Name, Spec: ver}
+		r.mu.Lock()
+		seen := r.resolved[resolved]
+		if !seen {
+			r.resolved[resolved] = true
+			r.result.Packages++
+		}
+		r.mu.Unlock()
+
+		if seen || !walk {
+			return
+		}
+		manifest := doc.Versions[ver]
+		for _, deps := range []map[string]string{manifest.Dependencies, manifest.OptionalDependencies} {
+			for name, spec := range deps {
+				if child, ok := registryDependency(name, spec); ok {
+					r.resolve(child, true)
+				}
+			}
+		}
+	}()
+}
+
+// packument 获取包的元数据，并发请求同一个包时只发出一次请求
+func (r *run) packument(name string) (*packument, error) {
+	r.mu.Lock()
+	entry, ok := r.packuments[name]
+	if !ok {
+		entry = &packumentEntry{done: make(chan struct{})}
+		r.packuments[name] = entry
+	}
+	r.mu.Unlock()
+
+	if ok {
+		select {
+		case <-entry.done:
+			return entry.packument, entry.err
+		case <-r.ctx.Done():
+			return nil, r.ctx.Err()
+		}
+	}
+
+	entry.packument, entry.err = r.fetch(name)
+	close(entry.done)
+	return entry.packument, entry.err
+}
+
+// fetch 请求包的精简版元数据
+func (r *run) fetch(name string) (*packument, error) {
+	select {
+	case r.semaphore <- struct{}{}: // 获取信号量
+		defer func() { <-r.semaphore }() // 释放信号量
+	case <-r.ctx.Done():
+		return nil, r.ctx.Err()
+	}
+
+	req, err := http.NewRequestWithContext(r.ctx, "GET", packumentURL(r.target.URL, name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", abbreviatedAccept)
+	req.Header.Set("User-Agent", r.bencher.opts.UserAgent)
+	for k, values := range r.target.Headers {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	r.mu.Lock()
+	r.result.Requests++
+	r.mu.Unlock()
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	counter := &countingReader{r: resp.Body}
+	doc := &packument{}
+	err = json.NewDecoder(counter).Decode(doc)
+
+	r.mu.Lock()
+	r.result.Bytes += counter.n
+	r.mu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %v", err)
+	}
+	return doc, nil
+}
+
+// fail 记录一次失败
+func (r *run) fail(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Failures++
+	if len(r.result.Errors) < maxErrors {
+		r.result.Errors = append(r.result.Errors, reason)
+	}
+}
+
+// pickVersion 按 npm 的规则选择版本：
+// 标签直接使用，latest 满足范围时优先使用，否则使用满足范围的最高版本
+func pickVersion(doc *packument, spec string) (string, bool) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		spec = "*"
+	}
+	if tagged, ok := doc.DistTags[spec]; ok {
+		_, exists := doc.Versions[tagged]
+		return tagged, exists
+	}
+	if _, ok := doc.Versions[spec]; ok {
+		return spec, true
+	}
+
+	r, err := parseRange(spec)
+	if err != nil {
+		return "", false
+	}
+	if latest, ok := doc.DistTags["latest"]; ok {
+		if v, err := parseVersion(latest); err == nil && r.match(v) {
+			if _, exists := doc.Versions[latest]; exists {
+				return latest, true
+			}
+		}
+	}
+
+	versions := make([]string, 0, len(doc.Versions))
+	for v := range doc.Versions {
+		versions = append(versions, v)
+	}
+	best := maxSatisfying(versions, r)
+	return best, best != ""
+}
+
+// packumentURL 返回包元数据的地址，scoped 包的 / 需要编码
+func packumentURL(registryURL, name string) string {
+	return strings.TrimSuffix(registryURL, "/") + "/" + url.PathEscape(name)
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read 实现 io.Reader 接口
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
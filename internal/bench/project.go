@@ -0,0 +1,326 @@
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 依赖来源文件，按优先级排列
+const (
+	shrinkwrapFile  = "npm-shrinkwrap.json"
+	packageLockFile = "package-lock.json"
+	yarnLockFile    = "yarn.lock"
+	pnpmLockFile    = "pnpm-lock.yaml"
+	packageJSONFile = "package.json"
+)
+
+// LoadProject 读取项目的依赖
+// 存在锁文件时使用锁文件中的完整依赖树，否则从 package.json 的
+// dependencies、devDependencies 与 optionalDependencies 开始解析
+func LoadProject(dir string) (*Project, error) {
+	parsers := []struct {
+		file  string
+		parse func(path string) ([]Dependency, error)
+	}{
+		{shrinkwrapFile, parsePackageLock},
+		{packageLockFile, parsePackageLock},
+		{yarnLockFile, parseYarnLock},
+		{pnpmLockFile, parsePnpmLock},
+	}
+	for _, parser := range parsers {
+		path := filepath.Join(dir, parser.file)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		deps, err := parser.parse(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", parser.file, err)
+		}
+		return &Project{Dir: dir, Source: parser.file, Locked: true, Dependencies: sortDependencies(deps)}, nil
+	}
+
+	path := filepath.Join(dir, packageJSONFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", packageJSONFile, err)
+	}
+
+	var manifest struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", packageJSONFile, err)
+	}
+
+	var deps []Dependency
+	for _, group := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.OptionalDependencies} {
+		for name, spec := range group {
+			if dep, ok := registryDependency(name, spec); ok {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return &Project{Dir: dir, Source: packageJSONFile, Dependencies: sortDependencies(deps)}, nil
+}
+
+// registryDependency 将 package.json 中的依赖转换为 registry 依赖
+// 本地路径、git、URL 与 workspace 依赖不经过 registry，返回 false
+func registryDependency(name, spec string) (Dependency, bool) {
+	spec = strings.TrimSpace(spec)
+
+	// npm 别名：npm:<pkg>@<range>
+	if alias, ok := strings.CutPrefix(spec, "npm:"); ok {
+		index := strings.LastIndex(alias, "@")
+		if index <= 0 {
+			return Dependency{Name: alias, Spec: "latest"}, true
+		}
+		return Dependency{Name: alias[:index], Spec: alias[index+1:]}, true
+	}
+
+	for _, prefix := range []string{"file:", "link:", "workspace:", "portal:", "patch:", "git+", "git:", "github:", "http:", "https:"} {
+		if strings.HasPrefix(spec, prefix) {
+			return Dependency{}, false
+		}
+	}
+
+	// GitHub 简写：<user>/<repo>
+	if strings.Contains(spec, "/") {
+		return Dependency{}, false
+	}
+	return Dependency{Name: name, Spec: spec}, true
+}
+
+// parsePackageLock 解析 package-lock.json 或 npm-shrinkwrap.json
+// lockfileVersion 2/3 使用 packages 字段，1 使用嵌套的 dependencies 字段
+func parsePackageLock(path string) ([]Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	type lockDependency struct {
+		Name         string                     `json:"name"` // npm 别名依赖的真实包名
+		Version      string                     `json:"version"`
+		Resolved     string                     `json:"resolved"`
+		Link         bool                       `json:"link"`
+		Bundled      bool                       `json:"bundled"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	var lock struct {
+		Packages     map[string]lockDependency  `json:"packages"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[Dependency]bool)
+	add := func(name string, dep lockDependency) {
+		if name == "" || dep.Link || dep.Bundled || dep.Version == "" {
+			return
+		}
+		// lockfileVersion 2/3 中别名依赖的真实包名在 name 字段，1 中版本写作 npm:<pkg>@<version>
+		if dep.Name != "" {
+			name = dep.Name
+		}
+		ver := dep.Version
+		if alias, ok := strings.CutPrefix(ver, "npm:"); ok {
+			if index := strings.LastIndex(alias, "@"); index > 0 {
+				name, ver = alias[:index], alias[index+1:]
+			}
+		}
+
+		// 非 registry 依赖，例如 git 或本地路径：版本不是 semver，或者 resolved 不是 tarball 地址
+		if _, err := parseVersion(ver); err != nil {
+			return
+		}
+		if dep.Resolved != "" && !strings.HasPrefix(dep.Resolved, "https://") && !strings.HasPrefix(dep.Resolved, "http://") {
+			return
+		}
+		seen[Dependency{Name: name, Spec: ver}] = true
+	}
+
+	if len(lock.Packages) > 0 {
+		for key, dep := range lock.Packages {
+			index := strings.LastIndex(key, "node_modules/")
+			if index < 0 {
+				continue // 项目自身或 workspace
+			}
+			add(key[index+len("node_modules/"):], dep)
+		}
+	} else {
+		var walk func(deps map[string]json.RawMessage) error
+		walk = func(deps map[string]json.RawMessage) error {
+			for name, raw := range deps {
+				var dep lockDependency
+				if err := json.Unmarshal(raw, &dep); err != nil {
+					return err
+				}
+				add(name, dep)
+				if err := walk(dep.Dependencies); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := walk(lock.Dependencies); err != nil {
+			return nil, err
+		}
+	}
+
+	return dependencySet(seen), nil
+}
+
+// parseYarnLock 解析 yarn.lock，支持 yarn classic 与 yarn berry 的格式
+//
+//	"lodash@^4.17.0", lodash@^4.17.21:
+//	  version "4.17.21"
+func parseYarnLock(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	seen := make(map[Dependency]bool)
+	var name string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case !strings.HasPrefix(line, " "):
+			// 条目标题，取第一个描述中的包名
+			name = ""
+			spec := strings.Trim(strings.SplitN(strings.TrimSuffix(line, ":"), ",", 2)[0], `" `)
+			index := strings.Index(strings.TrimPrefix(spec, "@"), "@")
+			if index < 0 || spec == "__metadata" {
+				continue
+			}
+			if strings.HasPrefix(spec, "@") {
+				index++
+			}
+
+			// 只保留来自 registry 的依赖，npm 别名使用真实的包名
+			// 其他协议与 GitHub 简写 <user>/<repo> 不经过 registry
+			name = spec[:index]
+			protocol := spec[index+1:]
+			if alias, ok := strings.CutPrefix(protocol, "npm:"); ok {
+				if i := strings.LastIndex(alias, "@"); i > 0 {
+					name = alias[:i]
+				}
+			} else if strings.ContainsAny(protocol, ":/") {
+				name = ""
+			}
+		case name != "" && strings.HasPrefix(strings.TrimSpace(line), "version"):
+			value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "version"))
+			value = strings.Trim(strings.TrimPrefix(value, ":"), `" `)
+			if _, err := parseVersion(value); err == nil {
+				seen[Dependency{Name: name, Spec: value}] = true
+			}
+			name = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return dependencySet(seen), nil
+}
+
+// parsePnpmLock 解析 pnpm-lock.yaml 中 packages 下的条目，支持以下格式：
+//
+//	/lodash/4.17.21:            (lockfileVersion 5)
+//	/lodash@4.17.21:            (lockfileVersion 6)
+//	'@types/node@20.11.0':      (lockfileVersion 9)
+func parsePnpmLock(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	seen := make(map[Dependency]bool)
+	inPackages := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			inPackages = strings.TrimSpace(line) == "packages:"
+			continue
+		}
+		if !inPackages || strings.HasPrefix(line, "   ") || !strings.HasSuffix(line, ":") {
+			continue
+		}
+
+		key := strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `'"`)
+		key = strings.TrimPrefix(key, "/")
+		if i := strings.IndexByte(key, '('); i >= 0 {
+			key = key[:i] // 去掉 peer 依赖后缀
+		}
+
+		name, ver := splitPnpmKey(key)
+		if _, err := parseVersion(ver); name == "" || err != nil {
+			continue
+		}
+		seen[Dependency{Name: name, Spec: ver}] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return dependencySet(seen), nil
+}
+
+// splitPnpmKey 拆分 pnpm-lock.yaml 中条目的包名与版本
+func splitPnpmKey(key string) (string, string) {
+	if index := strings.LastIndex(key, "@"); index > 0 && validName(key[:index]) {
+		return key[:index], key[index+1:]
+	}
+
+	// lockfileVersion 5 使用 / 分隔版本，peer 依赖以 _ 连接在版本之后
+	index := strings.LastIndex(key, "/")
+	if index <= 0 {
+		return "", ""
+	}
+	ver, _, _ := strings.Cut(key[index+1:], "_")
+	return key[:index], ver
+}
+
+// validName 判断是否为合法的包名格式，scoped 包只能包含一个 /
+func validName(name string) bool {
+	if scope, rest, ok := strings.Cut(name, "/"); ok {
+		return strings.HasPrefix(scope, "@") && rest != "" && !strings.Contains(rest, "/")
+	}
+	return !strings.HasPrefix(name, "@")
+}
+
+// dependencySet 将去重后的依赖转换为列表
+func dependencySet(seen map[Dependency]bool) []Dependency {
+	deps := make([]Dependency, 0, len(seen))
+	for dep := range seen {
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// sortDependencies 按包名与版本排序，保证每次测试的请求顺序一致
+func sortDependencies(deps []Dependency) []Dependency {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Spec < deps[j].Spec
+	})
+	return deps
+}
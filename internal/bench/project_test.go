@@ -0,0 +1,187 @@
+package bench
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadProject(t *testing.T) {
+	// 预期的依赖按包名与版本排序
+	tests := []struct {
+		dir    string
+		source string
+		locked bool
+		want   []Dependency
+	}{
+		{
+			dir:    "npm-v3",
+			source: packageLockFile,
+			locked: true,
+			want: []Dependency{
+				{Name: "@types/node", Spec: "20.11.0"},
+				{Name: "debug", Spec: "4.3.4"},
+				{Name: "lodash", Spec: "4.17.21"},
+				{Name: "ms", Spec: "2.1.2"},
+				{Name: "ms", Spec: "2.1.3"},
+				{Name: "undici-types", Spec: "5.26.5"},
+			},
+		},
+		{
+			dir:    "npm-v1",
+			source: packageLockFile,
+			locked: true,
+			want: []Dependency{
+				{Name: "debug", Spec: "4.3.4"},
+				{Name: "lodash", Spec: "4.17.21"},
+				{Name: "ms", Spec: "2.1.2"},
+				{Name: "ms", Spec: "2.1.3"},
+			},
+		},
+		{
+			// npm-shrinkwrap.json 优先于 package-lock.json
+			dir:    "shrinkwrap",
+			source: shrinkwrapFile,
+			locked: true,
+			want: []Dependency{
+				{Name: "ms", Spec: "2.1.3"},
+			},
+		},
+		{
+			dir:    "yarn-classic",
+			source: yarnLockFile,
+			locked: true,
+			want: []Dependency{
+				{Name: "@babel/code-frame", Spec: "7.22.13"},
+				{Name: "chalk", Spec: "2.4.2"},
+				{Name: "lodash", Spec: "4.17.20"},
+				{Name: "lodash", Spec: "4.17.21"},
+				{Name: "version-range", Spec: "1.1.0"},
+			},
+		},
+		{
+			dir:    "yarn-berry",
+			source: yarnLockFile,
+			locked: true,
+			want: []Dependency{
+				{Name: "@babel/code-frame", Spec: "7.22.13"},
+				{Name: "chalk", Spec: "2.4.2"},
+				{Name: "lodash", Spec: "4.17.21"},
+				{Name: "typescript", Spec: "5.3.3"},
+			},
+		},
+		{
+			dir:    "pnpm-v5",
+			source: pnpmLockFile,
+			locked: true,
+			want:   pnpmDependencies,
+		},
+		{
+			dir:    "pnpm-v6",
+			source: pnpmLockFile,
+			locked: true,
+			want:   pnpmDependencies,
+		},
+		{
+			dir:    "pnpm-v9",
+			source: pnpmLockFile,
+			locked: true,
+			want:   pnpmDependencies,
+		},
+		{
+			dir:    "package-json",
+			source: packageJSONFile,
+			want: []Dependency{
+				{Name: "@scope/real", Spec: "^2.0.0"},
+				{Name: "debug", Spec: "^4.3.4"},
+				{Name: "fsevents", Spec: "2.3.3"},
+				{Name: "lodash", Spec: "^4.17.21"},
+				{Name: "react", Spec: "latest"},
+				{Name: "typescript", Spec: "~5.3.0"},
+				{Name: "whole", Spec: "latest"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			project, err := LoadProject(filepath.Join("testdata", tt.dir))
+			if err != nil {
+				t.Fatalf("LoadProject error: %v", err)
+			}
+			if project.Source != tt.source || project.Locked != tt.locked {
+				t.Errorf("source = %s, locked = %v, want %s, %v", project.Source, project.Locked, tt.source, tt.locked)
+			}
+			if !reflect.DeepEqual(project.Dependencies, tt.want) {
+				t.Errorf("dependencies = %v\nwant %v", project.Dependencies, tt.want)
+			}
+		})
+	}
+}
+
+// pnpmDependencies 三个版本的 pnpm-lock.yaml 示例中的 registry 依赖
+var pnpmDependencies = []Dependency{
+	{Name: "@types/prop-types", Spec: "15.7.11"},
+	{Name: "@types/react", Spec: "18.2.45"},
+	{Name: "loose-envify", Spec: "1.4.0"},
+	{Name: "react", Spec: "18.2.0"},
+	{Name: "react-dom", Spec: "18.2.0"},
+}
+
+func TestLoadProjectMissing(t *testing.T) {
+	if _, err := LoadProject(t.TempDir()); err == nil {
+		t.Error("LoadProject should fail without package.json or a lockfile")
+	}
+}
+
+func TestRegistryDependency(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want Dependency
+		ok   bool
+	}{
+		{name: "lodash", spec: "^4.17.21", want: Dependency{Name: "lodash", Spec: "^4.17.21"}, ok: true},
+		{name: "lodash", spec: " 4.17.21 ", want: Dependency{Name: "lodash", Spec: "4.17.21"}, ok: true},
+		{name: "react", spec: "next", want: Dependency{Name: "react", Spec: "next"}, ok: true},
+		{name: "my-lodash", spec: "npm:lodash@^4", want: Dependency{Name: "lodash", Spec: "^4"}, ok: true},
+		{name: "types", spec: "npm:@types/node@20", want: Dependency{Name: "@types/node", Spec: "20"}, ok: true},
+		{name: "whole", spec: "npm:@scope/whole", want: Dependency{Name: "@scope/whole", Spec: "latest"}, ok: true},
+		{name: "local", spec: "file:../local"},
+		{name: "linked", spec: "link:../linked"},
+		{name: "shared", spec: "workspace:^"},
+		{name: "portal", spec: "portal:../portal"},
+		{name: "patched", spec: "patch:lodash@npm%3A4.17.21#./fix.patch"},
+		{name: "repo", spec: "git+ssh://git@github.com/user/repo.git"},
+		{name: "repo", spec: "git://github.com/user/repo.git"},
+		{name: "repo", spec: "github:user/repo"},
+		{name: "repo", spec: "user/repo"},
+		{name: "tarball", spec: "https://example.com/pkg.tgz"},
+		{name: "tarball", spec: "http://example.com/pkg.tgz"},
+	}
+	for _, tt := range tests {
+		got, ok := registryDependency(tt.name, tt.spec)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("registryDependency(%q, %q) = %v, %v, want %v, %v", tt.name, tt.spec, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSplitPnpmKey(t *testing.T) {
+	tests := []struct {
+		key, name, version string
+	}{
+		{key: "lodash/4.17.21", name: "lodash", version: "4.17.21"},
+		{key: "@types/node/20.11.0", name: "@types/node", version: "20.11.0"},
+		{key: "react-dom/18.2.0_react@18.2.0", name: "react-dom", version: "18.2.0"},
+		{key: "@scope/pkg/1.0.0_@types+react@18.2.45", name: "@scope/pkg", version: "1.0.0"},
+		{key: "lodash@4.17.21", name: "lodash", version: "4.17.21"},
+		{key: "@types/node@20.11.0", name: "@types/node", version: "20.11.0"},
+		{key: "lodash", name: "", version: ""},
+	}
+	for _, tt := range tests {
+		name, version := splitPnpmKey(tt.key)
+		if name != tt.name || version != tt.version {
+			t.Errorf("splitPnpmKey(%q) = %q, %q, want %q, %q", tt.key, name, version, tt.name, tt.version)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package bench
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version 表示一个 semver 版本号，忽略构建元数据
+type version struct {
+	major, minor, patch int
+	pre                 []string // 预发布标识，为空表示正式版本
+}
+
+// parseVersion 解析完整的版本号，允许 v 或 = 前缀
+func parseVersion(s string) (version, error) {
+	s = strings.TrimLeft(strings.TrimSpace(s), "v=")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	var v version
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return version{}, fmt.Errorf("invalid version %q", s)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[i] = n
+	}
+	v.major, v.minor, v.patch = numbers[0], numbers[1], numbers[2]
+	return v, nil
+}
+
+// compare 比较两个版本，返回 -1、0 或 1
+func (v version) compare(o version) int {
+	if c := compareInt(v.major, o.major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.minor, o.minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.patch, o.patch); c != 0 {
+		return c
+	}
+
+	// 正式版本大于预发布版本
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		a, aErr := strconv.Atoi(v.pre[i])
+		b, bErr := strconv.Atoi(o.pre[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(a, b); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1 // 数字标识小于字母标识
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(v.pre[i], o.pre[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(v.pre), len(o.pre))
+}
+
+// sameTuple 判断主、次、修订版本号是否相同
+func (v version) sameTuple(o version) bool {
+	return v.major == o.major && v.minor == o.minor && v.patch == o.patch
+}
+
+// compareInt 比较两个整数
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparator 表示单个比较条件，例如 >=1.2.3
+type comparator struct {
+	op string
+	v  version
+}
+
+// match 判断版本是否满足比较条件
+func (c comparator) match(v version) bool {
+	r := v.compare(c.v)
+	switch c.op {
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	default:
+		return r == 0
+	}
+}
+
+// versionRange 表示 npm 的版本范围，外层为 || 连接的条件组，组内条件需要同时满足
+type versionRange [][]comparator
+
+// parseRange 解析 npm 的版本范围
+// 支持精确版本、通配符（x、*）、^、~、比较运算符、连字符范围与 ||
+func parseRange(s string) (versionRange, error) {
+	var r versionRange
+	for _, part := range strings.Split(s, "||") {
+		set, err := parseComparatorSet(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, set)
+	}
+	return r, nil
+}
+
+// match 判断版本是否满足范围
+// 与 npm 一致，预发布版本只匹配包含相同主、次、修订版本号预发布条件的范围
+func (r versionRange) match(v version) bool {
+	for _, set := range r {
+		if matchSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSet 判断版本是否满足一组条件
+func matchSet(set []comparator, v version) bool {
+	for _, c := range set {
+		if !c.match(v) {
+			return false
+		}
+	}
+	if len(v.pre) == 0 {
+		return true
+	}
+	for _, c := range set {
+		if len(c.v.pre) > 0 && c.v.sameTuple(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparatorSet 解析空格分隔的一组条件
+func parseComparatorSet(s string) ([]comparator, error) {
+	// 连字符范围：1.2.3 - 2.3.4
+	if from, to, ok := strings.Cut(s, " - "); ok {
+		lower, err := expand(">=", strings.TrimSpace(from))
+		if err != nil {
+			return nil, err
+		}
+		upper, err := expand("<=", strings.TrimSpace(to))
+		if err != nil {
+			return nil, err
+		}
+		return append(lower, upper...), nil
+	}
+
+	var (
+		set    []comparator
+		tokens = strings.Fields(s)
+	)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		// 运算符与版本号之间允许有空格，例如 ">= 1.2.3"
+		if strings.Trim(token, "<>=~^") == "" && i+1 < len(tokens) {
+			i++
+			token += tokens[i]
+		}
+
+		op, partial := splitOperator(token)
+		comparators, err := expand(op, partial)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// splitOperator 拆分条件中的运算符与版本号
+func splitOperator(token string) (string, string) {
+	for _, op := range []string{">=", "<=", "~>", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(token, op) {
+			return op, token[len(op):]
+		}
+	}
+	return "", token
+}
+
+// expand 将带运算符的部分版本号展开为比较条件
+func expand(op, partial string) ([]comparator, error) {
+	major, minor, patch, pre, n, err := parsePartial(partial)
+	if err != nil {
+		return nil, err
+	}
+	v := version{major: major, minor: minor, patch: patch, pre: pre}
+
+	// 任意版本
+	if n == 0 {
+		if op == "<" || op == ">" {
+			return []comparator{{op: "<", v: version{}}}, nil // 不可能满足
+		}
+		return nil, nil
+	}
+
+	// next 返回部分版本号之后的第一个版本，例如 1.2 之后为 1.3.0
+	next := func(level int) version {
+		switch level {
+		case 1:
+			return version{major: major + 1}
+		case 2:
+			return version{major: major, minor: minor + 1}
+		default:
+			return version{major: major, minor: minor, patch: patch + 1}
+		}
+	}
+
+	switch op {
+	case "^":
+		// 不修改最左侧的非零版本号
+		switch {
+		case major > 0 || n == 1:
+			return bounds(v, next(1)), nil
+		case minor > 0 || n == 2:
+			return bounds(v, next(2)), nil
+		default:
+			return bounds(v, next(3)), nil
+		}
+	case "~", "~>":
+		if n == 1 {
+			return bounds(v, next(1)), nil
+		}
+		return bounds(v, next(2)), nil
+	case ">":
+		if n < 3 {
+			return []comparator{{op: ">=", v: next(n)}}, nil
+		}
+		return []comparator{{op: ">", v: v}}, nil
+	case ">=":
+		return []comparator{{op: ">=", v: v}}, nil
+	case "<":
+		return []comparator{{op: "<", v: v}}, nil
+	case "<=":
+		if n < 3 {
+			return []comparator{{op: "<", v: next(n)}}, nil
+		}
+		return []comparator{{op: "<=", v: v}}, nil
+	default:
+		if n < 3 {
+			return bounds(v, next(n)), nil
+		}
+		return []comparator{{op: "=", v: v}}, nil
+	}
+}
+
+// bounds 返回 [lower, upper) 范围的比较条件
+func bounds(lower, upper version) []comparator {
+	return []comparator{{op: ">=", v: lower}, {op: "<", v: upper}}
+}
+
+// parsePartial 解析可能省略或使用通配符的版本号，n 为给出的版本号段数
+func parsePartial(s string) (major, minor, patch int, pre []string, n int, err error) {
+	s = strings.TrimLeft(strings.TrimSpace(s), "v=")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	if s == "" {
+		return 0, 0, 0, nil, 0, nil
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return 0, 0, 0, nil, 0, fmt.Errorf("invalid version %q", s)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		numbers[i], err = strconv.Atoi(part)
+		if err != nil || numbers[i] < 0 {
+			return 0, 0, 0, nil, 0, fmt.Errorf("invalid version %q", s)
+		}
+		n++
+	}
+
+	// 预发布标识只对完整版本号有意义
+	if n < 3 {
+		pre = nil
+	}
+	return numbers[0], numbers[1], numbers[2], pre, n, nil
+}
+
+// maxSatisfying 返回满足范围的最高版本，没有满足的版本时返回空字符串
+func maxSatisfying(versions []string, r versionRange) string {
+	var (
+		best    string
+		bestVer version
+	)
+	for _, s := range versions {
+		v, err := parseVersion(s)
+		if err != nil || !r.match(v) {
+			continue
+		}
+		if best == "" || v.compare(bestVer) > 0 {
+			best, bestVer = s, v
+		}
+	}
+	return best
+}
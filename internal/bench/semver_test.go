@@ -0,0 +1,181 @@
+package bench
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    version
+		wantErr bool
+	}{
+		{input: "1.2.3", want: version{major: 1, minor: 2, patch: 3}},
+		{input: "v1.2.3", want: version{major: 1, minor: 2, patch: 3}},
+		{input: "=1.2.3", want: version{major: 1, minor: 2, patch: 3}},
+		{input: " 1.2.3 ", want: version{major: 1, minor: 2, patch: 3}},
+		{input: "1.2.3-beta.1", want: version{major: 1, minor: 2, patch: 3, pre: []string{"beta", "1"}}},
+		{input: "1.2.3+build.5", want: version{major: 1, minor: 2, patch: 3}},
+		{input: "1.2.3-rc.1+build.5", want: version{major: 1, minor: 2, patch: 3, pre: []string{"rc", "1"}}},
+		{input: "1.2", wantErr: true},
+		{input: "1.2.3.4", wantErr: true},
+		{input: "1.x.3", wantErr: true},
+		{input: "latest", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseVersion(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseVersion(%q) = %+v, want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseVersion(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got.compare(tt.want) != 0 || len(got.pre) != len(tt.want.pre) {
+			t.Errorf("parseVersion(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// 按 semver 规范从小到大排列
+	ordered := []string{
+		"0.9.9",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"1.10.0",
+		"2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := parseVersion(ordered[i])
+			b, _ := parseVersion(ordered[j])
+			want := compareInt(i, j)
+			if got := a.compare(b); got != want {
+				t.Errorf("compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestRangeMatch(t *testing.T) {
+	tests := []struct {
+		rng   string
+		match []string
+		miss  []string
+	}{
+		{rng: "", match: []string{"0.0.0", "1.2.3"}, miss: []string{"1.2.3-beta"}},
+		{rng: "*", match: []string{"0.0.1", "99.0.0"}, miss: []string{"1.0.0-rc.1"}},
+		{rng: "x", match: []string{"1.2.3"}},
+		{rng: "1.2.3", match: []string{"1.2.3"}, miss: []string{"1.2.4", "1.2.3-beta"}},
+		{rng: "=1.2.3", match: []string{"1.2.3"}, miss: []string{"1.2.2"}},
+		{rng: "v1.2.3", match: []string{"1.2.3"}, miss: []string{"1.2.4"}},
+		{rng: "1", match: []string{"1.0.0", "1.9.9"}, miss: []string{"0.9.9", "2.0.0"}},
+		{rng: "1.x", match: []string{"1.0.0", "1.9.9"}, miss: []string{"2.0.0"}},
+		{rng: "1.2", match: []string{"1.2.0", "1.2.9"}, miss: []string{"1.3.0", "1.1.9"}},
+		{rng: "1.2.x", match: []string{"1.2.0", "1.2.9"}, miss: []string{"1.3.0"}},
+		{rng: "1.2.*", match: []string{"1.2.5"}, miss: []string{"1.3.0"}},
+
+		// ^ 不修改最左侧的非零版本号
+		{rng: "^1.2.3", match: []string{"1.2.3", "1.9.0"}, miss: []string{"1.2.2", "2.0.0", "2.0.0-alpha"}},
+		{rng: "^0.2.3", match: []string{"0.2.3", "0.2.9"}, miss: []string{"0.3.0", "0.2.2"}},
+		{rng: "^0.0.3", match: []string{"0.0.3"}, miss: []string{"0.0.4", "0.0.2"}},
+		{rng: "^1.2", match: []string{"1.2.0", "1.9.9"}, miss: []string{"2.0.0", "1.1.0"}},
+		{rng: "^0.0", match: []string{"0.0.0", "0.0.9"}, miss: []string{"0.1.0"}},
+		{rng: "^0.x", match: []string{"0.0.0", "0.9.9"}, miss: []string{"1.0.0"}},
+		{rng: "^1.x", match: []string{"1.0.0", "1.9.9"}, miss: []string{"2.0.0"}},
+		{rng: "^1.2.3-beta.2", match: []string{"1.2.3-beta.2", "1.2.3-beta.4", "1.2.3", "1.3.0"}, miss: []string{"1.2.3-beta.1", "1.3.0-beta", "2.0.0"}},
+
+		// ~ 允许修订版本号变化
+		{rng: "~1.2.3", match: []string{"1.2.3", "1.2.9"}, miss: []string{"1.3.0", "1.2.2"}},
+		{rng: "~1.2", match: []string{"1.2.0", "1.2.9"}, miss: []string{"1.3.0"}},
+		{rng: "~1", match: []string{"1.0.0", "1.9.9"}, miss: []string{"2.0.0"}},
+		{rng: "~0.2.3", match: []string{"0.2.3", "0.2.9"}, miss: []string{"0.3.0"}},
+		{rng: "~>1.2.3", match: []string{"1.2.5"}, miss: []string{"1.3.0"}},
+		{rng: "~1.2.3-beta.2", match: []string{"1.2.3-beta.2", "1.2.3", "1.2.4"}, miss: []string{"1.2.3-beta.1", "1.2.4-beta.1", "1.3.0"}},
+
+		// 比较运算符
+		{rng: ">1.2.3", match: []string{"1.2.4", "2.0.0"}, miss: []string{"1.2.3", "1.2.4-beta"}},
+		{rng: ">1.2", match: []string{"1.3.0"}, miss: []string{"1.2.9"}},
+		{rng: ">=1.2.3", match: []string{"1.2.3", "5.0.0"}, miss: []string{"1.2.2"}},
+		{rng: "<1.2.3", match: []string{"1.2.2", "0.0.1"}, miss: []string{"1.2.3", "1.2.3-beta"}},
+		{rng: "<1.2", match: []string{"1.1.9"}, miss: []string{"1.2.0"}},
+		{rng: "<=1.2.3", match: []string{"1.2.3"}, miss: []string{"1.2.4"}},
+		{rng: "<=1.2", match: []string{"1.2.9"}, miss: []string{"1.3.0"}},
+		{rng: ">=1.2.3 <2.0.0", match: []string{"1.2.3", "1.9.9"}, miss: []string{"2.0.0", "1.2.2"}},
+		{rng: ">= 1.2.3 < 2", match: []string{"1.5.0"}, miss: []string{"2.0.0"}},
+		{rng: ">=1.0.0-rc.1 <1.0.0", match: []string{"1.0.0-rc.2"}, miss: []string{"1.0.0", "0.9.0"}},
+		{rng: ">*", miss: []string{"1.0.0"}},
+		{rng: "<*", miss: []string{"1.0.0"}},
+
+		// 连字符范围
+		{rng: "1.2.3 - 2.3.4", match: []string{"1.2.3", "2.3.4"}, miss: []string{"1.2.2", "2.3.5"}},
+		{rng: "1.2 - 2.3", match: []string{"1.2.0", "2.3.9"}, miss: []string{"1.1.9", "2.4.0"}},
+		{rng: "1.2.3 - 2", match: []string{"2.9.9"}, miss: []string{"3.0.0"}},
+
+		// ||
+		{rng: "^1.0.0 || ^2.0.0", match: []string{"1.5.0", "2.5.0"}, miss: []string{"3.0.0", "0.9.0"}},
+		{rng: "1.2.7 || >=1.2.9 <2.0.0", match: []string{"1.2.7", "1.2.9", "1.4.6"}, miss: []string{"1.2.8", "2.0.0"}},
+		{rng: "<1.0.0 || >=3", match: []string{"0.1.0", "3.0.0"}, miss: []string{"2.0.0"}},
+	}
+	for _, tt := range tests {
+		r, err := parseRange(tt.rng)
+		if err != nil {
+			t.Errorf("parseRange(%q) error: %v", tt.rng, err)
+			continue
+		}
+		for _, s := range tt.match {
+			if v, _ := parseVersion(s); !r.match(v) {
+				t.Errorf("range %q should match %s", tt.rng, s)
+			}
+		}
+		for _, s := range tt.miss {
+			if v, _ := parseVersion(s); r.match(v) {
+				t.Errorf("range %q should not match %s", tt.rng, s)
+			}
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	for _, rng := range []string{"latest", "next", "1.2.3.4", "^a.b", ">=1.2.3 <foo", "1.2.3 - bar"} {
+		if _, err := parseRange(rng); err == nil {
+			t.Errorf("parseRange(%q) should fail", rng)
+		}
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "1.2.5", "1.10.0", "2.0.0-beta.1", "2.0.0", "2.1.0-rc.1", "not-a-version"}
+	tests := []struct {
+		rng  string
+		want string
+	}{
+		{rng: "^1.0.0", want: "1.10.0"},
+		{rng: "~1.2.0", want: "1.2.5"},
+		{rng: "*", want: "2.0.0"},
+		{rng: ">=2.0.0-beta.1 <2.0.0", want: "2.0.0-beta.1"},
+		{rng: "^2.1.0-rc.0", want: "2.1.0-rc.1"},
+		{rng: "<1.0.0", want: ""},
+		{rng: "^3", want: ""},
+	}
+	for _, tt := range tests {
+		r, err := parseRange(tt.rng)
+		if err != nil {
+			t.Fatalf("parseRange(%q) error: %v", tt.rng, err)
+		}
+		if got := maxSatisfying(versions, r); got != tt.want {
+			t.Errorf("maxSatisfying(%q) = %q, want %q", tt.rng, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package bench
+
+import (
+	"net/http"
+	"time"
+)
+
+// Target 表示一个待测试的 registry
+type Target struct {
+	Name    string      // registry 名称
+	URL     string      // registry 地址
+	Headers http.Header // 请求头，例如私有 registry 的认证信息
+}
+
+// Dependency 表示项目的一个依赖
+type Dependency struct {
+	Name string // 包名
+	Spec string // 版本范围，来自锁文件时为精确版本
+}
+
+// Project 表示一个待模拟安装的项目
+type Project struct {
+	Dir          string       // 项目目录
+	Source       string       // 依赖来源文件，例如 package.json 或 package-lock.json
+	Locked       bool         // 依赖来自锁文件，已包含完整的依赖树
+	Dependencies []Dependency // 直接依赖，锁文件中为全部依赖
+}
+
+// Result 表示单个 registry 的模拟安装结果
+type Result struct {
+	Name      string        // registry 名称
+	URL       string        // registry 地址
+	Duration  time.Duration // 解析完整依赖树的总耗时
+	Packages  int           // 解析到的包版本数量
+	Requests  int           // 发出的元数据请求数量
+	Bytes     int64         // 下载的元数据大小
+	Failures  int           // 失败的请求或无法解析的依赖数量
+	Errors    []string      // 部分失败原因，最多 maxErrors 条
+	Cancelled bool          // 测试被取消
+}
+
+// Passed 判断是否在没有失败的情况下解析了完整的依赖树
+func (r *Result) Passed() bool {
+	return !r.Cancelled && r.Failures == 0
+}
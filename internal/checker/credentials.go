@@ -0,0 +1,228 @@
+package checker
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Credential 表示访问 registry 的凭据
+// 凭据只用于生成请求头，String 不会输出任何敏感信息
+type Credential struct {
+	Token    string // Bearer token（_authToken）
+	Username string // 用户名（Basic 认证）
+	Password string // 密码（Basic 认证）
+	Auth     string // base64 编码的 username:password（_auth）
+}
+
+// Header 返回 Authorization 请求头的值
+func (c Credential) Header() string {
+	switch {
+	case c.Token != "":
+		return "Bearer " + c.Token
+	case c.Auth != "":
+		return "Basic " + c.Auth
+	default:
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+	}
+}
+
+// String 实现 fmt.Stringer 接口，避免凭据被意外输出
+func (c Credential) String() string {
+	return "[redacted]"
+}
+
+// GoString 实现 fmt.GoStringer 接口，避免凭据被 %#v 输出
+func (c Credential) GoString() string {
+	return "checker.Credential{[redacted]}"
+}
+
+// valid 判断凭据是否完整
+func (c Credential) valid() bool {
+	return c.Token != "" || c.Auth != "" || (c.Username != "" && c.Password != "")
+}
+
+// credentialEntry 表示一个 registry 前缀对应的凭据
+type credentialEntry struct {
+	prefix     string // 去掉协议的 registry 地址，例如 //npm.example.com/repository/npm/
+	credential Credential
+}
+
+// CredentialSet 表示从包管理器配置中读取的所有凭据
+type CredentialSet struct {
+	entries []credentialEntry
+}
+
+// LoadCredentials 从用户 .npmrc 与 .bunfig.toml 中读取 registry 凭据
+// 读取失败的配置文件会被忽略
+func LoadCredentials() *CredentialSet {
+	set := &CredentialSet{}
+
+	if values, err := GetNPMConfigValues(); err == nil {
+		set.entries = append(set.entries, npmCredentials(values)...)
+	}
+
+	if path, err := getConfigPath(registryConfigs["bun"].ConfigFile); err == nil {
+		if data, err := readConfigFile(path); err == nil && data != nil {
+			set.entries = append(set.entries, bunCredentials(data)...)
+		}
+	}
+
+	return set
+}
+
+// Lookup 查找 registry 对应的凭据
+// 与 npm 一致，按路径前缀匹配，路径最长的优先；.npmrc 中的凭据优先于 .bunfig.toml
+func (s *CredentialSet) Lookup(registryURL string) (Credential, bool) {
+	target := nerfDart(registryURL)
+	if target == "" {
+		return Credential{}, false
+	}
+
+	var best *credentialEntry
+	for i, entry := range s.entries {
+		if !strings.HasPrefix(target, entry.prefix) {
+			continue
+		}
+		if best == nil || len(entry.prefix) > len(best.prefix) {
+			best = &s.entries[i]
+		}
+	}
+	if best == nil {
+		return Credential{}, false
+	}
+	return best.credential, true
+}
+
+// nerfDart 将 registry 地址转换为 npm 凭据使用的格式：//host[:port]/path/
+func nerfDart(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	path := u.Path
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return "//" + u.Host + path
+}
+
+// npmCredentials 从 .npmrc 配置项中提取凭据
+// 支持 //host/path/:_authToken、//host/path/:_auth 以及 username 与 _password（base64 编码）
+func npmCredentials(values map[string]string) []credentialEntry {
+	credentials := make(map[string]*Credential)
+	get := func(prefix string) *Credential {
+		if c, ok := credentials[prefix]; ok {
+			return c
+		}
+		c := &Credential{}
+		credentials[prefix] = c
+		return c
+	}
+
+	for key, value := range values {
+		if !strings.HasPrefix(key, "//") || value == "" {
+			continue
+		}
+		prefix, field, ok := strings.Cut(key, ":_")
+		if !ok {
+			// username 没有下划线前缀
+			if prefix, ok = strings.CutSuffix(key, ":username"); ok {
+				get(normalizePrefix(prefix)).Username = value
+			}
+			continue
+		}
+
+		switch field {
+		case "authToken":
+			get(normalizePrefix(prefix)).Token = value
+		case "auth":
+			get(normalizePrefix(prefix)).Auth = value
+		case "password":
+			password, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				continue
+			}
+			get(normalizePrefix(prefix)).Password = string(password)
+		}
+	}
+
+	var entries []credentialEntry
+	for prefix, c := range credentials {
+		if c.valid() {
+			entries = append(entries, credentialEntry{prefix: prefix, credential: *c})
+		}
+	}
+	return entries
+}
+
+// normalizePrefix 确保凭据前缀以 / 结尾
+func normalizePrefix(prefix string) string {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// bunCredentials 从 .bunfig.toml 中提取凭据
+// 支持 [install] 与 [install.scopes] 中对象形式的 registry 以及 URL 中的用户名密码
+func bunCredentials(data []byte) []credentialEntry {
+	var cfg struct {
+		Install struct {
+			Registry any            `toml:"registry"`
+			Scopes   map[string]any `toml:"scopes"`
+		} `toml:"install"`
+	}
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+
+	var entries []credentialEntry
+	add := func(value any) {
+		if entry, ok := bunCredential(value); ok {
+			entries = append(entries, entry)
+		}
+	}
+	add(cfg.Install.Registry)
+	for _, scope := range cfg.Install.Scopes {
+		add(scope)
+	}
+	return entries
+}
+
+// bunCredential 解析单个 bun registry 配置中的凭据
+func bunCredential(value any) (credentialEntry, bool) {
+	var rawURL string
+	var c Credential
+	switch v := value.(type) {
+	case string:
+		rawURL = v
+	case map[string]any:
+		rawURL, _ = v["url"].(string)
+		c.Token, _ = v["token"].(string)
+		c.Username, _ = v["username"].(string)
+		c.Password, _ = v["password"].(string)
+	default:
+		return credentialEntry{}, false
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return credentialEntry{}, false
+	}
+
+	// URL 中的用户名密码
+	if u.User != nil && c.Username == "" {
+		c.Username = u.User.Username()
+		c.Password, _ = u.User.Password()
+	}
+	u.User = nil
+
+	prefix := nerfDart(u.String())
+	if prefix == "" || !c.valid() {
+		return credentialEntry{}, false
+	}
+	return credentialEntry{prefix: prefix, credential: c}, true
+}
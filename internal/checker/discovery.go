@@ -0,0 +1,179 @@
+package checker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// ConfigScope 配置文件的层级
+type ConfigScope string
+
+const (
+	// ScopeProject 当前项目中的配置文件
+	ScopeProject ConfigScope = "project"
+	// ScopeUser 用户目录下的配置文件
+	ScopeUser ConfigScope = "user"
+	// ScopeGlobal 全局配置文件，例如 npm 安装目录下的 etc/npmrc
+	ScopeGlobal ConfigScope = "global"
+)
+
+// ConfigFile 包管理器会读取的一个配置文件
+type ConfigFile struct {
+	Manager string      // 包管理器名称
+	Scope   ConfigScope // 配置文件的层级
+	Path    string      // 配置文件的绝对路径
+	Exists  bool        // 配置文件是否存在
+}
+
+// DiscoverConfigFiles 列出包管理器会读取的所有配置文件，不要求包管理器已安装
+// 按项目、用户、全局的顺序排列，与包管理器读取配置的优先级一致，重复的路径只保留一次
+func DiscoverConfigFiles(name string) []ConfigFile {
+	home, _ := os.UserHomeDir()
+	project := findProjectRoot()
+
+	var candidates []ConfigFile
+	add := func(scope ConfigScope, paths ...string) {
+		for _, path := range paths {
+			if path != "" {
+				candidates = append(candidates, ConfigFile{Manager: name, Scope: scope, Path: path})
+			}
+		}
+	}
+	inHome := func(rel string) string {
+		if home == "" {
+			return ""
+		}
+		return filepath.Join(home, rel)
+	}
+
+	switch name {
+	case "npm":
+		add(ScopeProject, inProject(project, ".npmrc"))
+		add(ScopeUser, inHome(".npmrc"), npmEnv("userconfig"))
+		add(ScopeGlobal, npmGlobalConfig())
+	case "pnpm":
+		// pnpm 同时读取 npm 的 .npmrc 与自己的全局 rc
+		add(ScopeProject, inProject(project, ".npmrc"))
+		add(ScopeUser, inHome(".npmrc"), npmEnv("userconfig"))
+		add(ScopeGlobal, pnpmGlobalConfig(home))
+	case "yarn":
+		// yarn classic 使用 .yarnrc，yarn berry 使用 .yarnrc.yml
+		add(ScopeProject, inProject(project, ".yarnrc"), inProject(project, ".yarnrc.yml"))
+		add(ScopeUser, inHome(".yarnrc"), inHome(".yarnrc.yml"))
+	case "bun":
+		add(ScopeProject, inProject(project, "bunfig.toml"))
+		add(ScopeUser, inHome(registryConfigs["bun"].ConfigFile))
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			add(ScopeUser, filepath.Join(xdg, ".bunfig.toml"))
+		}
+	}
+
+	// 项目目录就是用户目录时，项目级配置与用户级配置是同一个文件
+	seen := make(map[string]bool)
+	var files []ConfigFile
+	for _, file := range candidates {
+		path, err := filepath.Abs(file.Path)
+		if err != nil {
+			path = file.Path
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		file.Path = path
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			file.Exists = true
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// findProjectRoot 与 npm 一致，从当前目录向上查找包含 package.json 或 node_modules 的目录
+// 找不到时使用当前目录
+func findProjectRoot() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		for _, marker := range []string{"package.json", "node_modules"} {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return cwd
+		}
+	}
+}
+
+// inProject 返回项目目录下的文件路径，没有项目目录时返回空字符串
+func inProject(project, name string) string {
+	if project == "" {
+		return ""
+	}
+	return filepath.Join(project, name)
+}
+
+// npmEnv 读取 npm 的环境变量配置，例如 NPM_CONFIG_USERCONFIG
+func npmEnv(key string) string {
+	if value := os.Getenv("npm_config_" + key); value != "" {
+		return value
+	}
+	return os.Getenv("NPM_CONFIG_" + key)
+}
+
+// npmGlobalConfig 返回 npm 的全局配置文件路径，即 {prefix}/etc/npmrc
+// prefix 未配置时与 npm 一致，由 node 的安装位置推导
+func npmGlobalConfig() string {
+	if path := npmEnv("globalconfig"); path != "" {
+		return path
+	}
+
+	prefix := npmEnv("prefix")
+	if prefix == "" {
+		node, err := exec.LookPath("node")
+		if err != nil {
+			return ""
+		}
+		// Windows 上 node.exe 位于 prefix 目录中，其他系统位于 prefix/bin 中
+		prefix = filepath.Dir(node)
+		if runtime.GOOS != "windows" {
+			prefix = filepath.Dir(prefix)
+		}
+	}
+	return filepath.Join(prefix, "etc", "npmrc")
+}
+
+// pnpmGlobalConfig 返回 pnpm 的全局配置文件路径
+func pnpmGlobalConfig(home string) string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "pnpm", "rc")
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			return filepath.Join(local, "pnpm", "config", "rc")
+		}
+		return ""
+	case "darwin":
+		if home == "" {
+			return ""
+		}
+		return filepath.Join(home, "Library", "Preferences", "pnpm", "rc")
+	default:
+		if home == "" {
+			return ""
+		}
+		return filepath.Join(home, ".config", "pnpm", "rc")
+	}
+}
+
+// SupportedManagers 返回 nrmgo 支持的所有包管理器名称
+func SupportedManagers() []string {
+	return append([]string(nil), managers...)
+}
@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envPattern 匹配 npm 配置值中的 ${VAR}
+var envPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// parseNPMStyleValues 解析 npm 风格配置文件中的所有配置项
+// 值中的 ${VAR} 会按 npm 的规则替换为环境变量
+func parseNPMStyleValues(data []byte) map[string]string {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), "\"'")
+		values[key] = envPattern.ReplaceAllStringFunc(value, func(match string) string {
+			return os.Getenv(match[2 : len(match)-1])
+		})
+	}
+
+	return values
+}
+
+// GetNPMConfigValues 读取用户 .npmrc 中的所有配置项，文件不存在时返回空映射
+func GetNPMConfigValues() (map[string]string, error) {
+	configPath, err := getConfigPath(registryConfigs["npm"].ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, NewConfigError("npm", "read", configPath, err)
+	}
+	return parseNPMStyleValues(data), nil
+}
+
+// ResolveNPMConfig 按 npm 的优先级读取配置项的生效值：
+// npm_config_* 环境变量优先，其次依次为项目、用户与全局 .npmrc，无法读取的文件被跳过
+// 没有设置的配置项不包含在结果中
+func ResolveNPMConfig(keys ...string) map[string]string {
+	values := make(map[string]string)
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	// 与 npm 一致，环境变量名不区分大小写，其中的 _ 对应配置项中的 -
+	for _, env := range os.Environ() {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || value == "" || !strings.HasPrefix(strings.ToLower(name), "npm_config_") {
+			continue
+		}
+		key := strings.ReplaceAll(strings.ToLower(name[len("npm_config_"):]), "_", "-")
+		if wanted[key] {
+			values[key] = value
+		}
+	}
+
+	for _, file := range DiscoverConfigFiles("npm") {
+		if !file.Exists {
+			continue
+		}
+		data, err := readConfigFile(file.Path)
+		if err != nil {
+			continue
+		}
+		for key, value := range parseNPMStyleValues(data) {
+			if _, ok := values[key]; !ok && wanted[key] && value != "" {
+				values[key] = value
+			}
+		}
+	}
+	return values
+}
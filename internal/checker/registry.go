@@ -242,6 +242,99 @@ func writeBunConfig(data []byte, registry string) []byte {
 	return []byte(strings.Join(newLines, "\n") + "\n")
 }
 
+// cleanNPMStyleConfig 删除 nrmgo 写入 .npmrc 的配置项
+// registry 总是由 nrmgo 写入；always-auth 与 strict-ssl 只删除 nrmgo 写入的默认值，删除后 npm 的行为不变
+func cleanNPMStyleConfig(data []byte) ([]byte, []string) {
+	defaultConfig := DefaultNPMConfig()
+	injected := map[string]string{
+		"always-auth": fmt.Sprintf("%v", defaultConfig.AlwaysAuth),
+		"strict-ssl":  fmt.Sprintf("%v", defaultConfig.StrictSSL),
+	}
+
+	var lines, removed []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case !ok || strings.HasPrefix(key, "#") || strings.HasPrefix(key, ";"):
+		case key == "registry":
+			removed = append(removed, key)
+			continue
+		case injected[key] == value && value != "":
+			removed = append(removed, key)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return joinConfigLines(lines), removed
+}
+
+// cleanYarnConfig 删除 nrmgo 写入 .yarnrc 的 registry
+func cleanYarnConfig(data []byte) ([]byte, []string) {
+	var lines, removed []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "registry ") {
+			removed = append(removed, "registry")
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return joinConfigLines(lines), removed
+}
+
+// cleanBunConfig 删除 nrmgo 写入 bunfig.toml 的 [install] registry，[install] 因此为空时一并删除
+func cleanBunConfig(data []byte) ([]byte, []string) {
+	var lines, removed []string
+	inInstallSection := false
+	start := -1  // 当前 [install] 标题所在的行
+	header := -1 // 删除了 registry 的 [install] 标题所在的行
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "[") {
+			dropEmptySection(&lines, header)
+			header = -1
+			inInstallSection = line == "[install]"
+			start = len(lines)
+		} else if inInstallSection && strings.HasPrefix(strings.TrimSpace(line), "registry") {
+			removed = append(removed, "install.registry")
+			header = start
+			continue
+		}
+		lines = append(lines, line)
+	}
+	dropEmptySection(&lines, header)
+	return joinConfigLines(lines), removed
+}
+
+// dropEmptySection 删除从 header 行开始、只剩空行的段落，header 为 -1 时不做处理
+func dropEmptySection(lines *[]string, header int) {
+	if header < 0 {
+		return
+	}
+	for _, line := range (*lines)[header+1:] {
+		if strings.TrimSpace(line) != "" {
+			return
+		}
+	}
+	*lines = (*lines)[:header]
+}
+
+// joinConfigLines 拼接配置文件的行，去掉末尾的空行，没有任何内容时返回 nil
+func joinConfigLines(lines []string) []byte {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
 // registryConfigs 定义支持的包管理器配置
 var registryConfigs = map[string]RegistryConfig{
 	"npm": {
@@ -250,6 +343,7 @@ var registryConfigs = map[string]RegistryConfig{
 		DefaultValue: "https://registry.npmjs.org/",
 		Parser:       parseNPMStyleConfig,
 		Writer:       writeNPMStyleConfig,
+		Cleaner:      cleanNPMStyleConfig,
 	},
 	"yarn": {
 		Name:         "yarn",
@@ -257,6 +351,7 @@ var registryConfigs = map[string]RegistryConfig{
 		DefaultValue: "https://registry.yarnpkg.com/",
 		Parser:       parseYarnConfig,
 		Writer:       writeYarnConfig,
+		Cleaner:      cleanYarnConfig,
 	},
 	"bun": {
 		Name:         "bun",
@@ -264,6 +359,7 @@ var registryConfigs = map[string]RegistryConfig{
 		DefaultValue: "https://registry.npmjs.org/",
 		Parser:       parseBunConfig,
 		Writer:       writeBunConfig,
+		Cleaner:      cleanBunConfig,
 	},
 }
 
@@ -335,6 +431,32 @@ func SetRegistry(name, registry string) error {
 	return writeConfigFile(configPath, newData)
 }
 
+// ManagedConfigFile 返回 nrmgo 设置 registry 时写入的配置文件路径，pnpm 与 npm 共用 .npmrc
+func ManagedConfigFile(name string) (string, error) {
+	if name == "pnpm" {
+		name = "npm"
+	}
+	config, ok := registryConfigs[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported package manager: %s", name)
+	}
+	return getConfigPath(config.ConfigFile)
+}
+
+// CleanConfig 删除 nrmgo 写入配置文件的配置项，返回新的内容与删除的配置项
+// 删除后没有任何内容时返回 nil，说明文件是 nrmgo 创建的
+func CleanConfig(name string, data []byte) ([]byte, []string, error) {
+	if name == "pnpm" {
+		name = "npm"
+	}
+	config, ok := registryConfigs[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported package manager: %s", name)
+	}
+	cleaned, removed := config.Cleaner(data)
+	return cleaned, removed, nil
+}
+
 // GetDefaultRegistry 获取包管理器的默认 registry 配置
 func GetDefaultRegistry(name string) (registry string, configPath string, exists bool, err error) {
 	// 如果是 pnpm，直接使用 npm 的配置
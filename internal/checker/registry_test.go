@@ -0,0 +1,116 @@
+package checker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCleanNPMStyleConfig(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string // 删除后的内容，nil 表示文件只包含 nrmgo 写入的配置项
+		removed []string
+		created bool
+	}{
+		{
+			data:    "registry=https://registry.npmmirror.com/\nalways-auth=false\nstrict-ssl=true\n",
+			removed: []string{"registry", "always-auth", "strict-ssl"},
+			created: true,
+		},
+		{
+			// 用户设置的非默认值保留
+			data:    "registry=https://a/\nstrict-ssl=false\nalways-auth = true\n",
+			want:    "strict-ssl=false\nalways-auth = true\n",
+			removed: []string{"registry"},
+		},
+		{
+			data:    "# registry=https://old/\n//a/:_authToken=abc\n registry = https://a/ \n\n",
+			want:    "# registry=https://old/\n//a/:_authToken=abc\n",
+			removed: []string{"registry"},
+		},
+		{
+			data:    "save-exact=true\n",
+			want:    "save-exact=true\n",
+			removed: nil,
+		},
+		{
+			data:    "",
+			removed: nil,
+			created: true,
+		},
+	}
+	for _, tt := range tests {
+		got, removed := cleanNPMStyleConfig([]byte(tt.data))
+		if string(got) != tt.want || (got == nil) != tt.created || !reflect.DeepEqual(removed, tt.removed) {
+			t.Errorf("cleanNPMStyleConfig(%q) = %q, %q, want %q, %q", tt.data, got, removed, tt.want, tt.removed)
+		}
+	}
+}
+
+func TestCleanBunConfig(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string
+		removed []string
+		created bool
+	}{
+		{
+			data:    "[install]\nregistry = \"https://a/\"\n",
+			removed: []string{"install.registry"},
+			created: true,
+		},
+		{
+			// 只删除 [install] 中的 registry，空的 [install] 一并删除
+			data:    "[install]\nregistry = \"https://a/\"\n\n[run]\nregistry = \"keep\"\nbun = true\n",
+			want:    "[run]\nregistry = \"keep\"\nbun = true\n",
+			removed: []string{"install.registry"},
+		},
+		{
+			data:    "telemetry = false\n\n[install]\nregistry = \"https://a/\"\nexact = true\n",
+			want:    "telemetry = false\n\n[install]\nexact = true\n",
+			removed: []string{"install.registry"},
+		},
+		{
+			data:    "[install.scopes]\nmyorg = { token = \"t\", url = \"https://b/\" }\n\n[install]\nregistry = \"https://a/\"\n",
+			want:    "[install.scopes]\nmyorg = { token = \"t\", url = \"https://b/\" }\n",
+			removed: []string{"install.registry"},
+		},
+		{
+			data:    "[install]\nexact = true\n",
+			want:    "[install]\nexact = true\n",
+			removed: nil,
+		},
+	}
+	for _, tt := range tests {
+		got, removed := cleanBunConfig([]byte(tt.data))
+		if string(got) != tt.want || (got == nil) != tt.created || !reflect.DeepEqual(removed, tt.removed) {
+			t.Errorf("cleanBunConfig(%q) = %q, %q, want %q, %q", tt.data, got, removed, tt.want, tt.removed)
+		}
+	}
+}
+
+func TestCleanConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		removed []string
+		wantErr bool
+	}{
+		{name: "npm", data: "registry=https://a/\nfund=false\n", want: "fund=false\n", removed: []string{"registry"}},
+		{name: "pnpm", data: "registry=https://a/\nfund=false\n", want: "fund=false\n", removed: []string{"registry"}},
+		{name: "yarn", data: "registry \"https://a/\"\nlastUpdateCheck 1\n", want: "lastUpdateCheck 1\n", removed: []string{"registry"}},
+		{name: "bun", data: "[install]\nregistry = \"https://a/\"\n", want: "", removed: []string{"install.registry"}},
+		{name: "cnpm", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, removed, err := CleanConfig(tt.name, []byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CleanConfig(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want || !reflect.DeepEqual(removed, tt.removed) {
+			t.Errorf("CleanConfig(%q, %q) = %q, %q, want %q, %q", tt.name, tt.data, got, removed, tt.want, tt.removed)
+		}
+	}
+}
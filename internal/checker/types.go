@@ -13,11 +13,12 @@ type PackageManager struct {
 
 // RegistryConfig 定义包管理器的 registry 配置
 type RegistryConfig struct {
-	Name         string                       // 包管理器名称
-	ConfigFile   string                       // 配置文件名
-	DefaultValue string                       // 默认 registry
-	Parser       func([]byte) (string, error) // 配置文件解析函数
-	Writer       func([]byte, string) []byte  // 配置文件写入函数
+	Name         string                          // 包管理器名称
+	ConfigFile   string                          // 配置文件名
+	DefaultValue string                          // 默认 registry
+	Parser       func([]byte) (string, error)    // 配置文件解析函数
+	Writer       func([]byte, string) []byte     // 配置文件写入函数
+	Cleaner      func([]byte) ([]byte, []string) // 删除 nrmgo 写入的配置项，返回新内容与删除的配置项
 }
 
 // CommandError 定义命令执行错误
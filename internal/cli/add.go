@@ -5,6 +5,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"nrmgo/internal/backup"
+	"nrmgo/internal/config"
 	"nrmgo/internal/registry"
 	"nrmgo/internal/style"
 	"nrmgo/internal/table"
@@ -15,7 +17,10 @@ var addCmd = &cobra.Command{
 	Short: "Add a custom registry",
 	Long: `Add a custom registry with name and URL.
 Registry name can only contain letters, numbers and underscores.
-Registry URL must be a valid HTTP/HTTPS URL.`,
+Registry URL must be a valid HTTP/HTTPS URL.
+
+Use --probe to choose how latency is tested: ping, packument:<name>, root,
+tcp or a custom /<path>, optionally with --expect-status.`,
 	Args: cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 获取参数
@@ -31,7 +36,7 @@ Registry URL must be a valid HTTP/HTTPS URL.`,
 		}
 
 		// 加载配置并创建管理器
-		_, manager, err := loadConfigAndCreateManager()
+		cfg, manager, err := loadConfigAndCreateManager()
 		if err != nil {
 			return err
 		}
@@ -56,10 +61,18 @@ Registry URL must be a valid HTTP/HTTPS URL.`,
 			return fmt.Errorf("\n%v", err)
 		}
 
+		// 验证探测方式
+		if err := config.ValidateProbe(addProbe, addExpectStatus); err != nil {
+			return fmt.Errorf("\n❌  Invalid probe: %v", err)
+		}
+
 		// 创建新的 registry
 		reg := registry.NewRegistry(name, url, home, description)
+		reg.Probe = addProbe
+		reg.ExpectStatus = addExpectStatus
 
 		// 添加 registry
+		autoBackup(cfg, "add", backup.ConfigManager)
 		if err := manager.Add(name, reg); err != nil {
 			return fmt.Errorf("\n❌  Failed to add registry: %v", err)
 		}
@@ -91,12 +104,28 @@ Registry URL must be a valid HTTP/HTTPS URL.`,
 			style.Success.Sprint(name),
 			style.Success.Sprint(url))
 
+		// 明文 HTTP 的 registry 会泄露流量与认证信息
+		if registry.IsInsecureURL(url) {
+			fmt.Println()
+			style.Warning.Printf("⚠️  %s uses plain http, packages and auth tokens are sent unencrypted\n", name)
+			if cfg.Security.RefuseInsecure {
+				style.Warning.Println("⚠️  security.refuse_insecure is enabled, 'nrmgo use' will refuse to switch to it")
+			}
+		}
+
 		return nil
 	},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 }
 
+var (
+	addProbe        string
+	addExpectStatus []int
+)
+
 func init() {
+	addCmd.Flags().StringVar(&addProbe, "probe", "", "Latency probe: ping, packument:<name>, root, tcp or /<path>")
+	addCmd.Flags().IntSliceVar(&addExpectStatus, "expect-status", nil, "HTTP status codes counted as success (default any 2xx)")
 	rootCmd.AddCommand(addCmd)
 }
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/audit"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// auditCmd 审计 registry 的传输安全
+var auditCmd = &cobra.Command{
+	Use:   "audit-registries [registry...]",
+	Short: "Audit TLS and transport security of registries",
+	Long: `Audit TLS and transport security of registries.
+
+For each registry the following problems are reported:
+  - plain http:// URLs
+  - expired certificates, or certificates expiring within --expiry-days
+  - certificates that do not match the hostname or are not trusted
+  - weak TLS versions, including servers that still accept TLS 1.0/1.1
+  - missing Strict-Transport-Security (HSTS) header
+  - redirects that downgrade from https to http
+
+Set refuse_insecure in the [security] section of config.toml to refuse
+switching to plain http registries.`,
+	Example: `  # Audit all registries
+  nrmgo audit-registries
+
+  # Audit specific registries and warn 30 days before certificates expire
+  nrmgo audit-registries taobao tencent --expiry-days 30`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 加载配置并创建管理器
+		cfg, manager, err := loadConfigAndCreateManager()
+		if err != nil {
+			return err
+		}
+
+		// 命令行参数优先于配置文件
+		if cmd.Flags().Changed("expiry-days") {
+			if auditExpiryDays < 1 {
+				return fmt.Errorf("\n❌  Invalid --expiry-days value: must be positive")
+			}
+			cfg.Security.CertExpiryDays = auditExpiryDays
+		}
+
+		// 检查指定的 registry 是否存在
+		for _, name := range args {
+			if _, ok := manager.Get(name); !ok {
+				return fmt.Errorf("\n❌  Registry '%s' not found", name)
+			}
+		}
+
+		fmt.Println("\n🔍 Auditing registry transport security ...")
+
+		results := manager.Audit(cmd.Context(), args...)
+
+		// 按名称排序
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Name < results[j].Name
+		})
+
+		// 创建表格渲染器
+		renderer := table.NewTableRenderer([]string{
+			"Name",
+			"Registry URL",
+			"TLS",
+			"Cert Expires",
+			"Result",
+		})
+
+		var failed []string
+		for _, result := range results {
+			if !result.Passed() {
+				failed = append(failed, result.Name)
+			}
+
+			tlsVersion, expiry := "-", "-"
+			if result.TLSVersion != "" {
+				tlsVersion = result.TLSVersion
+			}
+			if !result.CertExpiry.IsZero() {
+				expiry = result.CertExpiry.Format(time.DateOnly)
+			}
+
+			renderer.MustAddRow([]string{
+				result.Name,
+				result.URL,
+				tlsVersion,
+				expiry,
+				auditVerdict(result),
+			})
+		}
+
+		// 渲染表格
+		fmt.Println()
+		if err := renderer.Render(); err != nil {
+			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		}
+
+		// 输出问题详情
+		for _, result := range results {
+			if result.Error == "" && len(result.Findings) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s %s\n", style.Info.Sprint("📦"), result.Name)
+			if result.Error != "" {
+				fmt.Printf("  %s\n", style.Error.Sprintf("❌ %s", result.Error))
+			}
+			for _, finding := range result.Findings {
+				fmt.Printf("  %s\n", formatFinding(finding))
+			}
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("\n❌  Audit failed on: %s", strings.Join(failed, ", "))
+		}
+
+		fmt.Printf("\n✨ No critical problems found on %d registries\n", len(results))
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// auditVerdict 汇总单个 registry 的审计结果
+func auditVerdict(result *audit.Result) string {
+	switch {
+	case result.Error != "":
+		return style.Error.Sprint("❌ error")
+	case result.Critical():
+		return style.Error.Sprintf("❌ %d problem(s)", len(result.Findings))
+	case len(result.Findings) > 0:
+		return style.Warning.Sprintf("⚠️ %d warning(s)", len(result.Findings))
+	default:
+		return style.Success.Sprint("✅ pass")
+	}
+}
+
+// formatFinding 格式化审计发现的问题
+func formatFinding(finding audit.Finding) string {
+	if finding.Severity == audit.SeverityCritical {
+		return style.Error.Sprintf("❌ %s: %s", finding.Check, finding.Message)
+	}
+	return style.Warning.Sprintf("⚠️ %s: %s", finding.Check, finding.Message)
+}
+
+// 定义全局变量
+var auditExpiryDays int // 证书即将过期的提醒天数
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().IntVar(&auditExpiryDays, "expiry-days", 0, "Warn when a certificate expires within this many days (overrides security.cert_expiry_days)")
+}
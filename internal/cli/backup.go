@@ -5,120 +5,153 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"nrmgo/internal/backup"
-	"nrmgo/internal/checker"
+	"nrmgo/internal/config"
 	"nrmgo/internal/style"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // backupCmd 备份命令
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Backup package manager configurations",
-	Long:  `Backup package manager configurations to backups/{timestamp} directory`,
-	Run:   runBackup,
+	Long: `Backup package manager configurations to backups/{timestamp} directory.
+
+Without flags every configuration file is backed up: the project, user and
+global config files of npm, yarn, pnpm and bun (also when the package manager
+is not installed), nrmgo's own config.toml and the files matched by
+[backup] include, except those matched by [backup] exclude.
+
+Each backup keeps the files of every package manager in its own subdirectory
+and a manifest.json recording the original paths, SHA-256 checksums, package
+manager versions and registries. Use 'nrmgo backup ls' and 'nrmgo backup show'
+to browse the backups.
+
+Commands that change configuration files (use, unuse, add, rename, rm and
+config init) automatically back up the files they are about to change first.
+Automatic backups are kept according to [backup] auto_keep and auto_max_age,
+set [backup] auto = false to disable them. Use 'nrmgo backup prune' to apply
+the retention policy of [backup.retention], and 'nrmgo backup pin' to keep a
+backup forever.
+
+Set [backup] format = "tar.gz" to keep each backup in a single archive, and
+[backup] encryption to "passphrase" or "x25519" to encrypt it. The passphrase
+is prompted for, or read from the NRMGO_BACKUP_PASSPHRASE environment variable.
+Encrypted backups are age files (.tar.gz.age), the manifest is only stored
+inside the ciphertext. Create an X25519 key with 'nrmgo backup keygen' or
+age-keygen; 'age -d' can decrypt the backups without nrmgo.
+Backups are only readable by the current user.
+
+Set [backup] store = "git" to commit every backup to a local git repository
+instead ([backup] git_repo, "backups-git" in the data directory by default).
+The commit message describes the nrmgo command that took the backup, and
+'nrmgo backup ls', 'diff' and 'restore' read the commit history. The git store
+keeps every backup in its history, so backups are never pruned.`,
+	Run: runBackup,
 }
 
 func runBackup(cmd *cobra.Command, args []string) {
-	// 获取程序所在目录
-	execPath, err := os.Executable()
+	// 创建备份管理器
+	bm, err := newBackupManager()
 	if err != nil {
-		style.Error.Printf("❌ Failed to get executable path: %v\n", err)
+		style.Error.Println(strings.TrimSpace(err.Error()))
 		return
 	}
-	execPath = filepath.Dir(execPath)
 
-	// 检查是否需要清理
+	// 检查是否需要清理，固定的备份不会被删除
 	if days, _ := cmd.Flags().GetInt("clean"); days > 0 {
-		cleaner := backup.NewCleaner(execPath)
-		removed, err := cleaner.Clean(days)
+		policy := backup.Policy{MaxAge: time.Duration(days) * 24 * time.Hour}
+		decisions, err := bm.Prune(map[backup.Trigger]backup.Policy{
+			backup.TriggerManual: policy,
+			backup.TriggerAuto:   policy,
+		}, 0, false)
 		if err != nil {
 			style.Error.Printf("❌ Failed to clean up: %v\n", err)
 			return
 		}
+		removed := 0
+		for _, decision := range decisions {
+			if !decision.Keep {
+				removed++
+			}
+		}
 		style.Success.Printf("🧹 Successfully cleaned up %d directories older than %d days\n", removed, days)
 		return
 	}
 
-	// 获取需要备份的包管理器
+	// 获取需要备份的包管理器，没有指定时备份所有配置文件，包括 nrmgo 自身的配置与 [backup] include
 	var managers []string
-	if all, _ := cmd.Flags().GetBool("all"); all {
-		managers = append(managers, []string{"npm", "yarn", "pnpm", "bun"}...)
-	} else {
-		if npm, _ := cmd.Flags().GetBool("npm"); npm {
-			managers = append(managers, "npm")
-		}
-		if yarn, _ := cmd.Flags().GetBool("yarn"); yarn {
-			managers = append(managers, "yarn")
-		}
-		if pnpm, _ := cmd.Flags().GetBool("pnpm"); pnpm {
-			managers = append(managers, "pnpm")
-		}
-		if bun, _ := cmd.Flags().GetBool("bun"); bun {
-			managers = append(managers, "bun")
+	if all, _ := cmd.Flags().GetBool("all"); !all {
+		for _, name := range []string{"npm", "yarn", "pnpm", "bun"} {
+			if selected, _ := cmd.Flags().GetBool(name); selected {
+				managers = append(managers, name)
+			}
 		}
 	}
 
-	// 如果没有指定任何包管理器，默认备份所有
-	if len(managers) == 0 {
-		managers = append(managers, []string{"npm", "yarn", "pnpm", "bun"}...)
-	}
-
-	// 获取已安装的包管理器
-	installedManagers := make(map[string]bool)
-	for _, pm := range checker.GetAvailableManagers() {
-		installedManagers[pm.Name] = true
-	}
-
-	// 创建备份管理器
-	bm := backup.NewBackupManager(execPath)
-
 	// 执行备份
-	results, err := bm.Backup(managers)
+	results, err := bm.Backup(managers, backup.TriggerManual)
 	if err != nil {
 		style.Error.Printf("❌ Failed to backup: %v\n", err)
 		return
 	}
 
-	// 显示备份目录
-	if len(results) > 0 {
-		backupPath := filepath.Dir(results[0].BackupPath)
-		relPath := strings.TrimPrefix(backupPath, execPath)
-		relPath = strings.TrimPrefix(relPath, string(os.PathSeparator))
-		fmt.Println()
-		style.Info.Printf("📂  Backup directory: %s\n", relPath)
+	// 显示备份目录或归档文件
+	files := make(map[string]bool)
+	for _, result := range results {
+		if result.Success {
+			files[result.SourcePath] = true
+		}
 	}
-
-	// 创建结果映射
-	resultMap := make(map[string]backup.BackupResult)
 	for _, result := range results {
-		resultMap[result.Manager] = result
+		if result.Success {
+			fmt.Println()
+			style.Info.Printf("📂  Backup: %s (%d files)\n", formatBackupLocation(bm, result.BackupPath), len(files))
+			break
+		}
 	}
 
 	// 收集不同状态的包管理器
+	if len(managers) == 0 {
+		managers = bm.GetAllManagers()
+	}
 	var (
 		successList      []string
 		notInstalledList []string
 		failedList       []string
-		failedDetails    = make(map[string]string)
+		failedDetails    = make(map[string][]string)
 	)
 
 	for _, name := range managers {
-		if !installedManagers[name] {
-			notInstalledList = append(notInstalledList, name)
-			continue
+		succeeded, missing := false, false
+		for _, result := range results {
+			switch {
+			case result.Manager != name:
+			case result.Success:
+				succeeded = true
+			case result.SourcePath == "":
+				missing = true
+			default:
+				failedDetails[name] = append(failedDetails[name], fmt.Sprintf("%s: %v", displayPath(result.SourcePath), result.Error))
+			}
 		}
 
-		result, exists := resultMap[name]
-		if !exists || !result.Success {
+		// 未安装且没有配置文件的包管理器不需要备份
+		manager := bm.GetManager(name)
+		switch {
+		case len(failedDetails[name]) > 0:
 			failedList = append(failedList, name)
-			if exists {
-				failedDetails[name] = result.Error.Error()
-			}
-		} else {
+		case succeeded:
 			successList = append(successList, name)
+		case missing && manager != nil && !manager.Installed:
+			notInstalledList = append(notInstalledList, name)
+		default:
+			failedList = append(failedList, name)
+			failedDetails[name] = []string{"config file not found"}
 		}
 	}
 
@@ -134,11 +167,202 @@ func runBackup(cmd *cobra.Command, args []string) {
 
 	// 显示失败信息
 	for _, name := range failedList {
-		if errMsg, ok := failedDetails[name]; ok {
-			style.Error.Printf("\n❌  Backup failed: %s (error: %s)\n", name, errMsg)
-		} else {
-			style.Error.Printf("\n❌  Backup failed: %s\n", name)
+		style.Error.Printf("\n❌  Backup failed: %s (error: %s)\n", name, strings.Join(failedDetails[name], "; "))
+	}
+}
+
+// formatBackupLocation 格式化备份的位置，git 存储显示仓库与提交
+func formatBackupLocation(bm *backup.BackupManager, location string) string {
+	if store, ok := bm.Store.(*backup.GitStore); ok {
+		return fmt.Sprintf("%s @ %s", displayPath(store.Repo), shortHash(location))
+	}
+	return filepath.Join("backups", filepath.Base(location))
+}
+
+// newBackupManager 创建备份管理器，备份保存在 nrmgo 的数据目录下
+func newBackupManager() (*backup.BackupManager, error) {
+	dataDir, err := config.GetDataDir()
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  %v", err)
+	}
+
+	bm := backup.NewBackupManager(dataDir)
+	bm.Version = Version
+
+	// 按 [backup] 配置归档与加密，没有配置文件时使用默认的目录格式
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return bm, nil
+		}
+		return nil, fmt.Errorf("\n❌  Failed to load config: %v", err)
+	}
+	if err := configureBackupManager(bm, cfg.Backup); err != nil {
+		return nil, fmt.Errorf("\n❌  %v", err)
+	}
+	return bm, nil
+}
+
+// backupPassphraseEnv 提供备份密码的环境变量
+const backupPassphraseEnv = "NRMGO_BACKUP_PASSPHRASE"
+
+// configureBackupManager 按 [backup] 配置设置备份的存储、格式、加密的接收者与解密使用的密钥
+// 即使没有开启加密也会加载密钥，以便读取之前创建的加密备份
+func configureBackupManager(bm *backup.BackupManager, settings config.BackupConfig) error {
+	// 额外备份与排除的文件
+	var include []string
+	for _, pattern := range settings.Include {
+		include = append(include, expandHome(pattern))
+	}
+	if err := bm.Include(include); err != nil {
+		return err
+	}
+	for _, pattern := range settings.Exclude {
+		bm.Exclude = append(bm.Exclude, expandHome(pattern))
+	}
+
+	// git 存储不支持归档与加密
+	if settings.Store == config.BackupStoreGit {
+		repo := settings.GitRepo
+		if repo == "" {
+			repo = config.DefaultBackupGitRepo
+		}
+		repo = expandHome(repo)
+		if !filepath.IsAbs(repo) {
+			repo = filepath.Join(bm.ExecPath, repo)
 		}
+		bm.Store = backup.NewGitStore(repo)
+		return nil
+	}
+
+	store := backup.NewDirStore(filepath.Join(bm.ExecPath, "backups"))
+	store.Archive = settings.Format == config.BackupFormatTarGz
+	bm.Store = store
+
+	// 读取私钥文件
+	var keys []*backup.X25519Identity
+	if settings.IdentityFile != "" {
+		data, err := os.ReadFile(expandHome(settings.IdentityFile))
+		if err != nil {
+			return fmt.Errorf("failed to read identity file: %v", err)
+		}
+		identities, err := backup.ParseIdentities(data)
+		if err != nil {
+			return fmt.Errorf("invalid identity file %s: %v", settings.IdentityFile, err)
+		}
+		for _, identity := range identities {
+			if key, ok := identity.(*backup.X25519Identity); ok {
+				keys = append(keys, key)
+			}
+		}
+		store.Identities = append(store.Identities, identities...)
+	}
+
+	// 密码放在最后，只有私钥都无法解密时才会询问
+	passphrase := backup.NewPassphrase(readBackupPassphrase)
+	store.Identities = append(store.Identities, passphrase)
+
+	switch settings.Encryption {
+	case config.BackupEncryptionPassphrase:
+		store.Recipients = []backup.Recipient{passphrase}
+	case config.BackupEncryptionX25519:
+		for _, s := range settings.Recipients {
+			recipient, err := backup.ParseX25519Recipient(s)
+			if err != nil {
+				return err
+			}
+			store.Recipients = append(store.Recipients, recipient)
+		}
+		// 没有配置接收者时加密给自己
+		if len(settings.Recipients) == 0 {
+			for _, key := range keys {
+				store.Recipients = append(store.Recipients, key.Recipient())
+			}
+		}
+		if len(store.Recipients) == 0 {
+			return fmt.Errorf("x25519 encryption requires [backup] recipients or identity_file")
+		}
+	}
+	return nil
+}
+
+// readBackupPassphrase 读取备份密码，优先使用环境变量，否则在终端中询问
+// confirm 为 true 时要求再输入一次，避免加密时输错
+func readBackupPassphrase(confirm bool) (string, error) {
+	if value := os.Getenv(backupPassphraseEnv); value != "" {
+		return value, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("backup passphrase required, set %s", backupPassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "🔑 Backup passphrase: ")
+	value, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "🔑 Confirm passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if string(again) != string(value) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return string(value), nil
+}
+
+// expandHome 将路径开头的 ~ 展开为用户主目录
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// autoBackup 在 command 修改配置文件前自动备份即将被修改的文件，并按配置清理旧的自动备份
+// cfg 为 nil 时使用默认配置，备份失败只给出警告，不影响命令继续执行
+func autoBackup(cfg *config.Config, command string, managers ...string) {
+	settings := config.BackupConfig{
+		AutoMaxAge: config.DefaultBackupAutoMaxAge,
+	}
+	if cfg != nil {
+		settings = cfg.Backup
+	}
+	if !settings.AutoEnabled() || len(managers) == 0 {
+		return
+	}
+
+	bm, err := newBackupManager()
+	if err != nil {
+		style.Warning.Printf("\n⚠️  Automatic backup skipped: %v\n", strings.TrimSpace(err.Error()))
+		return
+	}
+	results, err := bm.AutoBackup(command, managers)
+	if err != nil {
+		style.Warning.Printf("\n⚠️  Automatic backup failed: %v\n", err)
+		return
+	}
+
+	// 不存在的文件不需要备份
+	for _, result := range results {
+		if result.Success {
+			style.Info.Printf("\n🛟 Backed up current files to %s, undo with 'nrmgo restore %s'\n", result.Snapshot, result.Snapshot)
+			break
+		}
+	}
+
+	if _, err := bm.PruneAuto(settings.AutoKeepCount(), settings.AutoMaxAgeDuration()); err != nil {
+		style.Warning.Printf("\n⚠️  Failed to clean up automatic backups: %v\n", err)
 	}
 }
 
@@ -151,5 +375,5 @@ func init() {
 	backupCmd.Flags().Bool("yarn", false, "Backup yarn configuration")
 	backupCmd.Flags().Bool("pnpm", false, "Backup pnpm configuration")
 	backupCmd.Flags().Bool("bun", false, "Backup bun configuration")
-	backupCmd.Flags().Int("clean", 0, "Clean up backups older than specified days")
+	backupCmd.Flags().Int("clean", 0, "Clean up backups older than specified days, see 'nrmgo backup prune' for more policies")
 }
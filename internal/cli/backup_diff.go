@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/backup"
+	"nrmgo/internal/style"
+)
+
+// backupDiffCmd 比较两个备份，或备份与当前的配置文件
+var backupDiffCmd = &cobra.Command{
+	Use:   "diff <a> [b]",
+	Short: "Show what changed between two backups, or a backup and the current files",
+	Long: `Show a unified diff per configuration file between backup <a> and backup <b>,
+or between backup <a> and the current files when <b> is omitted.
+
+The files are compared by what they configure rather than line by line:
+.npmrc and .yarnrc are read as key=value pairs, bunfig.toml and config.toml
+as TOML tables, so comments, blank lines and reordering are not reported.
+Sensitive values such as _authToken or passwords in URLs are redacted; equal
+secrets share a placeholder, so a changed token still shows up as a change.`,
+	Example: `  # What changed since a backup
+  nrmgo backup diff 20240101_120000
+
+  # What changed between two backups
+  nrmgo backup diff 20240101_120000 20240201_120000`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+
+		from, err := bm.GetSnapshot(args[0])
+		if err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+		fromItems, err := bm.PlanRestore(from, nil)
+		if err != nil {
+			return fmt.Errorf("\n❌  Failed to read backup: %v", err)
+		}
+
+		// 每个配置文件的两个版本，npm 与 pnpm 共用的 .npmrc 只比较一次
+		type versions struct {
+			managers []string
+			old, new []byte
+			oldOK    bool
+			newOK    bool
+		}
+		files := make(map[string]*versions)
+		for _, item := range fromItems {
+			files[item.TargetPath] = &versions{managers: item.Managers, old: item.Backup, oldOK: true}
+		}
+
+		toLabel := "current"
+		if len(args) > 1 {
+			to, err := bm.GetSnapshot(args[1])
+			if err != nil {
+				return fmt.Errorf("\n❌  %v", err)
+			}
+			toItems, err := bm.PlanRestore(to, nil)
+			if err != nil {
+				return fmt.Errorf("\n❌  Failed to read backup: %v", err)
+			}
+			for _, item := range toItems {
+				file, ok := files[item.TargetPath]
+				if !ok {
+					file = &versions{managers: item.Managers}
+					files[item.TargetPath] = file
+				}
+				file.new, file.newOK = item.Backup, true
+			}
+			toLabel = to.ID
+		} else {
+			for _, item := range fromItems {
+				file := files[item.TargetPath]
+				file.new, file.newOK = item.Current, item.Exists
+			}
+		}
+
+		if len(files) == 0 {
+			return fmt.Errorf("\n❌  Backup %s has no configuration files", from.ID)
+		}
+
+		paths := make([]string, 0, len(files))
+		for path := range files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		fmt.Printf("\n🔍 Comparing %s with %s\n", style.Info.Sprint(from.ID), style.Info.Sprint(toLabel))
+		changed := 0
+		for _, path := range paths {
+			file := files[path]
+			format := backup.DetectFormat(path)
+			fmt.Printf("\n📄 %s (%s, %s)\n", displayPath(path), strings.Join(file.managers, ", "), format)
+
+			switch {
+			case !file.oldOK:
+				style.Warning.Printf("   not in %s\n", from.ID)
+			case !file.newOK:
+				style.Warning.Printf("   not in %s\n", toLabel)
+			}
+
+			hunks := backup.DiffConfig(format, file.old, file.new, diffContext)
+			if len(hunks) == 0 {
+				style.Success.Println("   unchanged")
+				continue
+			}
+			changed++
+			fmt.Printf("   %s\n", style.Error.Sprint("--- "+from.ID))
+			fmt.Printf("   %s\n", style.Success.Sprint("+++ "+toLabel))
+			printHunks(hunks)
+		}
+
+		if changed == 0 {
+			fmt.Printf("\n✨ No differences between %s and %s\n", from.ID, toLabel)
+		} else {
+			fmt.Printf("\n📝 %d of %d file(s) changed\n", changed, len(files))
+		}
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	backupCmd.AddCommand(backupDiffCmd)
+}
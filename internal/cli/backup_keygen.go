@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/backup"
+	"nrmgo/internal/style"
+)
+
+// 定义全局变量
+var (
+	keygenOutput string // 私钥的保存路径
+)
+
+// backupKeygenCmd 生成加密备份使用的密钥
+var backupKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate a key pair for encrypted backups",
+	Long: `Generate an X25519 key pair for [backup] encryption = "x25519".
+
+The key file has the format of age-keygen, so keys created by age-keygen work
+too. Set [backup] identity_file to the key file, backups are encrypted to its
+public key unless [backup] recipients is set. Encrypted backups are standard
+age files that 'age -d -i <key file>' can decrypt. Keep a copy of the key in a
+safe place, encrypted backups can not be restored without it.`,
+	Example: `  nrmgo backup keygen
+  nrmgo backup keygen --output ~/.nrmgo/backup.key`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		identity, err := backup.GenerateX25519Identity()
+		if err != nil {
+			return fmt.Errorf("\n❌  Failed to generate key: %v", err)
+		}
+		publicKey := identity.Recipient().String()
+		content := fmt.Sprintf("# created: %s\n# public key: %s\n%s\n",
+			time.Now().Format(time.RFC3339), publicKey, identity.String())
+
+		// 没有指定文件时输出到标准输出
+		if keygenOutput == "" {
+			fmt.Print(content)
+			return nil
+		}
+
+		// 私钥文件只允许当前用户读取，不覆盖已有的文件
+		path := expandHome(keygenOutput)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return fmt.Errorf("\n❌  Failed to create key file: %v", err)
+		}
+		if _, err := file.WriteString(content); err != nil {
+			file.Close()
+			return fmt.Errorf("\n❌  Failed to write key file: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("\n❌  Failed to write key file: %v", err)
+		}
+
+		style.Success.Printf("\n🔑 Key written to %s\n", keygenOutput)
+		fmt.Printf("   Public key: %s\n", publicKey)
+		fmt.Printf("\n💡 Set [backup] identity_file = %q and encryption = \"x25519\" to use it\n", keygenOutput)
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	backupCmd.AddCommand(backupKeygenCmd)
+
+	// 添加命令行参数
+	backupKeygenCmd.Flags().StringVarP(&keygenOutput, "output", "o", "", "Write the key to this file instead of stdout")
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/backup"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// backupLsCmd 列出所有备份
+var backupLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List backups",
+	Long: `List backups created by 'nrmgo backup' and the automatic safety backups,
+newest first. Use 'nrmgo backup show <id>' to see the files of a backup.
+Encrypted backups keep their manifest inside the ciphertext, so only their
+ID, time and trigger are listed until 'backup show' decrypts them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+
+		snapshots, err := bm.ListSnapshots()
+		if err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+		if len(snapshots) == 0 {
+			style.Warning.Println("\n⚠️  No backup found, use 'nrmgo backup' to create one")
+			return nil
+		}
+
+		// 创建表格渲染器
+		renderer := table.NewTableRenderer([]string{
+			"ID",
+			"Created",
+			"Trigger",
+			"Files",
+			"Size",
+		})
+		for _, snapshot := range snapshots {
+			renderer.MustAddRow([]string{
+				formatSnapshotID(&snapshot),
+				fmt.Sprintf("%s ago", formatAge(time.Since(snapshot.Time))),
+				formatSnapshotTrigger(&snapshot),
+				formatSnapshotFiles(&snapshot),
+				formatSize(snapshot.Size()),
+			})
+		}
+
+		// 渲染表格
+		fmt.Println()
+		if err := renderer.Render(); err != nil {
+			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		}
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// formatSnapshotID 格式化备份 ID，加密与固定的备份带有标记
+func formatSnapshotID(snapshot *backup.Snapshot) string {
+	id := snapshot.ID
+	if snapshot.Encrypted {
+		id += " 🔒"
+	}
+	if snapshot.Pinned {
+		id += " 📌"
+	}
+	return id
+}
+
+// formatSnapshotFormat 格式化备份的保存方式
+func formatSnapshotFormat(snapshot *backup.Snapshot) string {
+	switch {
+	case snapshot.Commit != "":
+		return "git commit"
+	case snapshot.Encrypted:
+		return "tar.gz, encrypted"
+	case snapshot.Archive:
+		return "tar.gz"
+	default:
+		return "directory"
+	}
+}
+
+// formatSnapshotFiles 格式化备份中的文件，未解密的加密备份不显示文件
+func formatSnapshotFiles(snapshot *backup.Snapshot) string {
+	if snapshot.Locked() {
+		return style.Warning.Sprint("encrypted")
+	}
+	return strings.Join(snapshot.Files, ", ")
+}
+
+// formatSnapshotTrigger 格式化备份的触发方式，无法读取清单的备份标记为损坏
+// 未解密的加密备份只有文件名中的触发方式
+func formatSnapshotTrigger(snapshot *backup.Snapshot) string {
+	if snapshot.Err != nil {
+		return style.Error.Sprint("damaged")
+	}
+	if snapshot.Locked() {
+		return string(snapshot.Trigger())
+	}
+	return formatTrigger(snapshot.Manifest)
+}
+
+// formatTrigger 格式化备份的触发方式，自动备份附带触发的命令，旧版本的备份没有清单
+func formatTrigger(manifest *backup.Manifest) string {
+	if manifest == nil {
+		return "-"
+	}
+	if manifest.Trigger == backup.TriggerAuto {
+		trigger := string(manifest.Trigger)
+		if manifest.Command != "" {
+			trigger = fmt.Sprintf("%s (%s)", trigger, manifest.Command)
+		}
+		return style.Info.Sprint(trigger)
+	}
+	return string(manifest.Trigger)
+}
+
+func init() {
+	backupCmd.AddCommand(backupLsCmd)
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/style"
+)
+
+// backupPinCmd 固定备份
+var backupPinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Pin a backup so it is never removed",
+	Long: `Pin a backup so it is never removed by 'nrmgo backup prune', 'nrmgo backup --clean'
+or the cleanup of automatic backups. Use 'nrmgo backup unpin' to release it.`,
+	Example: `  nrmgo backup pin 20240101_120000`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+		if err := bm.Pin(args[0]); err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+		fmt.Printf("\n📌 Pinned backup %s\n", style.Success.Sprint(args[0]))
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	backupCmd.AddCommand(backupPinCmd)
+}
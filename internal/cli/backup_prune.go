@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/backup"
+	"nrmgo/internal/config"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// backupPruneCmd 按保留策略清理备份
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove backups according to a retention policy",
+	Long: `Remove backups according to a retention policy.
+
+Manual backups are kept when any rule selects them:
+  --keep-last N      the newest N backups
+  --keep-daily N     the newest backup of each of the last N days with backups
+  --keep-weekly N    the newest backup of each of the last N weeks with backups
+  --keep-monthly N   the newest backup of each of the last N months with backups
+
+Backups older than --max-age are removed even when a rule selects them. When
+the backups together are larger than --max-size the oldest ones are removed
+until they fit, the newest backup is always kept. Automatic backups follow
+[backup] auto_keep and auto_max_age, and pinned backups are never removed.
+
+Without flags the policy of [backup.retention] in config.toml is used, flags
+override single rules of it.`,
+	Example: `  # Show what the configured policy would remove
+  nrmgo backup prune --dry-run
+
+  # Keep the last 5 backups and one per month for a year
+  nrmgo backup prune --keep-last 5 --keep-monthly 12
+
+  # Keep the backups below 10MB in total
+  nrmgo backup prune --max-size 10MB`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadConfigAndCreateManager()
+		if err != nil {
+			return err
+		}
+
+		// 命令行参数覆盖配置文件中的保留策略
+		retention := cfg.Backup.Retention
+		flags := cmd.Flags()
+		for _, rule := range []struct {
+			name  string
+			value *int
+			flag  int
+		}{
+			{"keep-last", &retention.KeepLast, pruneKeepLast},
+			{"keep-daily", &retention.KeepDaily, pruneKeepDaily},
+			{"keep-weekly", &retention.KeepWeekly, pruneKeepWeekly},
+			{"keep-monthly", &retention.KeepMonthly, pruneKeepMonthly},
+		} {
+			if flags.Changed(rule.name) {
+				if rule.flag < 0 {
+					return fmt.Errorf("\n❌  --%s must not be negative", rule.name)
+				}
+				*rule.value = rule.flag
+			}
+		}
+		if flags.Changed("max-age") {
+			if d, err := config.ParseDuration(pruneMaxAge); err != nil || d <= 0 {
+				return fmt.Errorf("\n❌  Invalid --max-age value: %q", pruneMaxAge)
+			}
+			retention.MaxAge = pruneMaxAge
+		}
+		if flags.Changed("max-size") {
+			if n, err := config.ParseSize(pruneMaxSize); err != nil || n <= 0 {
+				return fmt.Errorf("\n❌  Invalid --max-size value: %q", pruneMaxSize)
+			}
+			retention.MaxSize = pruneMaxSize
+		}
+
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+		decisions, err := bm.Prune(map[backup.Trigger]backup.Policy{
+			backup.TriggerManual: {
+				KeepLast:    retention.KeepLast,
+				KeepDaily:   retention.KeepDaily,
+				KeepWeekly:  retention.KeepWeekly,
+				KeepMonthly: retention.KeepMonthly,
+				MaxAge:      retention.MaxAgeDuration(),
+			},
+			backup.TriggerAuto: {
+				KeepLast: cfg.Backup.AutoKeepCount(),
+				MaxAge:   cfg.Backup.AutoMaxAgeDuration(),
+			},
+		}, retention.MaxSizeBytes(), pruneDryRun)
+		if err != nil {
+			return fmt.Errorf("\n❌  Failed to prune backups: %v", err)
+		}
+		if len(decisions) == 0 {
+			style.Warning.Println("\n⚠️  No backup found")
+			return nil
+		}
+
+		// 创建表格渲染器
+		renderer := table.NewTableRenderer([]string{
+			"ID",
+			"Created",
+			"Trigger",
+			"Size",
+			"Action",
+			"Reason",
+		})
+		var (
+			removed int
+			freed   int64
+		)
+		for _, decision := range decisions {
+			snapshot := decision.Snapshot
+			action := style.Success.Sprint("keep")
+			if !decision.Keep {
+				action = style.Error.Sprint("remove")
+				removed++
+				freed += decision.Size
+			}
+			renderer.MustAddRow([]string{
+				formatSnapshotID(&snapshot),
+				fmt.Sprintf("%s ago", formatAge(time.Since(snapshot.Time))),
+				formatSnapshotTrigger(&snapshot),
+				formatSize(decision.Size),
+				action,
+				strings.Join(decision.Reasons, ", "),
+			})
+		}
+
+		// 渲染表格
+		fmt.Println()
+		if err := renderer.Render(); err != nil {
+			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		}
+
+		switch {
+		case removed == 0:
+			style.Success.Println("\n✨ Nothing to remove")
+		case pruneDryRun:
+			style.Info.Printf("\n🔍 Dry run: %d backup(s) would be removed, freeing %s\n", removed, formatSize(freed))
+		default:
+			style.Success.Printf("\n🧹 Removed %d backup(s), freed %s\n", removed, formatSize(freed))
+		}
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// 定义全局变量
+var (
+	pruneKeepLast    int    // 保留最新的备份数量
+	pruneKeepDaily   int    // 按天保留的备份数量
+	pruneKeepWeekly  int    // 按周保留的备份数量
+	pruneKeepMonthly int    // 按月保留的备份数量
+	pruneMaxAge      string // 最长保留时间
+	pruneMaxSize     string // 总大小上限
+	pruneDryRun      bool   // 只显示将被删除的备份
+)
+
+func init() {
+	backupCmd.AddCommand(backupPruneCmd)
+
+	// 添加命令行参数
+	flags := backupPruneCmd.Flags()
+	flags.IntVar(&pruneKeepLast, "keep-last", 0, "Keep the newest N backups")
+	flags.IntVar(&pruneKeepDaily, "keep-daily", 0, "Keep the newest backup of each of the last N days")
+	flags.IntVar(&pruneKeepWeekly, "keep-weekly", 0, "Keep the newest backup of each of the last N weeks")
+	flags.IntVar(&pruneKeepMonthly, "keep-monthly", 0, "Keep the newest backup of each of the last N months")
+	flags.StringVar(&pruneMaxAge, "max-age", "", "Remove backups older than this, e.g. 90d")
+	flags.StringVar(&pruneMaxSize, "max-size", "", "Maximum total size of the backups, e.g. 100MB")
+	flags.BoolVar(&pruneDryRun, "dry-run", false, "Only show which backups would be removed")
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// backupShowCmd 显示备份的详细信息
+var backupShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the files of a backup",
+	Long: `Show the details of a backup: when and how it was created, and for every
+file the package manager, the config layer (project, user or global), the
+package manager version, the registry in effect at backup time, the original
+path, the size and the SHA-256 checksum. Files that did not exist when the
+backup was taken are listed as missing.`,
+	Example: `  nrmgo backup show 20240101_120000`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := bm.GetSnapshot(args[0])
+		if err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+
+		fmt.Printf("\n📂 Backup %s\n", style.Info.Sprint(snapshot.ID))
+		fmt.Printf("   Created: %s (%s ago)\n", snapshot.Time.Format(time.DateTime), formatAge(time.Since(snapshot.Time)))
+		if snapshot.Commit != "" {
+			fmt.Printf("   Commit:  %s (%s)\n", shortHash(snapshot.Commit), displayPath(snapshot.Path))
+		} else {
+			fmt.Printf("   Path:    %s\n", filepath.Join("backups", filepath.Base(snapshot.Path)))
+		}
+		fmt.Printf("   Format:  %s\n", formatSnapshotFormat(snapshot))
+		if snapshot.Pinned {
+			fmt.Printf("   Pinned:  %s\n", style.Success.Sprint("yes"))
+		}
+
+		// 无法读取清单的备份
+		if snapshot.Err != nil {
+			return fmt.Errorf("\n❌  Backup %s is damaged: %v, run 'nrmgo backup verify %s' for details", snapshot.ID, snapshot.Err, snapshot.ID)
+		}
+
+		// 加密的备份需要解密后才能读取清单
+		if err := bm.Unlock(snapshot); err != nil {
+			return fmt.Errorf("\n❌  Failed to read backup: %v", err)
+		}
+
+		// 旧版本创建的备份没有清单，只能列出文件
+		manifest := snapshot.Manifest
+		if manifest == nil {
+			style.Warning.Println("   No manifest, created by an older nrmgo version")
+
+			renderer := table.NewTableRenderer([]string{"File", "Size"})
+			for _, file := range snapshot.Files {
+				size := "-"
+				if info, err := statBackupFile(snapshot.Path, file); err == nil {
+					size = formatSize(info)
+				}
+				renderer.MustAddRow([]string{file, size})
+			}
+			fmt.Println()
+			if err := renderer.Render(); err != nil {
+				return fmt.Errorf("\n❌  Failed to render table: %v", err)
+			}
+			return nil
+		}
+
+		fmt.Printf("   Trigger: %s\n", formatTrigger(manifest))
+		fmt.Printf("   nrmgo:   %s\n", manifest.NrmgoVersion)
+
+		// 创建表格渲染器
+		renderer := table.NewTableRenderer([]string{
+			"Manager",
+			"Scope",
+			"Version",
+			"Registry",
+			"Source",
+			"Size",
+			"SHA-256",
+		})
+		for _, file := range manifest.Files {
+			registry := file.Registry
+			if registry == "" {
+				registry = "-"
+			}
+			scope := file.Scope
+			if scope == "" {
+				scope = "-"
+			}
+			version := file.ManagerVersion
+			if version == "" {
+				version = "-"
+			}
+			// 备份时不存在的文件
+			size := formatSize(file.Size)
+			if file.Missing {
+				size = style.Warning.Sprint("missing")
+			}
+			renderer.MustAddRow([]string{
+				file.Manager,
+				scope,
+				version,
+				registry,
+				displayPath(file.Source),
+				size,
+				shortHash(file.SHA256),
+			})
+		}
+
+		// 渲染表格
+		fmt.Println()
+		if err := renderer.Render(); err != nil {
+			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		}
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// statBackupFile 返回备份文件的大小
+func statBackupFile(dir, name string) (int64, error) {
+	info, err := os.Stat(filepath.Join(dir, name))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// shortHash 截取哈希的前 12 位用于显示
+func shortHash(sum string) string {
+	if sum == "" {
+		return "-"
+	}
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
+
+func init() {
+	backupCmd.AddCommand(backupShowCmd)
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/style"
+)
+
+// backupUnpinCmd 取消固定备份
+var backupUnpinCmd = &cobra.Command{
+	Use:     "unpin <id>",
+	Short:   "Unpin a backup so retention policies apply to it again",
+	Example: `  nrmgo backup unpin 20240101_120000`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+		if err := bm.Unpin(args[0]); err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+		fmt.Printf("\n✨ Unpinned backup %s\n", style.Success.Sprint(args[0]))
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	backupCmd.AddCommand(backupUnpinCmd)
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/backup"
+	"nrmgo/internal/style"
+)
+
+// 定义全局变量
+var (
+	verifyAll bool // 校验所有备份
+)
+
+// backupVerifyCmd 校验备份的完整性
+var backupVerifyCmd = &cobra.Command{
+	Use:   "verify [id]",
+	Short: "Verify the integrity of backups",
+	Long: `Verify the integrity of a backup, or of every backup with --all.
+
+Every file is hashed again and compared with the SHA-256 and size recorded in
+the backup's manifest, which detects missing, truncated and modified files.
+Every file is also parsed with the parser of its format (TOML for bunfig.toml
+and config.toml, key=value for .npmrc and .yarnrc) to catch corruption.
+Encrypted backups are decrypted and checked against the manifest inside the
+archive. Backups created by older nrmgo versions have no manifest and are only
+parsed.
+
+Without an id the latest backup is verified. The command exits with a non-zero
+status when any backup fails, so it can be run from a cron job.`,
+	Example: `  nrmgo backup verify
+  nrmgo backup verify 20240101_120000
+  nrmgo backup verify --all`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verifyAll && len(args) > 0 {
+			return fmt.Errorf("\n❌  Specify either a backup id or --all")
+		}
+
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+
+		// 选择需要校验的备份
+		snapshots, err := bm.ListSnapshots()
+		if err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+		switch {
+		case len(args) > 0:
+			snapshot, err := bm.GetSnapshot(args[0])
+			if err != nil {
+				return fmt.Errorf("\n❌  %v", err)
+			}
+			snapshots = []backup.Snapshot{*snapshot}
+		case len(snapshots) == 0:
+			return fmt.Errorf("\n❌  No backup found, use 'nrmgo backup' to create one")
+		case !verifyAll:
+			snapshots = snapshots[:1]
+		}
+
+		var failed []string
+		for i := range snapshots {
+			result := bm.Verify(&snapshots[i])
+			printVerifyResult(result)
+			if !result.Passed() {
+				failed = append(failed, snapshots[i].ID)
+			}
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("\n❌  Verification failed: %s", strings.Join(failed, ", "))
+		}
+		fmt.Printf("\n✨ %d backup(s) verified\n", len(snapshots))
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// printVerifyResult 显示一个备份的校验结果，只列出未通过的文件
+func printVerifyResult(result *backup.VerifyResult) {
+	id := formatSnapshotID(result.Snapshot)
+	if result.Error != nil {
+		fmt.Printf("\n❌ %s: %s\n", id, style.Error.Sprint(result.Error))
+		return
+	}
+
+	var problems []backup.FileCheck
+	unverified := 0
+	for _, check := range result.Files {
+		if !check.Passed() {
+			problems = append(problems, check)
+		}
+		if check.Status == backup.CheckUnverified {
+			unverified++
+		}
+	}
+
+	switch {
+	case len(problems) > 0:
+		fmt.Printf("\n❌ %s: %s\n", id, style.Error.Sprintf("%d of %d file(s) failed", len(problems), len(result.Files)))
+	case unverified > 0:
+		fmt.Printf("\n⚠️  %s: %s\n", id, style.Warning.Sprintf("%d file(s) parsed, no manifest to check hashes against", len(result.Files)))
+	default:
+		fmt.Printf("\n✅ %s: %s\n", id, style.Success.Sprintf("%d file(s) OK", len(result.Files)))
+	}
+
+	for _, check := range problems {
+		source := ""
+		if check.Source != "" {
+			source = fmt.Sprintf(" (%s)", displayPath(check.Source))
+		}
+		fmt.Printf("   %s%s: %s, %s\n", check.Path, source, style.Error.Sprint(check.Status), check.Detail)
+	}
+}
+
+func init() {
+	backupCmd.AddCommand(backupVerifyCmd)
+
+	// 添加命令行参数
+	backupVerifyCmd.Flags().BoolVar(&verifyAll, "all", false, "Verify all backups")
+}
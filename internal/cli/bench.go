@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/bench"
+	"nrmgo/internal/registry"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// benchCmd 以项目的依赖模拟安装，测试各 registry 的实际表现
+var benchCmd = &cobra.Command{
+	Use:   "bench [registry...]",
+	Short: "Benchmark registries by resolving a project's dependencies",
+	Long: `Benchmark registries by resolving the dependency metadata of a project.
+
+The dependencies are read from the lockfile of the project (npm-shrinkwrap.json,
+package-lock.json, yarn.lock or pnpm-lock.yaml), or from package.json when there
+is no lockfile. For package.json the full dependency graph is resolved from
+abbreviated packuments, the same metadata package managers request on install.
+
+Registries are benchmarked one after another so they do not compete for
+bandwidth, with --concurrency metadata requests in flight per registry. The
+registries are ranked by total wall time, registries with failed requests or
+unresolvable dependencies are ranked last. Tarballs are not downloaded.`,
+	Example: `  # Benchmark all registries with the project in the current directory
+  nrmgo bench --project .
+
+  # Benchmark specific registries with 32 requests in flight
+  nrmgo bench taobao tencent --project ./web --concurrency 32
+
+  # Switch to the registry that resolves the project fastest
+  nrmgo use --project .`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 加载配置并创建管理器
+		_, manager, err := loadConfigAndCreateManager()
+		if err != nil {
+			return err
+		}
+
+		// 检查指定的 registry 是否存在
+		for _, name := range args {
+			if _, ok := manager.Get(name); !ok {
+				return fmt.Errorf("\n❌  Registry '%s' not found", name)
+			}
+		}
+
+		// 读取项目依赖
+		project, err := loadBenchProject(benchProject)
+		if err != nil {
+			return err
+		}
+
+		// 监听 Ctrl-C，中断时取消剩余的测试
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		total := len(args)
+		if total == 0 {
+			total = len(manager.List())
+		}
+		results, err := runBench(ctx, manager, project, benchConcurrency, total, args...)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("\n⚠️  Interrupted after %d/%d registries", len(results), total)
+		}
+
+		best := results[0]
+		if !best.Passed() {
+			return fmt.Errorf("\n❌  No registry resolved all dependencies of %s", project.Source)
+		}
+		fmt.Printf("\n✨ Fastest for this project: %s (%s)\n",
+			style.Success.Sprint(best.Name),
+			best.Duration.Round(time.Millisecond))
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// loadBenchProject 读取项目依赖并输出概要
+func loadBenchProject(dir string) (*bench.Project, error) {
+	project, err := bench.LoadProject(dir)
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  %v", err)
+	}
+	if len(project.Dependencies) == 0 {
+		return nil, fmt.Errorf("\n❌  No registry dependencies found in %s", project.Source)
+	}
+
+	kind := "direct dependencies, resolving the full graph"
+	if project.Locked {
+		kind = "locked packages"
+	}
+	fmt.Printf("\n📦 %s: %d %s\n", style.Info.Sprint(project.Source), len(project.Dependencies), kind)
+	return project, nil
+}
+
+// runBench 依次在 registry 上模拟安装，显示进度并渲染排名
+// 返回的结果已按排名排序，被中断时只包含已完成的 registry
+func runBench(ctx context.Context, manager registry.Manager, project *bench.Project, concurrency, total int, names ...string) ([]*bench.Result, error) {
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Resolving dependencies (0/%d registries)", total))
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  Failed to create spinner: %v", err)
+	}
+
+	var results []*bench.Result
+	for result := range manager.Bench(ctx, project, concurrency, names...) {
+		if result.Cancelled {
+			continue
+		}
+		results = append(results, result)
+		spinner.UpdateText(fmt.Sprintf("Resolving dependencies (%d/%d registries, %s took %s)",
+			len(results), total, result.Name, result.Duration.Round(time.Millisecond)))
+	}
+	if err := spinner.Stop(); err != nil {
+		return nil, fmt.Errorf("\n❌  Failed to stop spinner: %v", err)
+	}
+
+	rankBench(results)
+
+	fmt.Println()
+	if err := renderBench(results); err != nil {
+		return nil, err
+	}
+
+	// 输出失败原因
+	for _, result := range results {
+		if result.Passed() {
+			continue
+		}
+		fmt.Printf("\n%s %s\n", style.Error.Sprint("❌"), result.Name)
+		for _, reason := range result.Errors {
+			fmt.Printf("   - %s\n", reason)
+		}
+		if more := result.Failures - len(result.Errors); more > 0 {
+			fmt.Printf("   ... and %d more\n", more)
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("\n⚠️  Interrupted before any registry finished")
+	}
+	return results, nil
+}
+
+// rankBench 排序：没有失败的 registry 按总耗时在前，
+// 有失败的 registry 在后，解析到的包越多越靠前
+func rankBench(results []*bench.Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Passed() != b.Passed() {
+			return a.Passed()
+		}
+		if !a.Passed() && a.Packages != b.Packages {
+			return a.Packages > b.Packages
+		}
+		return a.Duration < b.Duration
+	})
+}
+
+// renderBench 以表格形式显示模拟安装结果
+func renderBench(results []*bench.Result) error {
+	renderer := table.NewTableRenderer([]string{
+		"#",
+		"Name",
+		"Registry URL",
+		"Wall Time",
+		"Packages",
+		"Requests",
+		"Metadata",
+		"Failures",
+	})
+
+	for i, result := range results {
+		failures := style.Success.Sprint("0")
+		if result.Failures > 0 {
+			failures = style.Error.Sprintf("%d", result.Failures)
+		}
+
+		row := []string{
+			fmt.Sprintf("%d", i+1),
+			result.Name,
+			result.URL,
+			result.Duration.Round(time.Millisecond).String(),
+			fmt.Sprintf("%d", result.Packages),
+			fmt.Sprintf("%d", result.Requests),
+			formatSize(result.Bytes),
+			failures,
+		}
+
+		// 高亮最快且没有失败的 registry
+		if i == 0 && result.Passed() {
+			for j := range row {
+				row[j] = style.Success.Sprint(row[j])
+			}
+		}
+		renderer.MustAddRow(row)
+	}
+
+	if err := renderer.Render(); err != nil {
+		return fmt.Errorf("\n❌  Failed to render table: %v", err)
+	}
+	return nil
+}
+
+// formatSize 格式化字节数
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// 定义全局变量
+var (
+	benchProject     string // 项目目录
+	benchConcurrency int    // 每个 registry 的并发请求数量
+)
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	// 添加命令行参数
+	flags := benchCmd.Flags()
+	flags.StringVar(&benchProject, "project", ".", "Project directory containing package.json or a lockfile")
+	flags.IntVar(&benchConcurrency, "concurrency", bench.DefaultConcurrency, "Number of metadata requests in flight per registry")
+}
@@ -0,0 +1,360 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/backup"
+	"nrmgo/internal/checker"
+	"nrmgo/internal/config"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// 定义全局变量
+var (
+	cleanPurge  bool // 同时删除 nrmgo 的配置、备份与历史记录
+	cleanYes    bool // 不确认直接执行
+	cleanDryRun bool // 只显示将要执行的操作
+)
+
+// cleanCmd 撤销 nrmgo 对配置文件的所有修改
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Revert every change nrmgo made, before uninstalling it",
+	Long: `Revert every change nrmgo made to the package manager configuration files,
+so the machine is back to how it was before nrmgo was used.
+
+nrmgo only writes the user config files ~/.npmrc (shared by npm and pnpm),
+~/.yarnrc and ~/.bunfig.toml. Each of them is restored from the earliest backup
+that records it, which is the file as it was before nrmgo first changed it. A
+file that did not exist in that backup was created by nrmgo and is deleted.
+The restored content and files that were never backed up are cleaned as well:
+the registry nrmgo set is removed, as are the always-auth=false and
+strict-ssl=true lines it injected into .npmrc. A file left empty was created
+by nrmgo and is deleted.
+
+With --purge nrmgo's own data is deleted as well: config.toml, the backups and
+the history in the data directory. A git backup repository outside the data
+directory is never deleted.
+
+A summary and the changes of every file are shown first, and nothing is
+changed without confirmation.`,
+	Example: `  # Show what would be reverted
+  nrmgo clean --dry-run
+
+  # Revert the config files and delete nrmgo's config, backups and history
+  nrmgo clean --purge`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+
+		// 生成撤销计划
+		items, err := planClean(bm)
+		if err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+		var purge []string
+		if cleanPurge {
+			if purge, err = purgePaths(bm); err != nil {
+				return fmt.Errorf("\n❌  %v", err)
+			}
+		}
+
+		// 显示摘要与每个文件的差异
+		changed := printCleanPlan(items, purge)
+		if len(changed) == 0 && len(purge) == 0 {
+			fmt.Printf("\n✨ Nothing to clean, nrmgo left no changes behind\n")
+			return nil
+		}
+		if cleanDryRun {
+			fmt.Printf("\n💡 Dry run, nothing was changed\n")
+			return nil
+		}
+
+		// 确认
+		if !cleanYes {
+			if !isInteractive() {
+				return fmt.Errorf("\n❌  Refusing to clean without confirmation, pass --yes to clean non-interactively")
+			}
+			prompt := fmt.Sprintf("Revert %d file(s)?", len(changed))
+			if len(purge) > 0 {
+				prompt = fmt.Sprintf("Revert %d file(s) and permanently delete nrmgo's config, backups and history?", len(changed))
+			}
+			fmt.Println()
+			confirmed, err := pterm.DefaultInteractiveConfirm.Show(prompt)
+			if err != nil {
+				return fmt.Errorf("\n❌  Failed to read confirmation: %v", err)
+			}
+			if !confirmed {
+				return fmt.Errorf("\n⚠️  Clean cancelled, nothing was changed")
+			}
+		}
+
+		// 保留 nrmgo 的数据时先备份当前的文件，以便撤销
+		if len(purge) == 0 {
+			var restoring []*backup.RestoreItem
+			for _, item := range changed {
+				restoring = append(restoring, item.RestoreItem)
+			}
+			safety, err := safetyBackup(bm, restoring, "clean")
+			if err != nil {
+				return err
+			}
+			if safety != "" {
+				style.Info.Printf("\n🛟 Safety backup of the current files: %s\n", safety)
+			}
+		}
+
+		// 恢复或删除配置文件
+		for _, item := range changed {
+			if item.Delete {
+				if err := os.Remove(item.TargetPath); err != nil {
+					return fmt.Errorf("\n❌  Failed to delete %s: %v", displayPath(item.TargetPath), err)
+				}
+				continue
+			}
+			if err := bm.Restore([]*backup.RestoreItem{item.RestoreItem}); err != nil {
+				return fmt.Errorf("\n❌  %v", err)
+			}
+		}
+		if len(changed) > 0 {
+			style.Success.Printf("\n🎉  Reverted %d file(s)\n", len(changed))
+		}
+
+		// 删除 nrmgo 的数据
+		for _, path := range purge {
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("\n❌  Failed to delete %s: %v", displayPath(path), err)
+			}
+		}
+		if len(purge) > 0 {
+			style.Success.Printf("\n🗑️  Deleted nrmgo's config, backups and history\n")
+		}
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// cleanItem 一个需要撤销修改的配置文件
+type cleanItem struct {
+	*backup.RestoreItem
+	Snapshot string   // 恢复使用的备份，为空时删除 nrmgo 写入的配置项
+	Removed  []string // 删除的配置项
+	Delete   bool     // 文件只包含 nrmgo 写入的配置项，直接删除
+}
+
+// Changed 判断是否需要修改文件，已被删除的文件不再需要撤销
+func (item *cleanItem) Changed() bool {
+	if item.Delete {
+		return true
+	}
+	return item.Exists && item.RestoreItem.Changed()
+}
+
+// planClean 为 nrmgo 写入的每个配置文件生成撤销计划
+// 优先使用最早记录了该文件的备份：备份时不存在的文件被删除，
+// 备份晚于 nrmgo 第一次修改该文件时，恢复的内容同样删除 nrmgo 写入的配置项
+// 没有备份时直接删除 nrmgo 写入的配置项
+func planClean(bm *backup.BackupManager) ([]*cleanItem, error) {
+	var items []*cleanItem
+	byPath := make(map[string]*cleanItem)
+	for _, name := range []string{"npm", "pnpm", "yarn", "bun"} {
+		path, err := checker.ManagedConfigFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if item, ok := byPath[path]; ok {
+			item.Managers = append(item.Managers, name)
+			continue
+		}
+
+		snapshot, restore, err := bm.Earliest(path, isCleanBackup)
+		if err != nil {
+			return nil, err
+		}
+		item := &cleanItem{RestoreItem: restore}
+		if snapshot != nil {
+			item.Snapshot = snapshot.ID
+			item.Managers = []string{name}
+			// 最早的备份可能晚于 nrmgo 第一次修改该文件，恢复的内容同样删除 nrmgo 写入的配置项
+			created := restore.Absent
+			if !restore.Absent && !isPristineBackup(snapshot) {
+				cleaned, removed, err := checker.CleanConfig(name, restore.Backup)
+				if err != nil {
+					return nil, err
+				}
+				if len(removed) > 0 {
+					restore.Backup = cleaned
+					item.Removed = removed
+					created = cleaned == nil
+				}
+			}
+			item.Delete = created && restore.Exists
+		} else {
+			current, err := os.ReadFile(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			cleaned, removed, err := checker.CleanConfig(name, current)
+			if err != nil {
+				return nil, err
+			}
+			if len(removed) == 0 {
+				cleaned = current
+			}
+			item.RestoreItem = &backup.RestoreItem{
+				Managers:   []string{name},
+				TargetPath: path,
+				Current:    current,
+				Backup:     cleaned,
+				Mode:       info.Mode().Perm(),
+				Exists:     true,
+			}
+			item.Removed = removed
+			item.Delete = len(removed) > 0 && cleaned == nil
+		}
+
+		byPath[path] = item
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// isCleanBackup 判断是否为 nrmgo clean 创建的安全备份，其中的文件已经被 nrmgo 修改过
+func isCleanBackup(snapshot *backup.Snapshot) bool {
+	return snapshot.Manifest != nil && snapshot.Manifest.Trigger == backup.TriggerAuto && snapshot.Manifest.Command == "clean"
+}
+
+// isPristineBackup 判断备份是否在 nrmgo 第一次修改配置文件之前创建，其中的文件保持用户原有的内容
+// use 前的自动备份记录的是切换前的文件；其他命令只修改 nrmgo 已经写入过的文件，手动备份与旧版本的备份无法判断
+func isPristineBackup(snapshot *backup.Snapshot) bool {
+	return snapshot.Manifest != nil && snapshot.Manifest.Trigger == backup.TriggerAuto && snapshot.Manifest.Command == "use"
+}
+
+// purgePaths 返回 --purge 需要删除的 nrmgo 数据，不存在的路径被跳过
+// 位于数据目录之外的 git 备份仓库可能还保存着其他文件，不会被删除
+func purgePaths(bm *backup.BackupManager) ([]string, error) {
+	dataDir, err := config.GetDataDir()
+	if err != nil {
+		return nil, err
+	}
+	candidates := []string{
+		filepath.Join(dataDir, config.ConfigFileName),
+		filepath.Join(dataDir, "history.jsonl"),
+		filepath.Join(dataDir, "backups"),
+		filepath.Join(dataDir, config.DefaultBackupGitRepo),
+	}
+	if store, ok := bm.Store.(*backup.GitStore); ok {
+		if rel, err := filepath.Rel(dataDir, store.Repo); err == nil && !strings.HasPrefix(rel, "..") && rel != "." {
+			candidates = append(candidates, store.Repo)
+		} else {
+			style.Warning.Printf("\n⚠️  Keeping git repository %s, its nrmgo/ directory holds the backups\n", displayPath(store.Repo))
+		}
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Lstat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// printCleanPlan 显示撤销计划与每个文件的差异，返回需要修改的文件
+func printCleanPlan(items []*cleanItem, purge []string) []*cleanItem {
+	var changed []*cleanItem
+	if len(items) > 0 {
+		renderer := table.NewTableRenderer([]string{"File", "Managers", "Action"})
+		for _, item := range items {
+			action := "unchanged"
+			switch {
+			case !item.Changed():
+			case item.Delete && item.Absent:
+				action = fmt.Sprintf("delete, did not exist in backup %s", item.Snapshot)
+			case item.Delete:
+				action = "delete, only contains settings written by nrmgo"
+			case item.Snapshot != "" && len(item.Removed) > 0:
+				action = fmt.Sprintf("restore from backup %s, remove %s", item.Snapshot, strings.Join(item.Removed, ", "))
+			case item.Snapshot != "":
+				action = fmt.Sprintf("restore from backup %s", item.Snapshot)
+			default:
+				action = fmt.Sprintf("remove %s", strings.Join(item.Removed, ", "))
+			}
+			if item.Changed() {
+				changed = append(changed, item)
+			}
+			renderer.MustAddRow([]string{displayPath(item.TargetPath), strings.Join(item.Managers, ", "), action})
+		}
+		fmt.Printf("\n🧹 Config files written by nrmgo\n\n")
+		if err := renderer.Render(); err != nil {
+			style.Error.Printf("❌ Failed to render table: %v\n", err)
+		}
+	}
+
+	for _, item := range changed {
+		fmt.Printf("\n📄 %s (%s)\n", displayPath(item.TargetPath), strings.Join(item.Managers, ", "))
+		if item.Delete {
+			style.Warning.Println("   file will be deleted")
+			printDiff(string(item.Current), "")
+			continue
+		}
+		printDiff(string(item.Current), string(item.Backup))
+	}
+
+	if len(purge) > 0 {
+		fmt.Printf("\n🗑️  nrmgo data to delete permanently\n")
+		for _, path := range purge {
+			fmt.Printf("   %s (%s)\n", displayPath(path), formatSize(pathSize(path)))
+		}
+	}
+	return changed
+}
+
+// pathSize 返回文件或目录的总大小
+func pathSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+
+	// 添加命令行参数
+	flags := cleanCmd.Flags()
+	flags.BoolVar(&cleanPurge, "purge", false, "Also delete nrmgo's config, backups and history")
+	flags.BoolVarP(&cleanYes, "yes", "y", false, "Clean without asking for confirmation")
+	flags.BoolVar(&cleanDryRun, "dry-run", false, "Only show what would be reverted")
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"nrmgo/internal/backup"
 	"nrmgo/internal/config"
 	"nrmgo/internal/style"
 )
@@ -36,6 +37,12 @@ var configInitCmd = &cobra.Command{
 		}
 		configPath := config.GetConfigPath(execPath)
 
+		// 覆盖已有的配置文件前自动备份，配置文件无法解析时使用默认的备份设置
+		if _, err := os.Stat(configPath); err == nil {
+			cfg, _ := config.LoadConfig()
+			autoBackup(cfg, "config init", backup.ConfigManager)
+		}
+
 		// 写入配置文件
 		if err := os.WriteFile(configPath, []byte(template), 0644); err != nil {
 			return fmt.Errorf("❌  Failed to write config file: %v", err)
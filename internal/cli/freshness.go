@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/freshness"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// freshnessCmd 检测镜像同步延迟
+var freshnessCmd = &cobra.Command{
+	Use:   "freshness [registry...]",
+	Short: "Measure how far mirrors lag behind the upstream registry",
+	Long: `Measure how far mirrors lag behind the upstream registry.
+
+The dist-tags.latest and time.modified of the sentinel packages configured in
+config.toml ([freshness] section) are compared between each registry and the
+upstream registry, and the lag is reported as a duration.`,
+	Example: `  # Check all registries
+  nrmgo freshness
+
+  # Check specific registries
+  nrmgo freshness taobao tencent`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 加载配置并创建管理器
+		cfg, manager, err := loadConfigAndCreateManager()
+		if err != nil {
+			return err
+		}
+
+		// 检查指定的 registry 是否存在
+		for _, name := range args {
+			if _, ok := manager.Get(name); !ok {
+				return fmt.Errorf("\n❌  Registry '%s' not found", name)
+			}
+		}
+
+		fmt.Printf("\n🔍 Comparing %s with %s ...\n",
+			strings.Join(cfg.Freshness.SentinelPackages, ", "),
+			cfg.Freshness.Upstream)
+
+		results := manager.Freshness(cmd.Context(), args...)
+
+		// 按同步延迟排序，检测失败的排在后面
+		sort.Slice(results, func(i, j int) bool {
+			if (results[i].Error == "") != (results[j].Error == "") {
+				return results[i].Error == ""
+			}
+			return results[i].Lag < results[j].Lag
+		})
+
+		maxLag := cfg.Freshness.MaxLagDuration()
+
+		// 创建表格渲染器
+		renderer := table.NewTableRenderer([]string{
+			"Name",
+			"Registry URL",
+			"Lag",
+			"Details",
+		})
+
+		for _, result := range results {
+			lag := formatLag(result)
+			if result.Exceeds(maxLag) {
+				lag = style.Error.Sprint(lag)
+			}
+
+			renderer.MustAddRow([]string{
+				result.Name,
+				result.URL,
+				lag,
+				formatLagDetails(result),
+			})
+		}
+
+		// 渲染表格
+		fmt.Println()
+		if err := renderer.Render(); err != nil {
+			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		}
+
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// formatLag 格式化同步延迟
+func formatLag(result *freshness.Result) string {
+	if result == nil || result.Error != "" {
+		return "-"
+	}
+	if result.Lag < time.Minute {
+		return "up to date"
+	}
+	return strings.TrimSuffix(result.Lag.Round(time.Minute).String(), "0s")
+}
+
+// formatLagDetails 格式化每个哨兵包的同步情况
+func formatLagDetails(result *freshness.Result) string {
+	details := make([]string, 0, len(result.Packages))
+	for _, pkg := range result.Packages {
+		switch {
+		case pkg.Error != "":
+			details = append(details, fmt.Sprintf("%s: %s", pkg.Package, pkg.Error))
+		case pkg.MirrorLatest != pkg.UpstreamLatest:
+			details = append(details, fmt.Sprintf("%s@%s (upstream %s)", pkg.Package, pkg.MirrorLatest, pkg.UpstreamLatest))
+		default:
+			details = append(details, fmt.Sprintf("%s@%s", pkg.Package, pkg.MirrorLatest))
+		}
+	}
+	return strings.Join(details, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(freshnessCmd)
+}
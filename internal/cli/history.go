@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/config"
+	"nrmgo/internal/history"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// 定义全局变量
+var (
+	historySince  string // 统计的时间范围
+	historyFormat string // 输出格式
+	historyOutput string // 导出文件路径
+)
+
+// historySparkWidth 火花线展示的记录数量
+const historySparkWidth = 30
+
+// historyCmd 显示延迟测试历史
+var historyCmd = &cobra.Command{
+	Use:   "history [registry]",
+	Short: "Show latency test history and trends",
+	Long: `Show latency test history and trends.
+
+Every latency test run by 'nrmgo use' is stored in history.jsonl next to the
+nrmgo binary. Records are kept according to the [history] section in
+config.toml. The table shows the success rate, the median latency and a
+sparkline of the most recent results for each registry (× marks a failure).
+Records are matched by name, so removed or renamed registries can still be
+viewed under their old name.`,
+	Example: `  # Show trends of all registries in the last 7 days
+  nrmgo history --since 7d
+
+  # Show trends of a single registry
+  nrmgo history taobao
+
+  # Export raw records as CSV
+  nrmgo history --format csv --output history.csv`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 加载配置并创建管理器
+		cfg, _, err := loadConfigAndCreateManager()
+		if err != nil {
+			return err
+		}
+
+		// 构建查询条件，按名称过滤记录，已删除或重命名的 registry 同样可以查看
+		filter := history.Filter{}
+		if len(args) > 0 {
+			filter.Registry = args[0]
+		}
+		if historySince != "" {
+			since, err := config.ParseDuration(historySince)
+			if err != nil {
+				return fmt.Errorf("\n❌  Invalid --since value: %v", err)
+			}
+			filter.Since = time.Now().Add(-since)
+		}
+
+		// 查询历史记录
+		store, err := history.NewStoreFromConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("\n❌  Failed to open history: %v", err)
+		}
+		records, err := store.Query(filter)
+		if err != nil {
+			return fmt.Errorf("\n❌  Failed to read history: %v", err)
+		}
+
+		switch historyFormat {
+		case "table":
+			return renderHistory(records, filter.Registry)
+		case "csv", "json":
+			return exportHistory(records, historyFormat, historyOutput)
+		default:
+			return fmt.Errorf("\n❌  Unsupported format: %s (expected table, csv or json)", historyFormat)
+		}
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// renderHistory 以表格形式显示每个 registry 的历史统计
+func renderHistory(records []history.Record, name string) error {
+	if len(records) == 0 {
+		fmt.Println()
+		if name != "" {
+			style.Warning.Printf("⚠️  No history records found for '%s'\n", name)
+			return nil
+		}
+		style.Warning.Println("⚠️  No history records found, run 'nrmgo use' to collect some")
+		return nil
+	}
+
+	// 创建表格渲染器
+	renderer := table.NewTableRenderer([]string{
+		"Name",
+		"Tests",
+		"Success",
+		"Median",
+		"Last Test",
+		"Trend",
+	})
+
+	for _, summary := range history.Summarize(records, historySparkWidth) {
+		median := "-"
+		if summary.Success > 0 {
+			median = fmt.Sprintf("%dms", summary.MedianLatency.Milliseconds())
+		}
+
+		rate := fmt.Sprintf("%.1f%%", summary.SuccessRate()*100)
+		switch {
+		case summary.SuccessRate() >= 0.95:
+			rate = style.Success.Sprint(rate)
+		case summary.SuccessRate() < 0.5:
+			rate = style.Error.Sprint(rate)
+		default:
+			rate = style.Warning.Sprint(rate)
+		}
+
+		renderer.MustAddRow([]string{
+			summary.Registry,
+			fmt.Sprintf("%d", summary.Total),
+			rate,
+			median,
+			summary.Last.Local().Format("2006-01-02 15:04"),
+			summary.Sparkline,
+		})
+	}
+
+	// 渲染表格
+	fmt.Println()
+	if err := renderer.Render(); err != nil {
+		return fmt.Errorf("\n❌  Failed to render table: %v", err)
+	}
+	return nil
+}
+
+// exportHistory 导出原始历史记录
+func exportHistory(records []history.Record, format, output string) error {
+	var (
+		w    io.Writer = os.Stdout
+		file *os.File
+	)
+	if output != "" {
+		var err error
+		if file, err = os.Create(output); err != nil {
+			return fmt.Errorf("\n❌  Failed to create output file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	var err error
+	if format == "csv" {
+		err = history.WriteCSV(w, records)
+	} else {
+		err = history.WriteJSON(w, records)
+	}
+	if err != nil {
+		return fmt.Errorf("\n❌  Failed to export history: %v", err)
+	}
+
+	if file != nil {
+		// 关闭时才可能报告写入失败
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("\n❌  Failed to write output file: %v", err)
+		}
+		fmt.Print(style.Success.Sprintf("\n✨ Exported %d records to %s\n", len(records), output))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	// 添加命令行参数
+	flags := historyCmd.Flags()
+	flags.StringVar(&historySince, "since", "", "Only include records newer than this duration (e.g. 24h, 7d, 2w)")
+	flags.StringVarP(&historyFormat, "format", "f", "table", "Output format: table, csv or json")
+	flags.StringVarP(&historyOutput, "output", "o", "", "Write csv/json export to this file instead of stdout")
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nrmgo/internal/checker"
+	"nrmgo/internal/config"
+	"nrmgo/internal/history"
+	"nrmgo/internal/latency"
+	"nrmgo/internal/registry"
+	"nrmgo/internal/scoring"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// offlineSelection 表示离线模式下根据历史测试结果选择的结果
+type offlineSelection struct {
+	ranked   []scoring.Breakdown       // 参与评分的 registry，按得分降序
+	latest   map[string]history.Record // 每个 registry 最近一次测试记录
+	failed   []history.Record          // 最近一次测试失败的 registry
+	untested []*registry.Info          // 有效期内没有测试记录的 registry
+	ttl      time.Duration             // 历史测试结果有效期
+}
+
+// offlineRegistry 在离线模式下根据最近的历史测试结果选择并切换 registry
+func offlineRegistry(cfg *config.Config, manager registry.Manager, installedPMs []checker.PackageManager, registries []*registry.Info) error {
+	sel, err := selectOffline(cfg, registries)
+	if err != nil {
+		return err
+	}
+
+	// 醒目地提示数据来自缓存
+	fmt.Println()
+	style.Warning.Printf("⚠️  OFFLINE: using cached test results from the last %s, no registry was probed\n", cfg.Offline.TTL)
+	style.Warning.Println("   The data below is stale and may not reflect current network conditions")
+	fmt.Println()
+
+	if err := renderOffline(sel); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	// 如果没有可用的 registry
+	if len(sel.ranked) == 0 {
+		return fmt.Errorf("\n❌  No registry was reachable in the cached test results, run 'nrmgo use' while online to refresh them")
+	}
+	best := sel.ranked[0]
+
+	// 设置得分最高的 registry 为当前使用的 registry
+	if err := useRegistry(cfg, manager, installedPMs, best.Name); err != nil {
+		return fmt.Errorf("\n❌  Failed to set registry: %v", err)
+	}
+
+	// 输出成功信息
+	fmt.Printf("✨ Successfully Changed Package Manager(%s) to: %s (score %.2f, %s)\n",
+		strings.Join(installedNames(installedPMs), ", "),
+		style.Success.Sprint(best.Name),
+		best.Score,
+		style.Warning.Sprintf("stale, tested %s ago", formatAge(time.Since(sel.latest[best.Name].Time))))
+	return nil
+}
+
+// selectOffline 根据有效期内的历史测试结果为 registry 评分
+func selectOffline(cfg *config.Config, registries []*registry.Info) (*offlineSelection, error) {
+	sel := &offlineSelection{
+		latest: make(map[string]history.Record),
+		ttl:    cfg.Offline.TTLDuration(),
+	}
+
+	// 读取有效期内的历史记录
+	store, err := history.NewStoreFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  Failed to open history: %v", err)
+	}
+	all, err := store.Query(history.Filter{Since: time.Now().Add(-sel.ttl)})
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  Failed to read history: %v", err)
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("\n❌  No test results in the last %s to choose from offline, run 'nrmgo use' while online first", cfg.Offline.TTL)
+	}
+
+	records := make(map[string][]history.Record)
+	for _, record := range all {
+		records[record.Registry] = append(records[record.Registry], record)
+		if latest, ok := sel.latest[record.Registry]; !ok || record.Time.After(latest.Time) {
+			sel.latest[record.Registry] = record
+		}
+	}
+
+	// 配置要求时，明文 HTTP 的 registry 不参与自动选择
+	names, err := secureNames(cfg, registries)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool)
+	for _, name := range names {
+		allowed[name] = true
+	}
+
+	// 构建评分依据，只考虑仍然存在且最近一次测试可用的 registry
+	var inputs []scoring.Input
+	for _, reg := range registries {
+		if names != nil && !allowed[reg.Name] {
+			continue
+		}
+
+		latest, ok := sel.latest[reg.Name]
+		if !ok {
+			sel.untested = append(sel.untested, reg)
+			continue
+		}
+		if !latest.Online {
+			sel.failed = append(sel.failed, latest)
+			continue
+		}
+
+		input := scoring.InputFromHistory(reg.Name, records[reg.Name])
+		input.Degraded = latest.Status == latency.StatusDegraded.String()
+		inputs = append(inputs, input)
+	}
+
+	sel.ranked = scoring.PolicyFromConfig(cfg).Score(inputs)
+
+	// 失败的 registry 按名称排序
+	sort.Slice(sel.failed, func(i, j int) bool {
+		return sel.failed[i].Registry < sel.failed[j].Registry
+	})
+
+	return sel, nil
+}
+
+// renderOffline 以表格形式显示离线模式的评分明细
+func renderOffline(sel *offlineSelection) error {
+	headers := []string{"Name", "Registry URL", "Last Latency", "Median", "Success", "Last Tested", "Bias", "Score"}
+	renderer := table.NewTableRenderer(headers)
+
+	// 添加参与评分的 registry
+	for i, b := range sel.ranked {
+		latest := sel.latest[b.Name]
+
+		row := []string{
+			b.Name,
+			latest.URL,
+			fmt.Sprintf("%dms", latest.Latency.Duration().Milliseconds()),
+			fmt.Sprintf("%dms", b.Latency.Milliseconds()),
+			fmt.Sprintf("%.0f%%", b.SuccessRate*100),
+			style.Warning.Sprintf("%s ago", formatAge(time.Since(latest.Time))),
+			formatBias(b.Bias),
+			fmt.Sprintf("%.2f", b.Score),
+		}
+
+		// 高亮得分最高的 registry
+		if i == 0 {
+			for j := range row {
+				row[j] = style.Success.Sprint(row[j])
+			}
+		}
+		renderer.MustAddRow(row)
+	}
+
+	// 添加最近一次测试失败的 registry
+	for _, record := range sel.failed {
+		renderer.MustAddRow([]string{
+			record.Registry,
+			record.URL,
+			"-",
+			"-",
+			"-",
+			style.Warning.Sprintf("%s ago", formatAge(time.Since(record.Time))),
+			"-",
+			formatStatus(latency.ParseStatus(record.Status)),
+		})
+	}
+
+	// 添加有效期内没有测试记录的 registry
+	for _, reg := range sel.untested {
+		renderer.MustAddRow([]string{reg.Name, reg.URL, "-", "-", "-", "never", "-", style.Info.Sprint("no data")})
+	}
+
+	if err := renderer.Render(); err != nil {
+		return fmt.Errorf("\n❌  Failed to render table: %v", err)
+	}
+	return nil
+}
+
+// formatAge 以易读的方式格式化时间间隔
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"nrmgo/internal/backup"
+	"nrmgo/internal/checker"
 	"nrmgo/internal/registry"
 	"nrmgo/internal/style"
 )
@@ -36,7 +38,7 @@ Note: Built-in registries cannot be renamed.`,
 		newName := args[1]
 
 		// 加载配置并创建管理器
-		_, manager, err := loadConfigAndCreateManager()
+		cfg, manager, err := loadConfigAndCreateManager()
 		if err != nil {
 			return err
 		}
@@ -83,6 +85,13 @@ Note: Built-in registries cannot be renamed.`,
 			}
 		}
 
+		// 重命名当前使用的 registry 时还会修改包管理器的配置文件
+		managers := []string{backup.ConfigManager}
+		if current != nil && current.Name == oldName {
+			managers = append(managers, installedNames(checker.DetectPackageManagers())...)
+		}
+		autoBackup(cfg, "rename", managers...)
+
 		// 执行重命名
 		if err := manager.Rename(oldName, newName); err != nil {
 			return fmt.Errorf("\n❌  %v", err)
@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"nrmgo/internal/backup"
+	"nrmgo/internal/style"
+)
+
+// diffContext 显示差异时每段前后保留的上下文行数
+const diffContext = 3
+
+// restoreCmd 从备份恢复包管理器配置
+var restoreCmd = &cobra.Command{
+	Use:   "restore [timestamp]",
+	Short: "Restore package manager configurations from a backup",
+	Long: `Restore package manager configurations from a backup created by 'nrmgo backup'.
+
+Without a timestamp an interactive picker lists the available backups. Before
+anything is overwritten the differences between the current files and the
+backup are shown, and a safety backup of the current files is created so the
+restore itself can be undone with another 'nrmgo restore'.`,
+	Example: `  # Pick a backup interactively
+  nrmgo restore
+
+  # Restore only the npm configuration from a specific backup
+  nrmgo restore 20240101_120000 --npm
+
+  # Restore without confirmation
+  nrmgo restore 20240101_120000 --yes`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bm, err := newBackupManager()
+		if err != nil {
+			return err
+		}
+
+		// 选择备份
+		var snapshot *backup.Snapshot
+		if len(args) > 0 {
+			snapshot, err = bm.GetSnapshot(args[0])
+			if err != nil {
+				return fmt.Errorf("\n❌  %v", err)
+			}
+		} else {
+			snapshot, err = pickSnapshot(bm)
+			if err != nil {
+				return err
+			}
+		}
+
+		// 生成恢复计划
+		managers := restoreManagers()
+		items, err := bm.PlanRestore(snapshot, managers)
+		if err != nil {
+			return fmt.Errorf("\n❌  Failed to read backup: %v", err)
+		}
+		if len(items) == 0 {
+			return fmt.Errorf("\n❌  Backup %s has no configuration of the selected package managers", snapshot.ID)
+		}
+
+		// 显示差异
+		fmt.Printf("\n📂 Backup %s\n", style.Info.Sprint(snapshot.ID))
+		var changed []*backup.RestoreItem
+		for _, item := range items {
+			fmt.Printf("\n📄 %s (%s)\n", displayPath(item.TargetPath), strings.Join(item.Managers, ", "))
+			if !item.Changed() {
+				style.Success.Println("   unchanged")
+				continue
+			}
+			if !item.Exists {
+				style.Warning.Println("   file does not exist and will be created")
+			}
+			printDiff(string(item.Current), string(item.Backup))
+			changed = append(changed, item)
+		}
+
+		if len(changed) == 0 {
+			fmt.Printf("\n✨ Current configuration already matches backup %s\n", style.Success.Sprint(snapshot.ID))
+			return nil
+		}
+
+		// 确认覆盖
+		if !restoreYes {
+			if !isInteractive() {
+				return fmt.Errorf("\n❌  Refusing to overwrite files without confirmation, pass --yes to restore non-interactively")
+			}
+			fmt.Println()
+			confirmed, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Overwrite %d file(s) with backup %s?", len(changed), snapshot.ID))
+			if err != nil {
+				return fmt.Errorf("\n❌  Failed to read confirmation: %v", err)
+			}
+			if !confirmed {
+				return fmt.Errorf("\n⚠️  Restore cancelled, nothing was changed")
+			}
+		}
+
+		// 覆盖前备份当前的配置文件
+		safety, err := safetyBackup(bm, changed, "restore")
+		if err != nil {
+			return err
+		}
+		if safety != "" {
+			style.Info.Printf("\n🛟 Safety backup of the current files: %s\n", safety)
+		}
+
+		// 恢复配置文件
+		if err := bm.Restore(changed); err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+
+		var restored []string
+		for _, item := range changed {
+			for _, name := range item.Managers {
+				if !slices.Contains(restored, name) {
+					restored = append(restored, name)
+				}
+			}
+		}
+		style.Success.Printf("\n🎉  Successfully restored %s from backup %s\n", strings.Join(restored, ", "), snapshot.ID)
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// pickSnapshot 交互式选择备份
+func pickSnapshot(bm *backup.BackupManager) (*backup.Snapshot, error) {
+	snapshots, err := bm.ListSnapshots()
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  %v", err)
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("\n❌  No backup found, use 'nrmgo backup' to create one")
+	}
+	if !isInteractive() {
+		return nil, fmt.Errorf("\n❌  No timestamp given, the latest backup is %s", snapshots[0].ID)
+	}
+
+	options := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		options[i] = fmt.Sprintf("%s  (%s ago)  %s",
+			snapshot.ID,
+			formatAge(time.Since(snapshot.Time)),
+			formatSnapshotFiles(&snapshot))
+	}
+
+	fmt.Println()
+	selected, err := pterm.DefaultInteractiveSelect.
+		WithOptions(options).
+		WithDefaultText("Select a backup to restore").
+		Show()
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  Failed to select backup: %v", err)
+	}
+	for i, option := range options {
+		if option == selected {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("\n❌  No backup selected")
+}
+
+// safetyBackup 在 command 覆盖配置文件前备份这些文件，返回备份目录名
+// 目标文件都不存在时不需要备份，返回空字符串
+func safetyBackup(bm *backup.BackupManager, items []*backup.RestoreItem, command string) (string, error) {
+	var existing []*backup.RestoreItem
+	for _, item := range items {
+		if item.Exists {
+			existing = append(existing, item)
+		}
+	}
+	if len(existing) == 0 {
+		return "", nil
+	}
+
+	results, err := bm.AutoBackupItems(command, existing)
+	if err != nil {
+		return "", fmt.Errorf("\n❌  Failed to create safety backup, nothing was changed: %v", err)
+	}
+	for _, result := range results {
+		if !result.Success {
+			return "", fmt.Errorf("\n❌  Failed to create safety backup of %s, nothing was changed: %v", result.Manager, result.Error)
+		}
+	}
+	if len(results) == 0 {
+		return "", fmt.Errorf("\n❌  Failed to create safety backup, no file was saved, nothing was changed")
+	}
+	return results[0].Snapshot, nil
+}
+
+// printDiff 以 unified diff 的格式显示两段文本的差异，敏感值会被隐藏
+func printDiff(oldText, newText string) {
+	r := backup.NewRedactor()
+	printHunks(backup.Hunks(backup.Diff(r.Redact(oldText), r.Redact(newText)), diffContext))
+}
+
+// printHunks 显示差异段落，删除的行为红色，新增的行为绿色
+func printHunks(hunks []backup.DiffHunk) {
+	for _, hunk := range hunks {
+		fmt.Printf("   %s\n", style.Info.Sprint(hunk.Header()))
+		for _, line := range hunk.Lines {
+			switch line.Op {
+			case backup.DiffDelete:
+				fmt.Printf("   %s\n", style.Error.Sprint(line.String()))
+			case backup.DiffInsert:
+				fmt.Printf("   %s\n", style.Success.Sprint(line.String()))
+			default:
+				fmt.Printf("   %s\n", line.String())
+			}
+		}
+	}
+}
+
+// displayPath 将用户目录显示为 ~
+func displayPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// isInteractive 判断标准输入与标准输出是否为终端
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// restoreManagers 返回命令行指定的包管理器，未指定时返回 nil 表示全部
+func restoreManagers() []string {
+	var managers []string
+	for _, flag := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"npm", restoreNPM},
+		{"yarn", restoreYarn},
+		{"pnpm", restorePNPM},
+		{"bun", restoreBun},
+	} {
+		if flag.enabled {
+			managers = append(managers, flag.name)
+		}
+	}
+	return managers
+}
+
+// 定义全局变量
+var (
+	restoreNPM  bool // 恢复 npm 配置
+	restoreYarn bool // 恢复 yarn 配置
+	restorePNPM bool // 恢复 pnpm 配置
+	restoreBun  bool // 恢复 bun 配置
+	restoreYes  bool // 不确认直接覆盖
+)
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	// 添加命令行参数
+	flags := restoreCmd.Flags()
+	flags.BoolVar(&restoreNPM, "npm", false, "Restore npm configuration")
+	flags.BoolVar(&restoreYarn, "yarn", false, "Restore yarn configuration")
+	flags.BoolVar(&restorePNPM, "pnpm", false, "Restore pnpm configuration")
+	flags.BoolVar(&restoreBun, "bun", false, "Restore bun configuration")
+	flags.BoolVarP(&restoreYes, "yes", "y", false, "Overwrite files without asking for confirmation")
+}
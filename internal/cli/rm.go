@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"nrmgo/internal/backup"
+	"nrmgo/internal/config"
 	"nrmgo/internal/registry"
 	"nrmgo/internal/style"
 )
@@ -32,7 +34,7 @@ Note: Built-in registries cannot be removed.`,
   nrmgo rm --all`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 加载配置并创建管理器
-		_, manager, err := loadConfigAndCreateManager()
+		cfg, manager, err := loadConfigAndCreateManager()
 		if err != nil {
 			return err
 		}
@@ -42,21 +44,21 @@ Note: Built-in registries cannot be removed.`,
 
 		// 执行删除操作
 		if removeAll {
-			return removeAllCustomRegistries(manager, forceRemove)
+			return removeAllCustomRegistries(cfg, manager, forceRemove)
 		}
 
 		if len(args) == 0 {
 			return fmt.Errorf("\n❌  Registry name is required")
 		}
 
-		return removeRegistry(manager, args[0], forceRemove, current)
+		return removeRegistry(cfg, manager, args[0], forceRemove, current)
 	},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 }
 
 // removeRegistry 删除单个 registry
-func removeRegistry(manager registry.Manager, name string, force bool, current *registry.Info) error {
+func removeRegistry(cfg *config.Config, manager registry.Manager, name string, force bool, current *registry.Info) error {
 	// 检查 registry 是否存在
 	if _, exists := manager.Get(name); !exists {
 		return fmt.Errorf("\n❌  Registry '%s' not found", name)
@@ -81,6 +83,7 @@ func removeRegistry(manager registry.Manager, name string, force bool, current *
 	}
 
 	// 执行删除
+	autoBackup(cfg, "rm", backup.ConfigManager)
 	if err := manager.Remove(name); err != nil {
 		return fmt.Errorf("\n❌  Failed to remove registry: %v", err)
 	}
@@ -90,7 +93,7 @@ func removeRegistry(manager registry.Manager, name string, force bool, current *
 }
 
 // removeAllCustomRegistries 删除所有自定义 registry
-func removeAllCustomRegistries(manager registry.Manager, force bool) error {
+func removeAllCustomRegistries(cfg *config.Config, manager registry.Manager, force bool) error {
 	// 获取所有 registry
 	registries := manager.List()
 
@@ -121,6 +124,7 @@ func removeAllCustomRegistries(manager registry.Manager, force bool) error {
 	}
 
 	// 执行删除
+	autoBackup(cfg, "rm", backup.ConfigManager)
 	var removed []string
 	for _, reg := range customRegs {
 		if err := manager.Remove(reg.Name); err != nil {
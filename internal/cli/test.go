@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/latency"
+	"nrmgo/internal/registry"
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+)
+
+// testCmd 测试 registry 的延迟
+var testCmd = &cobra.Command{
+	Use:   "test [registry...]",
+	Short: "Test registry latency without switching",
+	Long: `Test the latency of all registries, or only the given ones, without
+changing the registry of any package manager.
+
+Timeout, max latency, samples, user agent and concurrency are read from the
+[latency] section in config.toml and can be overridden with flags.
+
+With --family both every registry is tested over IPv4 and IPv6 separately, and
+registries whose IPv6 path is broken or much slower are flagged. Testing an
+address family always connects to the registries directly, bypassing any proxy.`,
+	Example: `  # Test all registries
+  nrmgo test
+
+  # Test specific registries with 3 samples each
+  nrmgo test taobao tencent --samples 3
+
+  # Compare IPv4 and IPv6
+  nrmgo test --family both`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 加载配置并创建管理器
+		cfg, manager, err := loadConfigAndCreateManager()
+		if err != nil {
+			return err
+		}
+
+		// --family both 表示对比两个地址族，而不是限制连接使用的地址族
+		compare := testLatency.family == familyBoth
+		if compare {
+			testLatency.family = ""
+		}
+
+		// 命令行参数覆盖配置文件中的延迟测试选项
+		if err := testLatency.apply(cmd, cfg); err != nil {
+			return err
+		}
+
+		// 检查指定的 registry 是否存在
+		for _, name := range args {
+			if _, ok := manager.Get(name); !ok {
+				return fmt.Errorf("\n❌  Registry '%s' not found", name)
+			}
+		}
+		warnProxy(cfg, manager, args, compare)
+		total := len(args)
+		if total == 0 {
+			total = len(manager.List())
+		}
+
+		// 监听 Ctrl-C，中断时取消剩余的测试
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		// 对比 IPv4 与 IPv6
+		if compare {
+			return compareFamilies(ctx, manager, args)
+		}
+
+		// 测试延迟，结果实时刷新
+		fmt.Println()
+		results, err := streamTest(ctx, manager, total, args...)
+		if err != nil {
+			return err
+		}
+
+		// 渲染表格
+		if err := latencyTable(results).Render(); err != nil {
+			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("\n⚠️  Interrupted after %d/%d registries", countCompleted(results), total)
+		}
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// compareFamilies 分别通过 IPv4 与 IPv6 测试 registry 并显示对比结果
+func compareFamilies(ctx context.Context, manager registry.Manager, names []string) error {
+	spinner, err := pterm.DefaultSpinner.Start("Testing registries over IPv4 and IPv6")
+	if err != nil {
+		return fmt.Errorf("\n❌  Failed to create spinner: %v", err)
+	}
+	results := manager.CompareFamilies(ctx, names...)
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("\n❌  Failed to stop spinner: %v", err)
+	}
+
+	// 创建表格渲染器
+	renderer := table.NewTableRenderer([]string{
+		"Name",
+		"Registry URL",
+		"IPv4",
+		"IPv6",
+		"Verdict",
+	})
+	var problems []string
+	for _, result := range results {
+		renderer.MustAddRow([]string{
+			result.Name,
+			result.URL,
+			formatFamilyLatency(result.IPv4),
+			formatFamilyLatency(result.IPv6),
+			formatVerdict(result.Verdict),
+		})
+		if result.Verdict.Problem() {
+			problems = append(problems, result.Name)
+		}
+	}
+
+	// 渲染表格
+	fmt.Println()
+	if err := renderer.Render(); err != nil {
+		return fmt.Errorf("\n❌  Failed to render table: %v", err)
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("\n⚠️  Interrupted, showing %d completed registries", len(results))
+	}
+
+	// IPv6 路径有问题时，建议让 Node.js 优先使用 IPv4
+	if len(problems) > 0 {
+		fmt.Println()
+		style.Warning.Printf("⚠️  IPv6 is broken or much slower for: %s\n", strings.Join(problems, ", "))
+		style.Info.Println("💡 Consider preferring IPv4, e.g. export NODE_OPTIONS=--dns-result-order=ipv4first")
+	}
+	return nil
+}
+
+// formatFamilyLatency 格式化单个地址族的测试结果
+func formatFamilyLatency(result *latency.Result) string {
+	if result.Reachable() {
+		return fmt.Sprintf("%dms", result.Latency.Milliseconds())
+	}
+	return style.Error.Sprint(result.ErrorKind.String())
+}
+
+// formatVerdict 格式化双栈对比结论
+func formatVerdict(verdict latency.FamilyVerdict) string {
+	switch verdict {
+	case latency.VerdictOK:
+		return style.Success.Sprint("✅ ok")
+	case latency.VerdictIPv4Only, latency.VerdictIPv6Only:
+		return verdict.String()
+	case latency.VerdictIPv6Broken, latency.VerdictIPv6Slow:
+		return style.Warning.Sprintf("⚠️ %s", verdict)
+	default:
+		return style.Error.Sprintf("❌ %s", verdict)
+	}
+}
+
+// familyBoth 表示对比 IPv4 与 IPv6
+const familyBoth = "both"
+
+// 定义全局变量
+var testLatency latencyFlags // 延迟测试选项
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+	testLatency.register(testCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"nrmgo/internal/checker"
+	"nrmgo/internal/config"
 	"nrmgo/internal/style"
 )
 
@@ -77,6 +78,16 @@ func runUnuse(cmd *cobra.Command, args []string) error {
 		failedDetails    = make(map[string]string)
 	)
 
+	// 备份即将被修改的配置文件
+	var changing []string
+	for _, name := range managers {
+		if installedMap[name] {
+			changing = append(changing, name)
+		}
+	}
+	cfg, _ := config.LoadConfig()
+	autoBackup(cfg, "unuse", changing...)
+
 	// 执行恢复操作
 	for _, name := range managers {
 		if !installedMap[name] {
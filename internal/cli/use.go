@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"sort"
 	"strings"
 	"time"
@@ -9,8 +12,14 @@ import (
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
+	"nrmgo/internal/bench"
 	"nrmgo/internal/checker"
+	"nrmgo/internal/config"
+	"nrmgo/internal/freshness"
+	"nrmgo/internal/history"
+	"nrmgo/internal/latency"
 	"nrmgo/internal/registry"
+	"nrmgo/internal/scoring"
 	"nrmgo/internal/style"
 	"nrmgo/internal/table"
 )
@@ -20,10 +29,21 @@ var useCmd = &cobra.Command{
 	Use:   "use [registry]",
 	Short: "Switch registry for package managers",
 	Long: `Switch registry for package managers. If no registry is specified, 
-it will automatically test and select the fastest registry.`,
+it will automatically test all registries and select the one with the best score.
+
+The score combines the median latency and success rate over the history window,
+the sync lag against upstream and the download throughput, using the weights
+and per-registry bias from the [scoring] section in config.toml.
+
+With --offline, or when no registry host is reachable and [offline] auto_detect
+is enabled, no registry is probed. The registry is picked from the test results
+stored in the history within [offline] ttl, and the output is marked as stale.
+
+With --project the registries are benchmarked by resolving the dependencies of
+the given project instead, and the fastest one without failures is used.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 加载配置并创建管理器
-		_, manager, err := loadConfigAndCreateManager()
+		cfg, manager, err := loadConfigAndCreateManager()
 		if err != nil {
 			return err
 		}
@@ -49,106 +69,119 @@ it will automatically test and select the fastest registry.`,
 			}
 
 			// 设置为当前使用的 registry
-			if err := manager.Use(reg.Name); err != nil {
+			if err := useRegistry(cfg, manager, installedPMs, reg.Name); err != nil {
 				return fmt.Errorf("\n❌  Failed to set registry: %v", err)
 			}
 
 			// 输出成功信息
-			installedNames := []string{}
-			for _, pm := range installedPMs {
-				if pm.Installed {
-					installedNames = append(installedNames, pm.Name)
-				}
-			}
 			fmt.Printf("\n✨ Successfully Changed Package Manager(%s) to: %s\n",
-				strings.Join(installedNames, ", "),
+				strings.Join(installedNames(installedPMs), ", "),
 				style.Success.Sprint(reg.Name))
 			return nil
 		}
 
-		// 自动测试并选择最快的 registry
-		fmt.Println()
-		progressbar, err := pterm.DefaultProgressbar.
-			WithTotal(len(registries)).
-			WithTitle("Testing Registry Latency").
-			WithShowCount(true).
-			WithElapsedTimeRoundingFactor(time.Millisecond * 10).
-			WithMaxWidth(66).
-			Start()
-		if err != nil {
-			return fmt.Errorf("\n❌  Failed to create progress bar: %v", err)
+		// 命令行参数覆盖配置文件中的延迟测试选项
+		if err := useLatency.apply(cmd, cfg); err != nil {
+			return err
+		}
+		warnProxy(cfg, manager, nil, false)
+
+		// 获取最大同步延迟，命令行参数优先于配置文件
+		maxLag := cfg.Freshness.MaxLagDuration()
+		if useMaxLag != "" {
+			maxLag, err = config.ParseDuration(useMaxLag)
+			if err != nil {
+				return fmt.Errorf("\n❌  Invalid --max-lag value: %v", err)
+			}
+			if maxLag < 0 {
+				return fmt.Errorf("\n❌  Invalid --max-lag value: must not be negative")
+			}
 		}
 
-		// 测试每个 registry 的延迟
-		testResults := manager.Test()
+		// 监听 Ctrl-C，中断时取消剩余的测试
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
 
-		// 按延迟排序
-		sort.Slice(testResults, func(i, j int) bool {
-			// 如果有错误，排在后面
-			if testResults[i].Error != "" && testResults[j].Error == "" {
-				return false
+		// 模拟安装模式：按项目依赖的解析耗时选择
+		if useProject != "" {
+			if useOffline {
+				return fmt.Errorf("\n❌  --project cannot be used with --offline")
 			}
-			if testResults[i].Error == "" && testResults[j].Error != "" {
-				return true
+			names, err := secureNames(cfg, registries)
+			if err != nil {
+				return err
 			}
-			return testResults[i].Latency < testResults[j].Latency
-		})
+			return projectRegistry(ctx, cfg, manager, installedPMs, names)
+		}
 
-		// 创建表格渲染器
-		renderer := table.NewTableRenderer([]string{
-			"Name",
-			"Registry URL",
-			"Latency",
-		})
+		// 离线模式：不测试网络，根据有效期内的历史测试结果选择
+		if useOffline {
+			return offlineRegistry(cfg, manager, installedPMs, registries)
+		}
+		if cfg.Offline.AutoDetectEnabled() && !manager.DetectOnline(ctx) {
+			// 检测期间按下 Ctrl-C 时连接同样会失败，不能当作离线
+			if ctx.Err() != nil {
+				return fmt.Errorf("\n⚠️  Interrupted, registry not changed")
+			}
+			fmt.Println()
+			style.Warning.Println("⚠️  No registry host is reachable, switching to offline mode")
+			return offlineRegistry(cfg, manager, installedPMs, registries)
+		}
 
-		// 添加数据行
-		var fastestReg *registry.Info
-		for _, result := range testResults {
-			latency := "-"
-			if result.Error == "" {
-				latency = fmt.Sprintf("%dms", result.Latency.Milliseconds())
-				// 记录第一个成功的 registry 为最快的
-				if fastestReg == nil {
-					reg, _ := manager.Get(result.Name)
-					fastestReg = reg
-				}
+		// 竞速模式：在时间预算内选出第一个明显领先的 registry
+		if useRace {
+			names, err := secureNames(cfg, registries)
+			if err != nil {
+				return err
 			}
+			return raceRegistry(ctx, cfg, manager, installedPMs, names)
+		}
+
+		// 自动测试每个 registry 的延迟，结果实时刷新
+		fmt.Println()
+		testResults, err := streamTest(ctx, manager, len(registries))
+		if err != nil {
+			return err
+		}
 
-			renderer.MustAddRow([]string{
-				result.Name,
-				result.URL,
-				latency,
-			})
-			progressbar.Increment()
+		// 被中断时只显示已完成的结果，不切换 registry
+		if ctx.Err() != nil {
+			fmt.Println()
+			if err := latencyTable(testResults).Render(); err != nil {
+				return fmt.Errorf("\n❌  Failed to render table: %v", err)
+			}
+			return fmt.Errorf("\n⚠️  Interrupted after %d/%d registries, registry not changed", countCompleted(testResults), len(registries))
 		}
 
-		// 渲染表格
+		// 为在线的 registry 评分
+		selection := selectRegistry(ctx, cfg, manager, testResults, maxLag)
+		if ctx.Err() != nil {
+			return fmt.Errorf("\n⚠️  Interrupted, registry not changed")
+		}
+
+		// 渲染评分明细
 		fmt.Println()
-		if err := renderer.Render(); err != nil {
-			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		if err := renderSelection(selection); err != nil {
+			return err
 		}
 		fmt.Println()
 
 		// 如果没有可用的 registry
-		if fastestReg == nil {
+		if len(selection.ranked) == 0 {
 			return fmt.Errorf("\n❌  No available registry found")
 		}
+		best := selection.ranked[0]
 
-		// 设置最快的 registry 为当前使用的 registry
-		if err := manager.Use(fastestReg.Name); err != nil {
+		// 设置得分最高的 registry 为当前使用的 registry
+		if err := useRegistry(cfg, manager, installedPMs, best.Name); err != nil {
 			return fmt.Errorf("\n❌  Failed to set registry: %v", err)
 		}
 
 		// 输出成功信息
-		installedNames := []string{}
-		for _, pm := range installedPMs {
-			if pm.Installed {
-				installedNames = append(installedNames, pm.Name)
-			}
-		}
-		fmt.Printf("✨ Successfully Changed Package Manager(%s) to: %s\n",
-			strings.Join(installedNames, ", "),
-			style.Success.Sprint(fastestReg.Name))
+		fmt.Printf("✨ Successfully Changed Package Manager(%s) to: %s (score %.2f)\n",
+			strings.Join(installedNames(installedPMs), ", "),
+			style.Success.Sprint(best.Name),
+			best.Score)
 
 		return nil
 	},
@@ -156,6 +189,476 @@ it will automatically test and select the fastest registry.`,
 	SilenceErrors: true,
 }
 
+// selection 表示自动选择的结果
+type selection struct {
+	ranked   []scoring.Breakdown             // 参与评分的 registry，按得分降序
+	excluded []*registry.TestResult          // 因同步延迟过大被排除的 registry
+	insecure []*registry.TestResult          // 因使用明文 HTTP 被排除的 registry
+	offline  []*registry.TestResult          // 测试失败的 registry
+	current  map[string]*registry.TestResult // 本次测试结果
+	lags     map[string]*freshness.Result    // 同步延迟检测结果
+	checkLag bool                            // 是否检测了同步延迟
+	maxLag   time.Duration                   // 最大同步延迟
+}
+
+// streamTest 测试 registry 的延迟，并在每个结果到达时刷新进度与表格
+// 未指定名称时测试所有 registry
+func streamTest(ctx context.Context, manager registry.Manager, total int, names ...string) ([]*registry.TestResult, error) {
+	start := time.Now()
+	area, err := pterm.DefaultArea.WithRemoveWhenDone(true).Start(progressView(nil, total, start))
+	if err != nil {
+		return nil, fmt.Errorf("\n❌  Failed to create progress area: %v", err)
+	}
+
+	var results []*registry.TestResult
+	for result := range manager.TestStream(ctx, names...) {
+		results = append(results, result)
+		area.Update(progressView(results, total, start))
+	}
+
+	if err := area.Stop(); err != nil {
+		return nil, fmt.Errorf("\n❌  Failed to stop progress area: %v", err)
+	}
+	return results, nil
+}
+
+// progressView 渲染测试进度与当前结果
+func progressView(results []*registry.TestResult, total int, start time.Time) string {
+	view := fmt.Sprintf("Testing Registry Latency [%d/%d] %s\n",
+		len(results), total, time.Since(start).Round(10*time.Millisecond))
+	if len(results) == 0 {
+		return view
+	}
+
+	rendered, err := latencyTable(results).Srender()
+	if err != nil {
+		return view
+	}
+	return view + "\n" + rendered
+}
+
+// latencyTable 创建按状态与延迟排序的测试结果表格，不可用的 registry 排在后面
+func latencyTable(results []*registry.TestResult) *table.TableRenderer {
+	sorted := append([]*registry.TestResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Status != sorted[j].Status {
+			return sorted[i].Status < sorted[j].Status
+		}
+		return sorted[i].Latency < sorted[j].Latency
+	})
+
+	renderer := table.NewTableRenderer([]string{
+		"Name",
+		"Registry URL",
+		"Status",
+		"Latency",
+		"Detail",
+	})
+	for _, result := range sorted {
+		elapsed := "-"
+		if result.StatusCode != 0 || result.Reachable() {
+			elapsed = fmt.Sprintf("%dms", result.Latency.Milliseconds())
+		}
+		renderer.MustAddRow([]string{
+			result.Name,
+			result.URL,
+			formatStatus(result.Status),
+			elapsed,
+			formatDetail(result),
+		})
+	}
+	return renderer
+}
+
+// formatStatus 格式化测试状态
+func formatStatus(status latency.Status) string {
+	label := fmt.Sprintf("%s %s", status.Icon(), status)
+	switch status {
+	case latency.StatusOnline:
+		return style.Success.Sprint(label)
+	case latency.StatusDegraded, latency.StatusAuthRequired, latency.StatusCancelled:
+		return style.Warning.Sprint(label)
+	default:
+		return style.Error.Sprint(label)
+	}
+}
+
+// formatDetail 格式化错误类别与 HTTP 状态码
+func formatDetail(result *registry.TestResult) string {
+	switch {
+	case result.ErrorKind == latency.ErrorNone:
+		return "-"
+	case result.StatusCode != 0 && result.Status != latency.StatusDegraded:
+		return fmt.Sprintf("HTTP %d", result.StatusCode)
+	default:
+		return result.ErrorKind.String()
+	}
+}
+
+// countCompleted 统计未被取消的测试结果数量
+func countCompleted(results []*registry.TestResult) int {
+	count := 0
+	for _, result := range results {
+		if !result.Cancelled() {
+			count++
+		}
+	}
+	return count
+}
+
+// raceRegistry 以竞速模式选择并切换 registry
+// names 为空时参与所有 registry
+func raceRegistry(ctx context.Context, cfg *config.Config, manager registry.Manager, installedPMs []checker.PackageManager, names []string) error {
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Racing registries (budget %s)", useBudget))
+	if err != nil {
+		return fmt.Errorf("\n❌  Failed to create spinner: %v", err)
+	}
+	race := manager.Race(ctx, useBudget, names...)
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("\n❌  Failed to stop spinner: %v", err)
+	}
+
+	// 创建表格渲染器
+	renderer := table.NewTableRenderer([]string{
+		"Name",
+		"Registry URL",
+		"Samples",
+		"Mean",
+		"Failures",
+	})
+	for _, entry := range race.Entries {
+		mean := "-"
+		if entry.Samples > 0 {
+			mean = fmt.Sprintf("%dms", entry.Mean.Milliseconds())
+		}
+		row := []string{
+			entry.Name,
+			entry.URL,
+			fmt.Sprintf("%d", entry.Samples),
+			mean,
+			fmt.Sprintf("%d", entry.Failures),
+		}
+		if entry.Name == race.Winner {
+			for i := range row {
+				row[i] = style.Success.Sprint(row[i])
+			}
+		}
+		renderer.MustAddRow(row)
+	}
+
+	// 渲染表格
+	fmt.Println()
+	if err := renderer.Render(); err != nil {
+		return fmt.Errorf("\n❌  Failed to render table: %v", err)
+	}
+	fmt.Println()
+
+	// 被中断时不切换 registry
+	if ctx.Err() != nil {
+		return fmt.Errorf("\n⚠️  Interrupted, registry not changed")
+	}
+	if race.Winner == "" {
+		return fmt.Errorf("\n❌  No available registry found within %s", useBudget)
+	}
+
+	if race.Decided {
+		style.Info.Printf("🏁 %s is clearly ahead after %s\n", race.Winner, race.Elapsed.Round(time.Millisecond))
+	} else {
+		style.Warning.Printf("⏱️  Budget of %s used up, picking current leader %s\n", useBudget, race.Winner)
+	}
+
+	// 切换到胜出的 registry
+	if err := useRegistry(cfg, manager, installedPMs, race.Winner); err != nil {
+		return fmt.Errorf("\n❌  Failed to set registry: %v", err)
+	}
+
+	fmt.Printf("✨ Successfully Changed Package Manager(%s) to: %s\n",
+		strings.Join(installedNames(installedPMs), ", "),
+		style.Success.Sprint(race.Winner))
+	return nil
+}
+
+// projectRegistry 在 registry 上模拟安装项目的依赖，切换到最快且没有失败的 registry
+// names 为空时测试所有 registry
+func projectRegistry(ctx context.Context, cfg *config.Config, manager registry.Manager, installedPMs []checker.PackageManager, names []string) error {
+	project, err := loadBenchProject(useProject)
+	if err != nil {
+		return err
+	}
+
+	total := len(names)
+	if total == 0 {
+		total = len(manager.List())
+	}
+	results, err := runBench(ctx, manager, project, bench.DefaultConcurrency, total, names...)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+
+	// 被中断时不切换 registry
+	if ctx.Err() != nil {
+		return fmt.Errorf("\n⚠️  Interrupted after %d/%d registries, registry not changed", len(results), total)
+	}
+	best := results[0]
+	if !best.Passed() {
+		return fmt.Errorf("\n❌  No registry resolved all dependencies of %s", project.Source)
+	}
+
+	// 设置最快的 registry 为当前使用的 registry
+	if err := useRegistry(cfg, manager, installedPMs, best.Name); err != nil {
+		return fmt.Errorf("\n❌  Failed to set registry: %v", err)
+	}
+
+	// 输出成功信息
+	fmt.Printf("✨ Successfully Changed Package Manager(%s) to: %s (%s for %s)\n",
+		strings.Join(installedNames(installedPMs), ", "),
+		style.Success.Sprint(best.Name),
+		best.Duration.Round(time.Millisecond),
+		project.Source)
+	return nil
+}
+
+// selectRegistry 根据本次测试结果与历史记录为 registry 评分
+func selectRegistry(ctx context.Context, cfg *config.Config, manager registry.Manager, testResults []*registry.TestResult, maxLag time.Duration) *selection {
+	sel := &selection{
+		current: make(map[string]*registry.TestResult),
+		lags:    make(map[string]*freshness.Result),
+		maxLag:  maxLag,
+	}
+
+	var online []string
+	for _, result := range testResults {
+		sel.current[result.Name] = result
+		if result.Reachable() {
+			online = append(online, result.Name)
+		} else {
+			sel.offline = append(sel.offline, result)
+		}
+	}
+
+	// 设置了最大同步延迟或同步延迟权重时，检测在线 registry 的同步延迟
+	sel.checkLag = len(online) > 0 && (maxLag > 0 || cfg.Scoring.Weights.Freshness > 0)
+	if sel.checkLag {
+		for _, result := range manager.Freshness(ctx, online...) {
+			sel.lags[result.Name] = result
+		}
+	}
+
+	// 读取评分窗口内的历史记录，本次测试结果已包含在内
+	records := make(map[string][]history.Record)
+	if store, err := history.NewStoreFromConfig(cfg); err == nil {
+		filter := history.Filter{Since: time.Now().Add(-cfg.Scoring.WindowDuration())}
+		if all, err := store.Query(filter); err == nil {
+			for _, record := range all {
+				records[record.Registry] = append(records[record.Registry], record)
+			}
+		}
+	}
+
+	// 构建评分依据
+	var inputs []scoring.Input
+	for _, name := range online {
+		result := sel.current[name]
+
+		// 配置要求时，明文 HTTP 的 registry 不参与自动选择
+		if cfg.Security.RefuseInsecure && registry.IsInsecureURL(result.URL) {
+			sel.insecure = append(sel.insecure, result)
+			continue
+		}
+
+		// 同步延迟超过阈值的镜像不参与自动选择
+		lag, hasLag := sel.lags[name]
+		if hasLag && lag.Exceeds(maxLag) {
+			sel.excluded = append(sel.excluded, result)
+			continue
+		}
+
+		// 历史记录写入失败时，退回到只使用本次测试结果
+		input := scoring.InputFromHistory(name, records[name])
+		if len(records[name]) == 0 {
+			input.Latency = result.Latency
+			input.SuccessRate = 1
+			input.Throughput = result.Throughput
+		}
+		input.Degraded = result.Status == latency.StatusDegraded
+		if hasLag {
+			input.HasLag = true
+			input.Lag = lag.Lag
+			input.LagFailed = lag.Error != ""
+		}
+		inputs = append(inputs, input)
+	}
+
+	sel.ranked = scoring.PolicyFromConfig(cfg).Score(inputs)
+
+	// 失败的 registry 按名称排序
+	sort.Slice(sel.offline, func(i, j int) bool {
+		return sel.offline[i].Name < sel.offline[j].Name
+	})
+
+	return sel
+}
+
+// renderSelection 以表格形式显示评分明细
+func renderSelection(sel *selection) error {
+	headers := []string{"Name", "Registry URL", "Latency", "Median", "Success"}
+	if sel.checkLag {
+		headers = append(headers, "Sync Lag")
+	}
+	headers = append(headers, "Throughput", "Bias", "Score")
+	renderer := table.NewTableRenderer(headers)
+
+	// 添加参与评分的 registry
+	for i, b := range sel.ranked {
+		result := sel.current[b.Name]
+
+		row := []string{
+			b.Name,
+			result.URL,
+			fmt.Sprintf("%dms", result.Latency.Milliseconds()),
+			fmt.Sprintf("%dms", b.Latency.Milliseconds()),
+			fmt.Sprintf("%.0f%%", b.SuccessRate*100),
+		}
+		if sel.checkLag {
+			row = append(row, formatLag(sel.lags[b.Name]))
+		}
+		row = append(row,
+			formatThroughput(b.Throughput),
+			formatBias(b.Bias),
+			fmt.Sprintf("%.2f", b.Score),
+		)
+
+		// 高亮得分最高的 registry
+		if i == 0 {
+			for j := range row {
+				row[j] = style.Success.Sprint(row[j])
+			}
+		}
+		renderer.MustAddRow(row)
+	}
+
+	// 添加因同步延迟被排除的 registry
+	for _, result := range sel.excluded {
+		row := []string{
+			result.Name,
+			result.URL,
+			fmt.Sprintf("%dms", result.Latency.Milliseconds()),
+			"-",
+			"-",
+			style.Error.Sprint(formatLag(sel.lags[result.Name])),
+			"-",
+			"-",
+			style.Error.Sprintf("> %s", sel.maxLag),
+		}
+		renderer.MustAddRow(row)
+	}
+
+	// 添加因使用明文 HTTP 被排除的 registry
+	for _, result := range sel.insecure {
+		row := []string{result.Name, result.URL, fmt.Sprintf("%dms", result.Latency.Milliseconds()), "-", "-"}
+		if sel.checkLag {
+			row = append(row, "-")
+		}
+		row = append(row, "-", "-", style.Warning.Sprint("🔓 insecure"))
+		renderer.MustAddRow(row)
+	}
+
+	// 添加测试失败的 registry
+	for _, result := range sel.offline {
+		row := []string{result.Name, result.URL, "-", "-", "-"}
+		if sel.checkLag {
+			row = append(row, "-")
+		}
+		row = append(row, "-", "-", formatStatus(result.Status))
+		renderer.MustAddRow(row)
+	}
+
+	if err := renderer.Render(); err != nil {
+		return fmt.Errorf("\n❌  Failed to render table: %v", err)
+	}
+	return nil
+}
+
+// formatThroughput 格式化下载速度
+func formatThroughput(bps float64) string {
+	if bps <= 0 {
+		return "-"
+	}
+	if bps >= 1<<20 {
+		return fmt.Sprintf("%.1f MB/s", bps/(1<<20))
+	}
+	return fmt.Sprintf("%.0f KB/s", bps/(1<<10))
+}
+
+// formatBias 格式化 registry 偏好
+func formatBias(bias string) string {
+	switch bias {
+	case config.BiasPrefer:
+		return style.Success.Sprint("prefer")
+	case config.BiasAvoid:
+		return style.Warning.Sprint("avoid")
+	default:
+		return "-"
+	}
+}
+
+// secureNames 返回允许切换的 registry 名称
+// 未开启 security.refuse_insecure 时返回 nil，表示所有 registry；所有 registry 都使用明文 HTTP 时返回错误
+func secureNames(cfg *config.Config, registries []*registry.Info) ([]string, error) {
+	if !cfg.Security.RefuseInsecure {
+		return nil, nil
+	}
+
+	names := []string{}
+	for _, reg := range registries {
+		if !registry.IsInsecureURL(reg.URL) {
+			names = append(names, reg.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("\n❌  No secure registry available, every registry uses plain HTTP and security.refuse_insecure is on")
+	}
+	return names, nil
+}
+
+// installedNames 返回已安装的包管理器名称
+func installedNames(pms []checker.PackageManager) []string {
+	names := []string{}
+	for _, pm := range pms {
+		if pm.Installed {
+			names = append(names, pm.Name)
+		}
+	}
+	return names
+}
+
+// useRegistry 自动备份包管理器的配置文件后切换 registry
+func useRegistry(cfg *config.Config, manager registry.Manager, installedPMs []checker.PackageManager, name string) error {
+	autoBackup(cfg, "use", installedNames(installedPMs)...)
+	return manager.Use(name)
+}
+
+// 定义全局变量
+var (
+	useMaxLag  string        // 自动选择时可接受的最大同步延迟
+	useRace    bool          // 是否使用竞速模式
+	useBudget  time.Duration // 竞速模式的时间预算
+	useOffline bool          // 是否使用离线模式
+	useProject string        // 按该项目的依赖模拟安装选择 registry
+
+	useLatency latencyFlags // 延迟测试选项
+)
+
 func init() {
 	rootCmd.AddCommand(useCmd)
+
+	// 添加命令行参数
+	flags := useCmd.Flags()
+	flags.StringVar(&useMaxLag, "max-lag", "", "Exclude mirrors whose sync lag exceeds this duration when auto-selecting (e.g. 30m, 2h, 1d)")
+	flags.BoolVar(&useRace, "race", false, "Probe all registries at once and switch to the first one that is clearly ahead")
+	flags.DurationVar(&useBudget, "budget", latency.DefaultRaceOptions().Budget, "Time budget for --race, the current leader is used when it runs out")
+	flags.BoolVar(&useOffline, "offline", false, "Skip network probes and pick a registry from cached test results within [offline] ttl")
+	flags.StringVar(&useProject, "project", "", "Pick the registry that resolves this project's dependencies fastest (see 'nrmgo bench')")
+	useLatency.register(useCmd)
 }
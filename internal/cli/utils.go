@@ -3,9 +3,14 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
 
 	"nrmgo/internal/config"
 	"nrmgo/internal/registry"
+	"nrmgo/internal/style"
 )
 
 // loadConfigAndCreateManager 加载配置并创建 registry 管理器
@@ -25,3 +30,93 @@ func loadConfigAndCreateManager() (*config.Config, registry.Manager, error) {
 
 	return cfg, manager, nil
 }
+
+// latencyFlags 延迟测试相关的命令行参数，设置后覆盖配置文件中的 [latency]
+type latencyFlags struct {
+	timeout     time.Duration
+	maxLatency  time.Duration
+	samples     int
+	concurrency int
+	userAgent   string
+	proxy       string
+	resolve     []string
+	dnsServer   string
+	family      string
+}
+
+// register 为命令注册延迟测试参数
+func (f *latencyFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.DurationVar(&f.timeout, "timeout", 0, "Request timeout per registry (overrides latency.timeout)")
+	flags.DurationVar(&f.maxLatency, "max-latency", 0, "Mark registries slower than this as degraded, 0 disables (overrides latency.max_latency)")
+	flags.IntVar(&f.samples, "samples", 0, "Number of probes per registry, the median latency is used (overrides latency.samples)")
+	flags.IntVar(&f.concurrency, "concurrency", 0, "Number of registries tested in parallel (overrides latency.concurrency)")
+	flags.StringVar(&f.userAgent, "user-agent", "", "User-Agent header sent with probes (overrides latency.user_agent)")
+	flags.StringVar(&f.proxy, "proxy", "", `Proxy url (http, https or socks5), "direct" disables the proxy from .npmrc and environment`)
+	flags.StringArrayVar(&f.resolve, "resolve", nil, "Resolve host to ip like curl, host:ip or host:port:ip (repeatable)")
+	flags.StringVar(&f.dnsServer, "dns", "", "DNS server used to resolve registries, ip or ip:port")
+	flags.StringVar(&f.family, "family", "", "Address family used to connect: 4 or 6, test also accepts both (overrides latency.family)")
+}
+
+// apply 将设置过的参数写入配置并重新验证
+func (f *latencyFlags) apply(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+	if flags.Changed("timeout") {
+		cfg.Latency.Timeout = f.timeout.String()
+	}
+	if flags.Changed("max-latency") {
+		cfg.Latency.MaxLatency = f.maxLatency.String()
+	}
+	if flags.Changed("samples") {
+		cfg.Latency.Samples = f.samples
+	}
+	if flags.Changed("concurrency") {
+		cfg.Latency.Concurrency = f.concurrency
+	}
+	if flags.Changed("user-agent") {
+		cfg.Latency.UserAgent = f.userAgent
+	}
+	if flags.Changed("proxy") {
+		cfg.Latency.Proxy = f.proxy
+	}
+	if flags.Changed("resolve") {
+		cfg.Latency.Resolve = f.resolve
+	}
+	if flags.Changed("dns") {
+		cfg.Latency.DNSServer = f.dnsServer
+	}
+	if flags.Changed("family") {
+		cfg.Latency.Family = f.family
+	}
+
+	if err := config.ValidateLatency(&cfg.Latency); err != nil {
+		return fmt.Errorf("\n❌  Invalid latency option: %v", err)
+	}
+	return nil
+}
+
+// warnProxy 提示代理对测试的影响：
+// 指定地址族或对比 IPv4 与 IPv6 时绕过代理直接连接，否则通过代理访问的 registry 由代理解析主机名，--resolve 与 --dns 对它们不生效
+func warnProxy(cfg *config.Config, manager registry.Manager, names []string, compare bool) {
+	bypass := compare || cfg.Latency.Family != ""
+	if !bypass && len(cfg.Latency.Resolve) == 0 && cfg.Latency.DNSServer == "" {
+		return
+	}
+	proxied := manager.Proxied(names...)
+	if len(proxied) == 0 {
+		return
+	}
+
+	proxiedNames := make([]string, len(proxied))
+	for i, reg := range proxied {
+		proxiedNames[i] = reg.Name
+	}
+	fmt.Println()
+	if bypass {
+		style.Warning.Printf("⚠️  Bypassing the proxy to test the address family, a proxy would only show its own: %s\n",
+			strings.Join(proxiedNames, ", "))
+		return
+	}
+	style.Warning.Printf("⚠️  --resolve and --dns do not apply to registries reached through the proxy, the proxy resolves their host names: %s\n",
+		strings.Join(proxiedNames, ", "))
+}
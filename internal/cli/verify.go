@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"nrmgo/internal/style"
+	"nrmgo/internal/table"
+	"nrmgo/internal/verify"
+)
+
+// verifyCmd 校验各镜像的 tarball 完整性
+var verifyCmd = &cobra.Command{
+	Use:   "verify <pkg>@<version> [registry...]",
+	Short: "Verify tarball integrity of a package across registries",
+	Long: `Verify tarball integrity of a package across registries.
+
+For each registry the version metadata and tarball are fetched. The
+dist.integrity and dist.shasum are compared with the upstream registry
+([freshness] upstream in config.toml), and the downloaded bytes are checked
+against both the registry's own hashes and the upstream hashes.`,
+	Example: `  # Verify a package on all registries
+  nrmgo verify lodash@4.17.21
+
+  # Verify a scoped package on specific registries
+  nrmgo verify @types/node@20.11.0 taobao tencent`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 解析包描述
+		spec, err := verify.ParseSpec(args[0])
+		if err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+
+		// 加载配置并创建管理器
+		_, manager, err := loadConfigAndCreateManager()
+		if err != nil {
+			return err
+		}
+
+		// 检查指定的 registry 是否存在
+		names := args[1:]
+		for _, name := range names {
+			if _, ok := manager.Get(name); !ok {
+				return fmt.Errorf("\n❌  Registry '%s' not found", name)
+			}
+		}
+
+		fmt.Printf("\n🔍 Verifying %s ...\n", style.Info.Sprint(spec.String()))
+
+		results, err := manager.Verify(cmd.Context(), spec, names...)
+		if err != nil {
+			return fmt.Errorf("\n❌  %v", err)
+		}
+
+		// 按名称排序
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Name < results[j].Name
+		})
+
+		// 创建表格渲染器
+		renderer := table.NewTableRenderer([]string{
+			"Name",
+			"Metadata",
+			"Integrity",
+			"Shasum",
+			"Upstream",
+			"Size",
+			"Result",
+		})
+
+		var failed []string
+		for _, result := range results {
+			if !result.Passed() {
+				failed = append(failed, result.Name)
+			}
+
+			if result.Error != "" {
+				renderer.MustAddRow([]string{
+					result.Name,
+					"-",
+					"-",
+					"-",
+					"-",
+					"-",
+					style.Error.Sprintf("❌ %s", result.Error),
+				})
+				continue
+			}
+
+			verdict := style.Success.Sprint("✅ pass")
+			if !result.Passed() {
+				verdict = style.Error.Sprint("❌ fail")
+			}
+
+			renderer.MustAddRow([]string{
+				result.Name,
+				checkMark(result.MetadataMatch),
+				checkMark(result.IntegrityOK),
+				checkMark(result.ShasumOK),
+				checkMark(result.UpstreamOK),
+				fmt.Sprintf("%d B", result.Size),
+				verdict,
+			})
+		}
+
+		// 渲染表格
+		fmt.Println()
+		if err := renderer.Render(); err != nil {
+			return fmt.Errorf("\n❌  Failed to render table: %v", err)
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("\n❌  Verification failed on: %s", strings.Join(failed, ", "))
+		}
+
+		fmt.Printf("\n✨ %s verified on %d registries\n", style.Success.Sprint(spec.String()), len(results))
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// checkMark 将校验结果转换为图标
+func checkMark(ok bool) string {
+	if ok {
+		return style.Success.Sprint("✅")
+	}
+	return style.Error.Sprint("❌")
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
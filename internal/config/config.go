@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
 const (
-	configFileName = "config.toml"
+	// ConfigFileName 配置文件名，保存在数据目录下
+	ConfigFileName = "config.toml"
 )
 
 //go:embed config.toml
@@ -18,7 +20,17 @@ var defaultTemplate string
 
 // GetConfigPath 获取配置文件路径
 func GetConfigPath(execPath string) string {
-	return filepath.Join(filepath.Dir(execPath), configFileName)
+	return filepath.Join(filepath.Dir(execPath), ConfigFileName)
+}
+
+// GetDataDir 获取 nrmgo 的数据目录，即程序所在目录
+// 配置文件、备份与历史记录都保存在该目录下
+func GetDataDir() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	return filepath.Dir(execPath), nil
 }
 
 // GetDefaultTemplate 获取默认的配置文件内容
@@ -33,7 +45,381 @@ type Config struct {
 
 	// MaxConcurrentRequests HTTP 并发请求数，用于延迟测试
 	// 默认值：5，建议范围：1-10
-	MaxConcurrentRequests int `toml:"max_concurrent_requests"`
+	// 保留用于兼容旧配置，latency.concurrency 优先
+	MaxConcurrentRequests int `toml:"max_concurrent_requests,omitempty"`
+
+	// Latency 延迟测试配置
+	Latency LatencyConfig `toml:"latency,omitempty"`
+
+	// Freshness 镜像同步延迟检测配置
+	Freshness FreshnessConfig `toml:"freshness,omitempty"`
+
+	// History 延迟测试历史记录配置
+	History HistoryConfig `toml:"history,omitempty"`
+
+	// Scoring 自动选择 registry 时的评分策略
+	Scoring ScoringConfig `toml:"scoring,omitempty"`
+
+	// Offline 离线模式配置
+	Offline OfflineConfig `toml:"offline,omitempty"`
+
+	// Security 传输安全配置
+	Security SecurityConfig `toml:"security,omitempty"`
+
+	// Backup 配置文件备份
+	Backup BackupConfig `toml:"backup,omitempty"`
+
+	// Probes 覆盖内置 registry 的探测方式，自定义 registry 直接在自身配置中设置
+	Probes map[string]*ProbeConfig `toml:"probes,omitempty"`
+}
+
+// ProbeConfig 延迟测试的探测方式
+type ProbeConfig struct {
+	// Probe 探测方式：ping、packument:<name>、root、tcp 或以 / 开头的自定义路径
+	Probe string `toml:"probe"`
+
+	// ExpectStatus 视为成功的 HTTP 状态码，为空时接受所有 2xx
+	ExpectStatus []int `toml:"expect_status,omitempty"`
+}
+
+// OfflineConfig 离线模式配置
+type OfflineConfig struct {
+	// TTL 离线模式下可使用的历史测试结果的最长时间，例如 "24h"
+	// 默认值："24h"
+	TTL string `toml:"ttl,omitempty"`
+
+	// AutoDetect 自动选择前检测网络，无法访问任何 registry 时自动进入离线模式
+	// 默认值：true
+	AutoDetect *bool `toml:"auto_detect,omitempty"`
+}
+
+// TTLDuration 返回解析后的历史测试结果有效期
+func (o OfflineConfig) TTLDuration() time.Duration {
+	d, err := ParseDuration(o.TTL)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// AutoDetectEnabled 判断是否开启离线自动检测
+func (o OfflineConfig) AutoDetectEnabled() bool {
+	return o.AutoDetect == nil || *o.AutoDetect
+}
+
+// BackupConfig 配置文件备份
+type BackupConfig struct {
+	// Auto 修改配置文件前自动创建备份
+	// 默认值：true
+	Auto *bool `toml:"auto,omitempty"`
+
+	// AutoKeep 最多保留的自动备份数量，0 表示不限制数量，手动创建的备份不受影响
+	// 默认值：20
+	AutoKeep *int `toml:"auto_keep,omitempty"`
+
+	// AutoMaxAge 自动备份的最长保留时间，例如 "30d"
+	// 默认值："30d"
+	AutoMaxAge string `toml:"auto_max_age,omitempty"`
+
+	// Include 额外备份的文件，语法同 filepath.Glob，支持 ~ 表示用户目录
+	Include []string `toml:"include,omitempty"`
+
+	// Exclude 不备份的文件，不含路径分隔符的模式匹配文件名，否则匹配完整路径
+	Exclude []string `toml:"exclude,omitempty"`
+
+	// Store 备份的存储，"dir" 保存在数据目录的 backups 下，"git" 提交到本地 git 仓库
+	// 默认值："dir"
+	Store string `toml:"store,omitempty"`
+
+	// GitRepo git 存储使用的仓库，不是 git 仓库时自动初始化，相对路径相对于数据目录
+	// 默认值："backups-git"
+	GitRepo string `toml:"git_repo,omitempty"`
+
+	// Format 备份的保存方式，"dir" 保存为目录，"tar.gz" 保存为单个归档文件，git 存储只支持 "dir"
+	// 默认值："dir"
+	Format string `toml:"format,omitempty"`
+
+	// Encryption 归档备份的加密方式，"passphrase" 或 "x25519"，为空时不加密，需要 format = "tar.gz"
+	Encryption string `toml:"encryption,omitempty"`
+
+	// Recipients x25519 加密使用的公钥（age1...），为空时使用 IdentityFile 中私钥对应的公钥
+	Recipients []string `toml:"recipients,omitempty"`
+
+	// IdentityFile 解密 x25519 加密备份的私钥文件（AGE-SECRET-KEY-1...），可以由 nrmgo backup keygen 生成
+	IdentityFile string `toml:"identity_file,omitempty"`
+
+	// Retention nrmgo backup prune 默认使用的保留策略
+	Retention RetentionConfig `toml:"retention,omitempty"`
+}
+
+// RetentionConfig 备份的保留策略，固定的备份不会被删除
+// 没有设置任何规则时使用默认策略
+type RetentionConfig struct {
+	// KeepLast 保留最新的 N 个备份
+	// 默认值：10
+	KeepLast int `toml:"keep_last,omitempty"`
+
+	// KeepDaily 保留最近 N 天每天最新的一个备份
+	// 默认值：7
+	KeepDaily int `toml:"keep_daily,omitempty"`
+
+	// KeepWeekly 保留最近 N 周每周最新的一个备份
+	// 默认值：4
+	KeepWeekly int `toml:"keep_weekly,omitempty"`
+
+	// KeepMonthly 保留最近 N 个月每月最新的一个备份
+	// 默认值：6
+	KeepMonthly int `toml:"keep_monthly,omitempty"`
+
+	// MaxAge 删除早于该时间的备份，即使被以上规则保留，例如 "365d"，为空时不限制
+	MaxAge string `toml:"max_age,omitempty"`
+
+	// MaxSize 备份的总大小上限，超出时从最旧的备份开始删除，例如 "100MB"，为空时不限制
+	MaxSize string `toml:"max_size,omitempty"`
+}
+
+// IsZero 判断是否没有设置任何保留规则
+func (r RetentionConfig) IsZero() bool {
+	return r == RetentionConfig{}
+}
+
+// MaxAgeDuration 返回解析后的最长保留时间，未设置时返回 0
+func (r RetentionConfig) MaxAgeDuration() time.Duration {
+	d, err := ParseDuration(r.MaxAge)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// MaxSizeBytes 返回解析后的总大小上限，未设置时返回 0
+func (r RetentionConfig) MaxSizeBytes() int64 {
+	n, err := ParseSize(r.MaxSize)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// AutoEnabled 判断是否在修改配置文件前自动备份
+func (b BackupConfig) AutoEnabled() bool {
+	return b.Auto == nil || *b.Auto
+}
+
+// AutoKeepCount 返回最多保留的自动备份数量，0 表示不限制
+func (b BackupConfig) AutoKeepCount() int {
+	if b.AutoKeep == nil {
+		return DefaultBackupAutoKeep
+	}
+	return *b.AutoKeep
+}
+
+// AutoMaxAgeDuration 返回解析后的自动备份最长保留时间
+func (b BackupConfig) AutoMaxAgeDuration() time.Duration {
+	d, err := ParseDuration(b.AutoMaxAge)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// SecurityConfig 传输安全配置
+type SecurityConfig struct {
+	// RefuseInsecure 拒绝切换到使用明文 HTTP 的 registry
+	// 默认值：false
+	RefuseInsecure bool `toml:"refuse_insecure,omitempty"`
+
+	// CertExpiryDays 证书在该天数内过期时 audit-registries 给出警告
+	// 默认值：14
+	CertExpiryDays int `toml:"cert_expiry_days,omitempty"`
+}
+
+// LatencyConfig 延迟测试配置
+type LatencyConfig struct {
+	// Timeout 单次请求的超时时间
+	// 默认值："5s"
+	Timeout string `toml:"timeout,omitempty"`
+
+	// MaxLatency 最大可接受延迟，超过时 registry 视为降级，"0s" 表示不限制
+	// 默认值："3s"
+	MaxLatency string `toml:"max_latency,omitempty"`
+
+	// Samples 每个 registry 的测试次数，取延迟的中位数
+	// 默认值：1
+	Samples int `toml:"samples,omitempty"`
+
+	// UserAgent 测试请求使用的 User-Agent
+	// 默认值："NRMG-Latency-Tester/1.0"
+	UserAgent string `toml:"user_agent,omitempty"`
+
+	// Concurrency 并发测试数量，为 0 时使用 max_concurrent_requests
+	Concurrency int `toml:"concurrency,omitempty"`
+
+	// Proxy 测试使用的代理，覆盖 .npmrc 与环境变量中的代理设置
+	// 为空时与 npm 一致，"direct" 表示不使用代理
+	Proxy string `toml:"proxy,omitempty"`
+
+	// Resolve 自定义主机解析，格式同 curl 的 --resolve：host:ip 或 host:port:ip
+	Resolve []string `toml:"resolve,omitempty"`
+
+	// DNSServer 自定义 DNS 服务器，格式为 ip 或 ip:port
+	DNSServer string `toml:"dns_server,omitempty"`
+
+	// Family 连接 registry 使用的地址族："4"、"6"，为空时由系统决定；指定时绕过代理直接连接
+	Family string `toml:"family,omitempty"`
+
+	// Timeouts 每个 registry 的超时时间，覆盖 Timeout
+	Timeouts map[string]string `toml:"timeouts,omitempty"`
+}
+
+// TimeoutDuration 返回解析后的请求超时时间
+func (l LatencyConfig) TimeoutDuration() time.Duration {
+	d, err := ParseDuration(l.Timeout)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// MaxLatencyDuration 返回解析后的最大可接受延迟
+func (l LatencyConfig) MaxLatencyDuration() time.Duration {
+	d, err := ParseDuration(l.MaxLatency)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// LatencyConcurrency 返回延迟测试的并发数量，latency.concurrency 优先于 max_concurrent_requests
+func (c *Config) LatencyConcurrency() int {
+	if c.Latency.Concurrency > 0 {
+		return c.Latency.Concurrency
+	}
+	return c.MaxConcurrentRequests
+}
+
+// RenameRegistrySettings 将按 registry 名称保存的偏好、超时与探测方式移到新名称，newName 为空时删除
+func (c *Config) RenameRegistrySettings(oldName, newName string) {
+	renameKey(c.Scoring.Bias, oldName, newName)
+	renameKey(c.Latency.Timeouts, oldName, newName)
+	renameKey(c.Probes, oldName, newName)
+}
+
+// renameKey 将 map 中的键移到新名称，newName 为空时删除
+func renameKey[V any](m map[string]V, oldName, newName string) {
+	value, ok := m[oldName]
+	if !ok {
+		return
+	}
+	delete(m, oldName)
+	if newName != "" {
+		m[newName] = value
+	}
+}
+
+// TimeoutFor 返回指定 registry 的超时时间，未单独配置时返回 0
+func (l LatencyConfig) TimeoutFor(name string) time.Duration {
+	d, err := ParseDuration(l.Timeouts[name])
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// ScoringConfig 自动选择 registry 时的评分策略
+type ScoringConfig struct {
+	// Window 参与评分的历史记录时间范围，例如 "7d"
+	// 默认值："7d"
+	Window string `toml:"window,omitempty"`
+
+	// Weights 各项指标的权重，全部为 0 时使用默认权重
+	Weights ScoringWeights `toml:"weights,omitempty"`
+
+	// BiasWeight prefer/avoid 对总分的加减值，设置为 0 时忽略 prefer/avoid
+	// 默认值：0.2
+	BiasWeight *float64 `toml:"bias_weight,omitempty"`
+
+	// Bias 每个 registry 的偏好，取值为 "prefer" 或 "avoid"
+	Bias map[string]string `toml:"bias,omitempty"`
+}
+
+// BiasWeightValue 返回 prefer/avoid 的加减值，未设置时使用默认值
+func (s ScoringConfig) BiasWeightValue() float64 {
+	if s.BiasWeight == nil {
+		return DefaultBiasWeight
+	}
+	return *s.BiasWeight
+}
+
+// ScoringWeights 评分指标权重
+type ScoringWeights struct {
+	// Latency 延迟中位数的权重
+	Latency float64 `toml:"latency,omitempty"`
+
+	// SuccessRate 历史成功率的权重
+	SuccessRate float64 `toml:"success_rate,omitempty"`
+
+	// Freshness 同步延迟的权重，大于 0 时会额外检测同步延迟
+	Freshness float64 `toml:"freshness,omitempty"`
+
+	// Throughput 下载速度的权重
+	Throughput float64 `toml:"throughput,omitempty"`
+}
+
+// WindowDuration 返回解析后的历史记录时间范围
+func (s ScoringConfig) WindowDuration() time.Duration {
+	d, err := ParseDuration(s.Window)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// HistoryConfig 延迟测试历史记录配置
+type HistoryConfig struct {
+	// MaxAge 历史记录的最长保留时间，例如 "30d"
+	// 默认值："30d"
+	MaxAge string `toml:"max_age,omitempty"`
+
+	// MaxEntries 最多保留的记录条数
+	// 默认值：10000
+	MaxEntries int `toml:"max_entries,omitempty"`
+}
+
+// MaxAgeDuration 返回解析后的最长保留时间
+func (h HistoryConfig) MaxAgeDuration() time.Duration {
+	d, err := ParseDuration(h.MaxAge)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// FreshnessConfig 镜像同步延迟（freshness）检测配置
+type FreshnessConfig struct {
+	// Upstream 作为基准的上游 registry
+	// 默认值：https://registry.npmjs.org/
+	Upstream string `toml:"upstream,omitempty"`
+
+	// SentinelPackages 用于比较的哨兵包，应选择发布频繁的包
+	// 默认值：["npm", "pnpm"]
+	SentinelPackages []string `toml:"sentinel_packages,omitempty"`
+
+	// MaxLag 自动选择时可接受的最大同步延迟，例如 "30m"、"2h"、"1d"
+	// 为空表示不检测同步延迟
+	MaxLag string `toml:"max_lag,omitempty"`
+}
+
+// MaxLagDuration 返回解析后的最大同步延迟，未配置时返回 0
+func (f FreshnessConfig) MaxLagDuration() time.Duration {
+	if f.MaxLag == "" {
+		return 0
+	}
+	d, err := ParseDuration(f.MaxLag)
+	if err != nil {
+		return 0
+	}
+	return d
 }
 
 // Registry 注册表信息
@@ -46,6 +432,12 @@ type Registry struct {
 
 	// Description registry 的描述信息（可选）
 	Description string `toml:"description,omitempty"`
+
+	// Probe 延迟测试的探测方式（可选），格式同 ProbeConfig.Probe
+	Probe string `toml:"probe,omitempty"`
+
+	// ExpectStatus 视为成功的 HTTP 状态码（可选）
+	ExpectStatus []int `toml:"expect_status,omitempty"`
 }
 
 // LoadConfig 加载配置
@@ -85,6 +477,8 @@ func LoadConfig() (*Config, error) {
 }
 
 // SaveConfig 保存配置
+// 只更新 registry 及其评分偏好、超时与探测方式，其他设置保持配置文件中的原样，
+// ValidateConfig 填充的默认值不会写入文件
 func SaveConfig(cfg *Config) error {
 	// 验证配置
 	if err := ValidateConfig(cfg); err != nil {
@@ -98,8 +492,22 @@ func SaveConfig(cfg *Config) error {
 	}
 	configPath := GetConfigPath(execPath)
 
+	// 重新读取配置文件，不填充默认值
+	file := &Config{}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := toml.Unmarshal(data, file); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	file.CustomRegistries = cfg.CustomRegistries
+	file.Scoring.Bias = cfg.Scoring.Bias
+	file.Latency.Timeouts = cfg.Latency.Timeouts
+	file.Probes = cfg.Probes
+
 	// 使用 toml.Marshal 序列化配置
-	data, err := toml.Marshal(cfg)
+	data, err = toml.Marshal(file)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ProxyDirect 表示不使用代理
+const ProxyDirect = "direct"
+
+// ParseResolve 解析 curl 风格的 --resolve 配置，返回主机到 IP 的映射
+// 支持 host:ip 与 host:port:ip 两种格式，IPv6 地址可以用方括号包裹
+// host:port:ip 格式的键为 host:port，只对该端口生效
+func ParseResolve(entries []string) (map[string]string, error) {
+	resolve := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		host, rest, ok := strings.Cut(entry, ":")
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid resolve entry %q, expected host:ip or host:port:ip", entry)
+		}
+
+		// host:ip
+		if ip := parseIP(rest); ip != "" {
+			resolve[host] = ip
+			continue
+		}
+
+		// host:port:ip
+		port, addr, ok := strings.Cut(rest, ":")
+		if n, err := strconv.Atoi(port); ok && err == nil && n > 0 && n < 65536 {
+			if ip := parseIP(addr); ip != "" {
+				resolve[net.JoinHostPort(host, port)] = ip
+				continue
+			}
+		}
+		return nil, fmt.Errorf("invalid resolve entry %q, expected host:ip or host:port:ip", entry)
+	}
+	return resolve, nil
+}
+
+// parseIP 解析 IP 地址，无效时返回空字符串
+func parseIP(value string) string {
+	ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// ParseDNSServer 解析 DNS 服务器地址，未指定端口时使用 53
+func ParseDNSServer(value string) (string, error) {
+	if ip := parseIP(value); ip != "" {
+		return net.JoinHostPort(ip, "53"), nil
+	}
+
+	host, port, err := net.SplitHostPort(value)
+	if err != nil || parseIP(host) == "" {
+		return "", fmt.Errorf("invalid dns server %q, expected ip or ip:port", value)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return "", fmt.Errorf("invalid dns server port %q", port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
@@ -46,6 +46,16 @@ func RenderConfig(cfg *Config) error {
 					pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("📝 description: %q", reg.Description)},
 				)
 			}
+			if reg.Probe != "" {
+				leveledList = append(leveledList,
+					pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("📡 probe: %q", reg.Probe)},
+				)
+			}
+			if len(reg.ExpectStatus) > 0 {
+				leveledList = append(leveledList,
+					pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("🔢 expect_status: %v", reg.ExpectStatus)},
+				)
+			}
 		}
 	}
 
@@ -54,6 +64,164 @@ func RenderConfig(cfg *Config) error {
 		pterm.LeveledListItem{Level: 1, Text: fmt.Sprintf("🔢 max_concurrent_requests: %d", cfg.MaxConcurrentRequests)},
 	)
 
+	// 添加 latency
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 1, Text: "📂 latency"},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⏱️ timeout: %q", cfg.Latency.Timeout)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⏱️ max_latency: %q", cfg.Latency.MaxLatency)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔢 samples: %d", cfg.Latency.Samples)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🏷️ user_agent: %q", cfg.Latency.UserAgent)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔢 concurrency: %d", cfg.LatencyConcurrency())},
+	)
+	if cfg.Latency.Proxy != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🌐 proxy: %q", cfg.Latency.Proxy)},
+		)
+	}
+	if len(cfg.Latency.Resolve) > 0 {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🧭 resolve: %q", cfg.Latency.Resolve)},
+		)
+	}
+	if cfg.Latency.DNSServer != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🧭 dns_server: %q", cfg.Latency.DNSServer)},
+		)
+	}
+	if len(cfg.Latency.Timeouts) > 0 {
+		leveledList = append(leveledList, pterm.LeveledListItem{Level: 2, Text: "📂 timeouts"})
+		for name, timeout := range cfg.Latency.Timeouts {
+			leveledList = append(leveledList,
+				pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("⏱️ %s: %q", name, timeout)},
+			)
+		}
+	}
+
+	// 添加 offline
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 1, Text: "📂 offline"},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⏱️ ttl: %q", cfg.Offline.TTL)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("📡 auto_detect: %v", cfg.Offline.AutoDetectEnabled())},
+	)
+
+	// 添加 security
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 1, Text: "📂 security"},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔒 refuse_insecure: %v", cfg.Security.RefuseInsecure)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("📅 cert_expiry_days: %d", cfg.Security.CertExpiryDays)},
+	)
+
+	// 添加 backup
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 1, Text: "📂 backup"},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🛟 auto: %v", cfg.Backup.AutoEnabled())},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔢 auto_keep: %d", cfg.Backup.AutoKeepCount())},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⏱️ auto_max_age: %q", cfg.Backup.AutoMaxAge)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("📦 format: %q", cfg.Backup.Format)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🗄️ store: %q", cfg.Backup.Store)},
+	)
+	if cfg.Backup.GitRepo != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🌿 git_repo: %q", cfg.Backup.GitRepo)},
+		)
+	}
+	if len(cfg.Backup.Include) > 0 {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("➕ include: %q", cfg.Backup.Include)},
+		)
+	}
+	if len(cfg.Backup.Exclude) > 0 {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("➖ exclude: %q", cfg.Backup.Exclude)},
+		)
+	}
+	if cfg.Backup.Encryption != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔐 encryption: %q", cfg.Backup.Encryption)},
+		)
+	}
+	if len(cfg.Backup.Recipients) > 0 {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔑 recipients: %v", cfg.Backup.Recipients)},
+		)
+	}
+	if cfg.Backup.IdentityFile != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🗝️ identity_file: %q", cfg.Backup.IdentityFile)},
+		)
+	}
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 2, Text: "📂 retention"},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("🔢 keep_last: %d", cfg.Backup.Retention.KeepLast)},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("📅 keep_daily: %d", cfg.Backup.Retention.KeepDaily)},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("📅 keep_weekly: %d", cfg.Backup.Retention.KeepWeekly)},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("📅 keep_monthly: %d", cfg.Backup.Retention.KeepMonthly)},
+	)
+	if cfg.Backup.Retention.MaxAge != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("⏱️ max_age: %q", cfg.Backup.Retention.MaxAge)},
+		)
+	}
+	if cfg.Backup.Retention.MaxSize != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("💾 max_size: %q", cfg.Backup.Retention.MaxSize)},
+		)
+	}
+
+	// 添加 freshness
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 1, Text: "📂 freshness"},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔗 upstream: %q", cfg.Freshness.Upstream)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("📦 sentinel_packages: %q", cfg.Freshness.SentinelPackages)},
+	)
+	if cfg.Freshness.MaxLag != "" {
+		leveledList = append(leveledList,
+			pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⏱️ max_lag: %q", cfg.Freshness.MaxLag)},
+		)
+	}
+
+	// 添加 history
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 1, Text: "📂 history"},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⏱️ max_age: %q", cfg.History.MaxAge)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("🔢 max_entries: %d", cfg.History.MaxEntries)},
+	)
+
+	// 添加 scoring
+	leveledList = append(leveledList,
+		pterm.LeveledListItem{Level: 1, Text: "📂 scoring"},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⏱️ window: %q", cfg.Scoring.Window)},
+		pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("⚖️ bias_weight: %g", cfg.Scoring.BiasWeightValue())},
+		pterm.LeveledListItem{Level: 2, Text: "📂 weights"},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("🔢 latency: %g", cfg.Scoring.Weights.Latency)},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("🔢 success_rate: %g", cfg.Scoring.Weights.SuccessRate)},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("🔢 freshness: %g", cfg.Scoring.Weights.Freshness)},
+		pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("🔢 throughput: %g", cfg.Scoring.Weights.Throughput)},
+	)
+	if len(cfg.Scoring.Bias) > 0 {
+		leveledList = append(leveledList, pterm.LeveledListItem{Level: 2, Text: "📂 bias"})
+		for name, bias := range cfg.Scoring.Bias {
+			leveledList = append(leveledList,
+				pterm.LeveledListItem{Level: 3, Text: fmt.Sprintf("📦 %s: %q", name, bias)},
+			)
+		}
+	}
+
+	// 添加 probes
+	if len(cfg.Probes) > 0 {
+		leveledList = append(leveledList, pterm.LeveledListItem{Level: 1, Text: "📂 probes"})
+		for name, probe := range cfg.Probes {
+			if probe == nil {
+				continue
+			}
+			text := fmt.Sprintf("📡 %s: %q", name, probe.Probe)
+			if len(probe.ExpectStatus) > 0 {
+				text += fmt.Sprintf(" (expect %v)", probe.ExpectStatus)
+			}
+			leveledList = append(leveledList, pterm.LeveledListItem{Level: 2, Text: text})
+		}
+	}
+
 	// 添加空行
 	fmt.Println()
 
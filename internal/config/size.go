@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseSize 解析文件大小，支持 B、KB、MB、GB 为单位（1024 进制），不区分大小写
+// 例如 "512KB"、"100MB"、"1.5GB"，没有单位时为字节数
+func ParseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	unit := int64(1)
+	for _, suffix := range []struct {
+		name string
+		size int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if n, ok := strings.CutSuffix(value, suffix.name); ok {
+			value, unit = strings.TrimSpace(n), suffix.size
+			break
+		}
+	}
+
+	count, err := strconv.ParseFloat(value, 64)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(count * float64(unit)), nil
+}
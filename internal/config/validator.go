@@ -3,8 +3,104 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
+	"strings"
 )
 
+const (
+	// DefaultUpstream 默认的上游 registry
+	DefaultUpstream = "https://registry.npmjs.org/"
+)
+
+const (
+	// DefaultLatencyTimeout 默认的请求超时时间
+	DefaultLatencyTimeout = "5s"
+	// DefaultMaxLatency 默认的最大可接受延迟
+	DefaultMaxLatency = "3s"
+	// DefaultSamples 默认每个 registry 的测试次数
+	DefaultSamples = 1
+	// DefaultUserAgent 默认的 User-Agent
+	DefaultUserAgent = "NRMG-Latency-Tester/1.0"
+	// DefaultConcurrency 默认的并发测试数量
+	DefaultConcurrency = 5
+	// MaxSamples 每个 registry 的最大测试次数
+	MaxSamples = 20
+	// MaxConcurrency 最大并发测试数量
+	MaxConcurrency = 64
+	// FamilyIPv4 只使用 IPv4 连接
+	FamilyIPv4 = "4"
+	// FamilyIPv6 只使用 IPv6 连接
+	FamilyIPv6 = "6"
+)
+
+const (
+	// DefaultOfflineTTL 默认的离线模式历史测试结果有效期
+	DefaultOfflineTTL = "24h"
+)
+
+const (
+	// DefaultCertExpiryDays 默认的证书过期提醒天数
+	DefaultCertExpiryDays = 14
+)
+
+const (
+	// DefaultBackupAutoKeep 默认最多保留的自动备份数量
+	DefaultBackupAutoKeep = 20
+	// DefaultBackupAutoMaxAge 默认的自动备份保留时间
+	DefaultBackupAutoMaxAge = "30d"
+	// BackupStoreDir 备份保存在数据目录的 backups 下
+	BackupStoreDir = "dir"
+	// BackupStoreGit 备份提交到本地 git 仓库
+	BackupStoreGit = "git"
+	// DefaultBackupGitRepo 默认的 git 存储仓库，位于数据目录下
+	DefaultBackupGitRepo = "backups-git"
+	// BackupFormatDir 备份保存为目录
+	BackupFormatDir = "dir"
+	// BackupFormatTarGz 备份保存为单个 .tar.gz
+	BackupFormatTarGz = "tar.gz"
+	// BackupEncryptionPassphrase 使用密码加密备份
+	BackupEncryptionPassphrase = "passphrase"
+	// BackupEncryptionX25519 使用 X25519 公钥加密备份
+	BackupEncryptionX25519 = "x25519"
+	// DefaultRetentionKeepLast 默认保留最新的备份数量
+	DefaultRetentionKeepLast = 10
+	// DefaultRetentionKeepDaily 默认按天保留的备份数量
+	DefaultRetentionKeepDaily = 7
+	// DefaultRetentionKeepWeekly 默认按周保留的备份数量
+	DefaultRetentionKeepWeekly = 4
+	// DefaultRetentionKeepMonthly 默认按月保留的备份数量
+	DefaultRetentionKeepMonthly = 6
+)
+
+const (
+	// DefaultHistoryMaxAge 默认的历史记录保留时间
+	DefaultHistoryMaxAge = "30d"
+	// DefaultHistoryMaxEntries 默认最多保留的历史记录条数
+	DefaultHistoryMaxEntries = 10000
+)
+
+const (
+	// DefaultScoringWindow 默认参与评分的历史记录时间范围
+	DefaultScoringWindow = "7d"
+	// DefaultBiasWeight 默认的 prefer/avoid 加减值
+	DefaultBiasWeight = 0.2
+	// BiasPrefer 优先选择
+	BiasPrefer = "prefer"
+	// BiasAvoid 尽量避免
+	BiasAvoid = "avoid"
+)
+
+// DefaultScoringWeights 默认的评分权重
+var DefaultScoringWeights = ScoringWeights{
+	Latency:     0.5,
+	SuccessRate: 0.3,
+	Freshness:   0,
+	Throughput:  0.2,
+}
+
+// DefaultSentinelPackages 默认的哨兵包
+var DefaultSentinelPackages = []string{"npm", "pnpm"}
+
 // ValidationError 验证错误
 type ValidationError struct {
 	Field   string
@@ -40,6 +136,38 @@ func ValidateRegistry(name string, reg *Registry) error {
 		}
 	}
 
+	// 验证探测方式
+	if err := ValidateProbe(reg.Probe, reg.ExpectStatus); err != nil {
+		return &ValidationError{
+			Field:   "registry.probe",
+			Message: fmt.Sprintf("registry %s: %v", name, err),
+		}
+	}
+
+	return nil
+}
+
+// ValidateProbe 验证探测方式，为空表示使用默认探测方式
+func ValidateProbe(probe string, expectStatus []int) error {
+	switch {
+	case probe == "", probe == "ping", probe == "root", strings.HasPrefix(probe, "/"):
+	case probe == "tcp":
+		if len(expectStatus) > 0 {
+			return fmt.Errorf("probe tcp does not support expect_status")
+		}
+	case strings.HasPrefix(probe, "packument:"):
+		if strings.TrimPrefix(probe, "packument:") == "" {
+			return fmt.Errorf("probe %q: package name is required", probe)
+		}
+	default:
+		return fmt.Errorf("unknown probe %q, expected ping, packument:<name>, root, tcp or /<path>", probe)
+	}
+
+	for _, code := range expectStatus {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid expected status: %d", code)
+		}
+	}
 	return nil
 }
 
@@ -54,11 +182,64 @@ func ValidateConfig(cfg *Config) error {
 
 	// 验证并发请求数
 	if cfg.MaxConcurrentRequests < 1 {
-		cfg.MaxConcurrentRequests = 5 // 使用默认值
-	} else if cfg.MaxConcurrentRequests > 10 {
+		cfg.MaxConcurrentRequests = DefaultConcurrency // 使用默认值
+	} else if cfg.MaxConcurrentRequests > MaxConcurrency {
 		return &ValidationError{
 			Field:   "max_concurrent_requests",
-			Message: "value must be between 1 and 10",
+			Message: fmt.Sprintf("value must be between 1 and %d", MaxConcurrency),
+		}
+	}
+
+	// 验证延迟测试配置
+	if err := ValidateLatency(&cfg.Latency); err != nil {
+		return err
+	}
+
+	// 验证离线模式配置
+	if err := validateOffline(&cfg.Offline); err != nil {
+		return err
+	}
+
+	// 验证传输安全配置
+	if cfg.Security.CertExpiryDays == 0 {
+		cfg.Security.CertExpiryDays = DefaultCertExpiryDays
+	} else if cfg.Security.CertExpiryDays < 0 {
+		return &ValidationError{
+			Field:   "security.cert_expiry_days",
+			Message: "value must be positive",
+		}
+	}
+
+	// 验证备份配置
+	if err := validateBackup(&cfg.Backup); err != nil {
+		return err
+	}
+
+	// 验证同步延迟检测配置
+	if err := validateFreshness(&cfg.Freshness); err != nil {
+		return err
+	}
+
+	// 验证历史记录配置
+	if err := validateHistory(&cfg.History); err != nil {
+		return err
+	}
+
+	// 验证评分策略配置
+	if err := validateScoring(&cfg.Scoring); err != nil {
+		return err
+	}
+
+	// 验证内置 registry 的探测方式
+	for name, probe := range cfg.Probes {
+		if probe == nil {
+			continue
+		}
+		if err := ValidateProbe(probe.Probe, probe.ExpectStatus); err != nil {
+			return &ValidationError{
+				Field:   "probes",
+				Message: fmt.Sprintf("registry %s: %v", name, err),
+			}
 		}
 	}
 
@@ -71,3 +252,371 @@ func ValidateConfig(cfg *Config) error {
 
 	return nil
 }
+
+// ValidateLatency 验证延迟测试配置，并为缺省项填充默认值
+// 并发数量为 0 时保持不变，测试时使用 max_concurrent_requests
+func ValidateLatency(l *LatencyConfig) error {
+	if l.Timeout == "" {
+		l.Timeout = DefaultLatencyTimeout
+	} else if d, err := ParseDuration(l.Timeout); err != nil || d <= 0 {
+		return &ValidationError{
+			Field:   "latency.timeout",
+			Message: fmt.Sprintf("invalid duration %q, expected a positive value such as \"5s\"", l.Timeout),
+		}
+	}
+
+	if l.MaxLatency == "" {
+		l.MaxLatency = DefaultMaxLatency
+	} else if d, err := ParseDuration(l.MaxLatency); err != nil || d < 0 {
+		return &ValidationError{
+			Field:   "latency.max_latency",
+			Message: fmt.Sprintf("invalid duration %q, expected a value such as \"3s\" or \"0s\" to disable", l.MaxLatency),
+		}
+	}
+
+	if l.Samples == 0 {
+		l.Samples = DefaultSamples
+	} else if l.Samples < 1 || l.Samples > MaxSamples {
+		return &ValidationError{
+			Field:   "latency.samples",
+			Message: fmt.Sprintf("value must be between 1 and %d, got %d", MaxSamples, l.Samples),
+		}
+	}
+
+	if l.UserAgent == "" {
+		l.UserAgent = DefaultUserAgent
+	}
+
+	if l.Concurrency < 0 || l.Concurrency > MaxConcurrency {
+		return &ValidationError{
+			Field:   "latency.concurrency",
+			Message: fmt.Sprintf("value must be between 1 and %d, got %d", MaxConcurrency, l.Concurrency),
+		}
+	}
+
+	if l.Proxy != "" && l.Proxy != ProxyDirect {
+		if u, err := url.Parse(l.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			return &ValidationError{
+				Field:   "latency.proxy",
+				Message: fmt.Sprintf("invalid proxy %q, expected a url such as \"http://proxy:8080\" or \"socks5://proxy:1080\"", l.Proxy),
+			}
+		}
+	}
+
+	if _, err := ParseResolve(l.Resolve); err != nil {
+		return &ValidationError{
+			Field:   "latency.resolve",
+			Message: err.Error(),
+		}
+	}
+
+	if l.DNSServer != "" {
+		if _, err := ParseDNSServer(l.DNSServer); err != nil {
+			return &ValidationError{
+				Field:   "latency.dns_server",
+				Message: err.Error(),
+			}
+		}
+	}
+
+	if l.Family != "" && l.Family != FamilyIPv4 && l.Family != FamilyIPv6 {
+		return &ValidationError{
+			Field:   "latency.family",
+			Message: fmt.Sprintf("invalid family %q, expected %q or %q", l.Family, FamilyIPv4, FamilyIPv6),
+		}
+	}
+
+	for name, timeout := range l.Timeouts {
+		if d, err := ParseDuration(timeout); err != nil || d <= 0 {
+			return &ValidationError{
+				Field:   "latency.timeouts",
+				Message: fmt.Sprintf("registry %s: invalid duration %q, expected a positive value such as \"10s\"", name, timeout),
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFreshness 验证同步延迟检测配置，并为缺省项填充默认值
+func validateFreshness(f *FreshnessConfig) error {
+	if f.Upstream == "" {
+		f.Upstream = DefaultUpstream
+	} else if u, err := url.Parse(f.Upstream); err != nil || u.Scheme == "" || u.Host == "" {
+		return &ValidationError{
+			Field:   "freshness.upstream",
+			Message: fmt.Sprintf("invalid url: %s", f.Upstream),
+		}
+	}
+
+	if len(f.SentinelPackages) == 0 {
+		f.SentinelPackages = append([]string(nil), DefaultSentinelPackages...)
+	}
+	for _, pkg := range f.SentinelPackages {
+		if pkg == "" {
+			return &ValidationError{
+				Field:   "freshness.sentinel_packages",
+				Message: "package name must not be empty",
+			}
+		}
+	}
+
+	if f.MaxLag != "" {
+		d, err := ParseDuration(f.MaxLag)
+		if err != nil {
+			return &ValidationError{
+				Field:   "freshness.max_lag",
+				Message: fmt.Sprintf("invalid duration %q: %v", f.MaxLag, err),
+			}
+		}
+		if d < 0 {
+			return &ValidationError{
+				Field:   "freshness.max_lag",
+				Message: "value must not be negative",
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateOffline 验证离线模式配置，并为缺省项填充默认值
+func validateOffline(o *OfflineConfig) error {
+	if o.TTL == "" {
+		o.TTL = DefaultOfflineTTL
+	} else if d, err := ParseDuration(o.TTL); err != nil || d <= 0 {
+		return &ValidationError{
+			Field:   "offline.ttl",
+			Message: fmt.Sprintf("invalid duration %q", o.TTL),
+		}
+	}
+
+	if o.AutoDetect == nil {
+		autoDetect := true
+		o.AutoDetect = &autoDetect
+	}
+
+	return nil
+}
+
+// validateBackup 验证备份配置，并为缺省项填充默认值
+func validateBackup(b *BackupConfig) error {
+	if b.Auto == nil {
+		auto := true
+		b.Auto = &auto
+	}
+
+	if b.AutoKeep == nil {
+		autoKeep := DefaultBackupAutoKeep
+		b.AutoKeep = &autoKeep
+	} else if *b.AutoKeep < 0 {
+		return &ValidationError{
+			Field:   "backup.auto_keep",
+			Message: "value must not be negative",
+		}
+	}
+
+	if b.AutoMaxAge == "" {
+		b.AutoMaxAge = DefaultBackupAutoMaxAge
+	} else if d, err := ParseDuration(b.AutoMaxAge); err != nil || d <= 0 {
+		return &ValidationError{
+			Field:   "backup.auto_max_age",
+			Message: fmt.Sprintf("invalid duration %q", b.AutoMaxAge),
+		}
+	}
+
+	for field, patterns := range map[string][]string{"include": b.Include, "exclude": b.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil || pattern == "" {
+				return &ValidationError{
+					Field:   "backup." + field,
+					Message: fmt.Sprintf("invalid pattern %q", pattern),
+				}
+			}
+		}
+	}
+
+	if b.Format == "" {
+		b.Format = BackupFormatDir
+	} else if b.Format != BackupFormatDir && b.Format != BackupFormatTarGz {
+		return &ValidationError{
+			Field:   "backup.format",
+			Message: fmt.Sprintf("invalid format %q, expected %q or %q", b.Format, BackupFormatDir, BackupFormatTarGz),
+		}
+	}
+
+	switch b.Store {
+	case "":
+		b.Store = BackupStoreDir
+	case BackupStoreDir:
+	case BackupStoreGit:
+		if b.Format != BackupFormatDir {
+			return &ValidationError{
+				Field:   "backup.store",
+				Message: fmt.Sprintf("the git store requires format = %q", BackupFormatDir),
+			}
+		}
+	default:
+		return &ValidationError{
+			Field:   "backup.store",
+			Message: fmt.Sprintf("invalid store %q, expected %q or %q", b.Store, BackupStoreDir, BackupStoreGit),
+		}
+	}
+	if b.Store == BackupStoreGit && b.GitRepo == "" {
+		b.GitRepo = DefaultBackupGitRepo
+	}
+
+	switch b.Encryption {
+	case "":
+	case BackupEncryptionPassphrase, BackupEncryptionX25519:
+		if b.Format != BackupFormatTarGz {
+			return &ValidationError{
+				Field:   "backup.encryption",
+				Message: fmt.Sprintf("encryption requires format = %q", BackupFormatTarGz),
+			}
+		}
+	default:
+		return &ValidationError{
+			Field:   "backup.encryption",
+			Message: fmt.Sprintf("invalid encryption %q, expected %q or %q", b.Encryption, BackupEncryptionPassphrase, BackupEncryptionX25519),
+		}
+	}
+
+	for _, recipient := range b.Recipients {
+		if !strings.HasPrefix(recipient, "age1") {
+			return &ValidationError{
+				Field:   "backup.recipients",
+				Message: fmt.Sprintf("invalid recipient %q, expected an age1... public key", recipient),
+			}
+		}
+	}
+	if b.Encryption == BackupEncryptionX25519 && len(b.Recipients) == 0 && b.IdentityFile == "" {
+		return &ValidationError{
+			Field:   "backup.recipients",
+			Message: "x25519 encryption requires recipients or identity_file",
+		}
+	}
+
+	return validateRetention(&b.Retention)
+}
+
+// validateRetention 验证备份保留策略，没有设置任何规则时使用默认策略
+func validateRetention(r *RetentionConfig) error {
+	if r.IsZero() {
+		*r = RetentionConfig{
+			KeepLast:    DefaultRetentionKeepLast,
+			KeepDaily:   DefaultRetentionKeepDaily,
+			KeepWeekly:  DefaultRetentionKeepWeekly,
+			KeepMonthly: DefaultRetentionKeepMonthly,
+		}
+		return nil
+	}
+
+	for field, value := range map[string]int{
+		"keep_last":    r.KeepLast,
+		"keep_daily":   r.KeepDaily,
+		"keep_weekly":  r.KeepWeekly,
+		"keep_monthly": r.KeepMonthly,
+	} {
+		if value < 0 {
+			return &ValidationError{
+				Field:   "backup.retention." + field,
+				Message: "value must not be negative",
+			}
+		}
+	}
+
+	if r.MaxAge != "" {
+		if d, err := ParseDuration(r.MaxAge); err != nil || d <= 0 {
+			return &ValidationError{
+				Field:   "backup.retention.max_age",
+				Message: fmt.Sprintf("invalid duration %q", r.MaxAge),
+			}
+		}
+	}
+
+	if r.MaxSize != "" {
+		if n, err := ParseSize(r.MaxSize); err != nil || n <= 0 {
+			return &ValidationError{
+				Field:   "backup.retention.max_size",
+				Message: fmt.Sprintf("invalid size %q", r.MaxSize),
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateHistory 验证历史记录配置，并为缺省项填充默认值
+func validateHistory(h *HistoryConfig) error {
+	if h.MaxAge == "" {
+		h.MaxAge = DefaultHistoryMaxAge
+	} else if d, err := ParseDuration(h.MaxAge); err != nil || d <= 0 {
+		return &ValidationError{
+			Field:   "history.max_age",
+			Message: fmt.Sprintf("invalid duration %q", h.MaxAge),
+		}
+	}
+
+	if h.MaxEntries == 0 {
+		h.MaxEntries = DefaultHistoryMaxEntries
+	} else if h.MaxEntries < 0 {
+		return &ValidationError{
+			Field:   "history.max_entries",
+			Message: "value must be positive",
+		}
+	}
+
+	return nil
+}
+
+// validateScoring 验证评分策略配置，并为缺省项填充默认值
+func validateScoring(s *ScoringConfig) error {
+	if s.Window == "" {
+		s.Window = DefaultScoringWindow
+	} else if d, err := ParseDuration(s.Window); err != nil || d <= 0 {
+		return &ValidationError{
+			Field:   "scoring.window",
+			Message: fmt.Sprintf("invalid duration %q", s.Window),
+		}
+	}
+
+	weights := map[string]float64{
+		"scoring.weights.latency":      s.Weights.Latency,
+		"scoring.weights.success_rate": s.Weights.SuccessRate,
+		"scoring.weights.freshness":    s.Weights.Freshness,
+		"scoring.weights.throughput":   s.Weights.Throughput,
+	}
+	for field, weight := range weights {
+		if weight < 0 {
+			return &ValidationError{
+				Field:   field,
+				Message: "weight must not be negative",
+			}
+		}
+	}
+	if s.Weights == (ScoringWeights{}) {
+		s.Weights = DefaultScoringWeights
+	}
+
+	if s.BiasWeight == nil {
+		biasWeight := DefaultBiasWeight
+		s.BiasWeight = &biasWeight
+	} else if *s.BiasWeight < 0 || *s.BiasWeight > 1 {
+		return &ValidationError{
+			Field:   "scoring.bias_weight",
+			Message: "value must be between 0 and 1",
+		}
+	}
+
+	for name, bias := range s.Bias {
+		if bias != BiasPrefer && bias != BiasAvoid {
+			return &ValidationError{
+				Field:   "scoring.bias",
+				Message: fmt.Sprintf("registry %s: bias must be %q or %q, got %q", name, BiasPrefer, BiasAvoid, bias),
+			}
+		}
+	}
+
+	return nil
+}
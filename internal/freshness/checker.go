@@ -0,0 +1,244 @@
+package freshness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"nrmgo/internal/config"
+	"nrmgo/internal/latency"
+)
+
+const (
+	// defaultTimeout 获取完整 packument 的超时时间，完整 packument 可能有数 MB
+	defaultTimeout = 15 * time.Second
+	// defaultConcurrency 默认并发检测数量
+	defaultConcurrency = 5
+)
+
+// Checker 镜像同步延迟检测器
+type Checker struct {
+	client      *http.Client
+	userAgent   string
+	upstream    string
+	packages    []string
+	concurrency int
+	now         func() time.Time
+}
+
+// NewChecker 创建同步延迟检测器
+func NewChecker(upstream string, packages []string) *Checker {
+	return newChecker(upstream, packages, latency.DefaultOptions().WithTimeout(defaultTimeout))
+}
+
+// newChecker 使用 opts 中的代理、解析配置与 User-Agent 创建同步延迟检测器
+func newChecker(upstream string, packages []string, opts *latency.Options) *Checker {
+	return &Checker{
+		client:      latency.NewHTTPClient(opts),
+		userAgent:   opts.UserAgent,
+		upstream:    upstream,
+		packages:    packages,
+		concurrency: defaultConcurrency,
+		now:         time.Now,
+	}
+}
+
+// NewCheckerFromConfig 从配置创建同步延迟检测器，opts 为与延迟测试相同的网络选项
+func NewCheckerFromConfig(cfg *config.Config, opts *latency.Options) *Checker {
+	upstream := config.DefaultUpstream
+	packages := config.DefaultSentinelPackages
+	concurrency := defaultConcurrency
+	if cfg != nil {
+		if cfg.Freshness.Upstream != "" {
+			upstream = cfg.Freshness.Upstream
+		}
+		if len(cfg.Freshness.SentinelPackages) > 0 {
+			packages = cfg.Freshness.SentinelPackages
+		}
+		if n := cfg.LatencyConcurrency(); n > 0 {
+			concurrency = n
+		}
+	}
+
+	// 与延迟测试使用相同的代理、解析配置与 User-Agent
+	c := newChecker(upstream, packages, opts.WithTimeout(defaultTimeout))
+	c.concurrency = concurrency
+	return c
+}
+
+// Check 并发检测多个镜像相对上游的同步延迟
+func (c *Checker) Check(ctx context.Context, targets []Target) []*Result {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// 先获取上游的 packument，作为所有镜像的比较基准
+	upstream := make(map[string]*packument, len(c.packages))
+	upstreamErrs := make(map[string]error, len(c.packages))
+	for _, pkg := range c.packages {
+		doc, err := c.fetch(ctx, c.upstream, pkg)
+		if err != nil {
+			upstreamErrs[pkg] = err
+			continue
+		}
+		upstream[pkg] = doc
+	}
+
+	results := make([]*Result, len(targets))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, c.concurrency)
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(index int, tgt Target) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // 获取信号量
+			defer func() { <-semaphore }() // 释放信号量
+
+			results[index] = c.checkOne(ctx, tgt, upstream, upstreamErrs)
+		}(i, target)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// checkOne 检测单个镜像
+func (c *Checker) checkOne(ctx context.Context, target Target, upstream map[string]*packument, upstreamErrs map[string]error) *Result {
+	result := &Result{
+		Name: target.Name,
+		URL:  target.URL,
+	}
+
+	failed := 0
+	for _, pkg := range c.packages {
+		lag := PackageLag{Package: pkg}
+
+		up, ok := upstream[pkg]
+		if !ok {
+			lag.Error = fmt.Sprintf("upstream: %v", upstreamErrs[pkg])
+			result.Packages = append(result.Packages, lag)
+			failed++
+			continue
+		}
+
+		mirror, err := c.fetch(ctx, target.URL, pkg)
+		if err != nil {
+			lag.Error = err.Error()
+			result.Packages = append(result.Packages, lag)
+			failed++
+			continue
+		}
+
+		lag.UpstreamLatest = up.DistTags["latest"]
+		lag.MirrorLatest = mirror.DistTags["latest"]
+		lag.UpstreamModified = parseTime(up.Time["modified"])
+		lag.MirrorModified = parseTime(mirror.Time["modified"])
+		lag.Lag = computeLag(up, mirror, c.now())
+
+		if lag.Lag > result.Lag {
+			result.Lag = lag.Lag
+		}
+		result.Packages = append(result.Packages, lag)
+	}
+
+	if failed == len(c.packages) && failed > 0 {
+		result.Error = result.Packages[0].Error
+	}
+
+	return result
+}
+
+// fetch 获取指定 registry 上的完整 packument
+// 缩略版 packument 不包含 time 字段，因此这里需要完整文档
+func (c *Checker) fetch(ctx context.Context, registryURL, pkg string) (*packument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, packumentURL(registryURL, pkg), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	var doc packument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode packument: %w", err)
+	}
+	if doc.DistTags["latest"] == "" {
+		return nil, fmt.Errorf("packument has no dist-tags.latest")
+	}
+
+	return &doc, nil
+}
+
+// computeLag 计算镜像相对上游的同步延迟
+// 若镜像的 time.modified 早于上游，延迟为上游在该时间点之后第一次变更距今的时长；
+// 若 dist-tags.latest 不一致，延迟至少为上游 latest 版本发布距今的时长
+func computeLag(upstream, mirror *packument, now time.Time) time.Duration {
+	var lag time.Duration
+
+	upstreamModified := parseTime(upstream.Time["modified"])
+	mirrorModified := parseTime(mirror.Time["modified"])
+	if !upstreamModified.IsZero() && !mirrorModified.IsZero() && mirrorModified.Before(upstreamModified) {
+		lag = now.Sub(firstChangeAfter(upstream, mirrorModified, upstreamModified))
+	}
+
+	if upstreamLatest := upstream.DistTags["latest"]; upstreamLatest != mirror.DistTags["latest"] {
+		if published := parseTime(upstream.Time[upstreamLatest]); !published.IsZero() {
+			if d := now.Sub(published); d > lag {
+				lag = d
+			}
+		}
+	}
+
+	if lag < 0 {
+		return 0
+	}
+	return lag
+}
+
+// firstChangeAfter 返回上游在 since 之后最早发布的版本时间，找不到时返回 fallback
+func firstChangeAfter(doc *packument, since, fallback time.Time) time.Time {
+	first := fallback
+	for version, value := range doc.Time {
+		if version == "created" || version == "modified" {
+			continue
+		}
+		t := parseTime(value)
+		if t.After(since) && t.Before(first) {
+			first = t
+		}
+	}
+	return first
+}
+
+// packumentURL 拼接 packument 地址，scoped 包名中的 / 需要转义
+func packumentURL(registryURL, pkg string) string {
+	if !strings.HasSuffix(registryURL, "/") {
+		registryURL += "/"
+	}
+	return registryURL + url.PathEscape(pkg)
+}
+
+// parseTime 解析 packument 中的时间字段，解析失败时返回零值
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,44 @@
+package freshness
+
+import "time"
+
+// Target 表示一个待检测的镜像
+type Target struct {
+	Name string // 镜像名称
+	URL  string // 镜像地址
+}
+
+// PackageLag 表示单个哨兵包的同步情况
+type PackageLag struct {
+	Package          string        // 包名
+	UpstreamLatest   string        // 上游 dist-tags.latest
+	MirrorLatest     string        // 镜像 dist-tags.latest
+	UpstreamModified time.Time     // 上游 time.modified
+	MirrorModified   time.Time     // 镜像 time.modified
+	Lag              time.Duration // 同步延迟
+	Error            string        // 错误信息
+}
+
+// Result 表示一个镜像的同步延迟检测结果
+type Result struct {
+	Name     string        // 镜像名称
+	URL      string        // 镜像地址
+	Lag      time.Duration // 所有哨兵包中最大的同步延迟
+	Packages []PackageLag  // 每个哨兵包的检测详情
+	Error    string        // 错误信息（所有哨兵包均检测失败时设置）
+}
+
+// Exceeds 判断同步延迟是否超过阈值
+// 检测失败的镜像无法确认是否同步，视为超过阈值
+func (r *Result) Exceeds(maxLag time.Duration) bool {
+	if maxLag <= 0 {
+		return false
+	}
+	return r.Error != "" || r.Lag > maxLag
+}
+
+// packument 表示 packument 中与同步检测相关的字段
+type packument struct {
+	DistTags map[string]string `json:"dist-tags"`
+	Time     map[string]string `json:"time"`
+}
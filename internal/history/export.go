@@ -0,0 +1,120 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader CSV 导出的表头，时长以毫秒为单位
+var csvHeader = []string{
+	"time",
+	"registry",
+	"url",
+	"online",
+	"status",
+	"error_kind",
+	"http_status",
+	"latency_ms",
+	"dns_ms",
+	"connect_ms",
+	"tls_ms",
+	"ttfb_ms",
+	"throughput_bps",
+	"error",
+}
+
+// exportRecord JSON 导出使用的记录格式，字段名比存储格式更易读
+type exportRecord struct {
+	Time       time.Time `json:"time"`
+	Registry   string    `json:"registry"`
+	URL        string    `json:"url"`
+	Online     bool      `json:"online"`
+	Status     string    `json:"status,omitempty"`
+	ErrorKind  string    `json:"error_kind,omitempty"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+	LatencyMs  float64   `json:"latency_ms"`
+	DNSMs      float64   `json:"dns_ms"`
+	ConnectMs  float64   `json:"connect_ms"`
+	TLSMs      float64   `json:"tls_ms"`
+	TTFBMs     float64   `json:"ttfb_ms"`
+	Throughput float64   `json:"throughput_bps"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// millis 将 Micros 转换为毫秒
+func millis(m Micros) float64 {
+	return float64(m) / 1000
+}
+
+// formatCode 格式化 HTTP 状态码，没有状态码时返回空字符串
+func formatCode(code int) string {
+	if code == 0 {
+		return ""
+	}
+	return strconv.Itoa(code)
+}
+
+// WriteCSV 以 CSV 格式导出记录
+func WriteCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	format := func(m Micros) string {
+		return strconv.FormatFloat(millis(m), 'f', 3, 64)
+	}
+	for _, r := range records {
+		if err := writer.Write([]string{
+			r.Time.Format(time.RFC3339),
+			r.Registry,
+			r.URL,
+			strconv.FormatBool(r.Online),
+			r.Status,
+			r.Kind,
+			formatCode(r.Code),
+			format(r.Latency),
+			format(r.DNS),
+			format(r.Connect),
+			format(r.TLS),
+			format(r.TTFB),
+			strconv.FormatFloat(r.Throughput, 'f', 0, 64),
+			r.Error,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON 以 JSON 数组格式导出记录
+func WriteJSON(w io.Writer, records []Record) error {
+	out := make([]exportRecord, len(records))
+	for i, r := range records {
+		out[i] = exportRecord{
+			Time:       r.Time,
+			Registry:   r.Registry,
+			URL:        r.URL,
+			Online:     r.Online,
+			Status:     r.Status,
+			ErrorKind:  r.Kind,
+			HTTPStatus: r.Code,
+			LatencyMs:  millis(r.Latency),
+			DNSMs:      millis(r.DNS),
+			ConnectMs:  millis(r.Connect),
+			TLSMs:      millis(r.TLS),
+			TTFBMs:     millis(r.TTFB),
+			Throughput: r.Throughput,
+			Error:      r.Error,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
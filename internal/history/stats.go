@@ -0,0 +1,125 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// sparkBlocks 火花线使用的字符，从低到高
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkFailure 火花线中表示失败的字符
+const sparkFailure = '×'
+
+// Summary 表示一个 registry 的历史统计
+type Summary struct {
+	Registry      string        // registry 名称
+	URL           string        // 最近一次记录的 registry 地址
+	Total         int           // 记录总数
+	Success       int           // 成功次数
+	MedianLatency time.Duration // 成功请求的延迟中位数
+	Last          time.Time     // 最近一次测试时间
+	Sparkline     string        // 最近记录的延迟火花线
+}
+
+// SuccessRate 返回成功率（0-1）
+func (s Summary) SuccessRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Success) / float64(s.Total)
+}
+
+// Summarize 按 registry 汇总记录，结果按名称排序
+// width 为火花线的最大长度，仅展示最近的 width 条记录
+func Summarize(records []Record, width int) []Summary {
+	groups := make(map[string][]Record)
+	for _, record := range records {
+		groups[record.Registry] = append(groups[record.Registry], record)
+	}
+
+	summaries := make([]Summary, 0, len(groups))
+	for name, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].Time.Before(group[j].Time)
+		})
+
+		summary := Summary{
+			Registry: name,
+			Total:    len(group),
+		}
+
+		var latencies []time.Duration
+		for _, record := range group {
+			if record.Online {
+				summary.Success++
+				latencies = append(latencies, record.Latency.Duration())
+			}
+		}
+		last := group[len(group)-1]
+		summary.URL = last.URL
+		summary.Last = last.Time
+		summary.MedianLatency = Median(latencies)
+		summary.Sparkline = Sparkline(group, width)
+
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Registry < summaries[j].Registry
+	})
+	return summaries
+}
+
+// Median 计算时长的中位数
+func Median(values []time.Duration) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Sparkline 将最近 width 条记录的延迟绘制为火花线，失败的记录显示为 ×
+func Sparkline(records []Record, width int) string {
+	if width > 0 && len(records) > width {
+		records = records[len(records)-width:]
+	}
+
+	var lo, hi Micros
+	first := true
+	for _, record := range records {
+		if !record.Online {
+			continue
+		}
+		if first || record.Latency < lo {
+			lo = record.Latency
+		}
+		if first || record.Latency > hi {
+			hi = record.Latency
+		}
+		first = false
+	}
+
+	var b strings.Builder
+	for _, record := range records {
+		if !record.Online {
+			b.WriteRune(sparkFailure)
+			continue
+		}
+		level := 0
+		if hi > lo {
+			level = int(float64(record.Latency-lo) / float64(hi-lo) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
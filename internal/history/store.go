@@ -0,0 +1,193 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"nrmgo/internal/config"
+)
+
+const (
+	// fileName 历史记录文件名，每行一条 JSON 记录
+	fileName = "history.jsonl"
+)
+
+// Store 基于 JSON Lines 文件的历史记录存储
+type Store struct {
+	path      string
+	retention Retention
+	mu        sync.Mutex
+}
+
+// NewStore 创建历史记录存储，dir 为数据目录
+func NewStore(dir string, retention Retention) *Store {
+	return &Store{
+		path:      filepath.Join(dir, fileName),
+		retention: retention,
+	}
+}
+
+// NewStoreFromConfig 从配置创建历史记录存储，数据保存在 nrmgo 的数据目录下
+func NewStoreFromConfig(cfg *config.Config) (*Store, error) {
+	dir, err := config.GetDataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	retention := Retention{}
+	if cfg != nil {
+		retention.MaxAge = cfg.History.MaxAgeDuration()
+		retention.MaxEntries = cfg.History.MaxEntries
+	}
+	return NewStore(dir, retention), nil
+}
+
+// Path 返回存储文件路径
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append 追加记录，并按保留策略清理过期记录
+func (s *Store) Append(records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return s.prune()
+}
+
+// Query 返回满足条件的记录，按时间升序排列
+func (s *Store) Query(filter Filter) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Record, 0, len(records))
+	for _, record := range records {
+		if filter.Match(record) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+// readAll 读取所有记录，跳过无法解析的行
+func (s *Store) readAll() ([]Record, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue // 跳过损坏的行
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return records, nil
+}
+
+// prune 按保留策略清理记录，仅在有记录被清理时重写文件
+func (s *Store) prune() error {
+	if s.retention.MaxAge <= 0 && s.retention.MaxEntries <= 0 {
+		return nil
+	}
+
+	records, err := s.readAll()
+	if err != nil {
+		return err
+	}
+
+	kept := records
+	if s.retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-s.retention.MaxAge)
+		kept = kept[:0:0]
+		for _, record := range records {
+			if !record.Time.Before(cutoff) {
+				kept = append(kept, record)
+			}
+		}
+	}
+	if s.retention.MaxEntries > 0 && len(kept) > s.retention.MaxEntries {
+		kept = kept[len(kept)-s.retention.MaxEntries:]
+	}
+
+	if len(kept) == len(records) {
+		return nil
+	}
+	return s.rewrite(kept)
+}
+
+// rewrite 通过临时文件原子地重写存储文件
+func (s *Store) rewrite(records []Record) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), fileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package history
+
+import (
+	"time"
+
+	"nrmgo/internal/latency"
+)
+
+// Record 表示一条延迟测试记录
+// 为了让存储文件保持紧凑，JSON 字段名使用缩写，时长统一以微秒保存
+type Record struct {
+	Time     time.Time `json:"t"`           // 测试时间
+	Registry string    `json:"r"`           // registry 名称
+	URL      string    `json:"u,omitempty"` // registry 地址
+	Online   bool      `json:"o"`           // 是否可用（正常或降级）
+	Status   string    `json:"s,omitempty"` // 目标状态
+	Kind     string    `json:"k,omitempty"` // 错误类别
+	Code     int       `json:"c,omitempty"` // HTTP 状态码
+	Latency  Micros    `json:"l"`           // 延迟
+	Error    string    `json:"e,omitempty"` // 错误信息
+	DNS      Micros    `json:"dns,omitempty"`
+	Connect  Micros    `json:"conn,omitempty"`
+	TLS      Micros    `json:"tls,omitempty"`
+	TTFB     Micros    `json:"ttfb,omitempty"`
+
+	Throughput float64 `json:"tp,omitempty"` // 下载速度（字节/秒）
+}
+
+// Micros 以微秒序列化的时长
+type Micros int64
+
+// Duration 转换为 time.Duration
+func (m Micros) Duration() time.Duration {
+	return time.Duration(m) * time.Microsecond
+}
+
+// toMicros 将 time.Duration 转换为 Micros
+func toMicros(d time.Duration) Micros {
+	return Micros(d / time.Microsecond)
+}
+
+// FromResult 将延迟测试结果转换为历史记录
+func FromResult(r *latency.Result) Record {
+	return Record{
+		Time:     r.TestTime,
+		Registry: r.Name,
+		URL:      r.URL,
+		Online:   r.Reachable(),
+		Status:   r.Status.String(),
+		Kind:     r.ErrorKind.String(),
+		Code:     r.StatusCode,
+		Latency:  toMicros(r.Latency),
+		Error:    r.Error,
+		DNS:      toMicros(r.Phases.DNS),
+		Connect:  toMicros(r.Phases.Connect),
+		TLS:      toMicros(r.Phases.TLS),
+		TTFB:     toMicros(r.Phases.TTFB),
+
+		Throughput: r.Throughput,
+	}
+}
+
+// Filter 查询条件
+type Filter struct {
+	Registry string    // 仅返回指定 registry 的记录，为空表示全部
+	Since    time.Time // 仅返回该时间之后的记录，零值表示不限制
+}
+
+// Match 判断记录是否满足查询条件
+func (f Filter) Match(r Record) bool {
+	if f.Registry != "" && r.Registry != f.Registry {
+		return false
+	}
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// Retention 历史记录的保留策略
+type Retention struct {
+	MaxAge     time.Duration // 最长保留时间，0 表示不限制
+	MaxEntries int           // 最多保留条数，0 表示不限制
+}
@@ -31,10 +31,13 @@ func Example() {
 
 	// 处理结果
 	for _, result := range results {
-		if result.IsOnline {
+		switch result.Status {
+		case latency.StatusOnline:
 			fmt.Printf("%s is online, latency: %v\n", result.Name, result.Latency)
-		} else {
-			fmt.Printf("%s is offline: %s\n", result.Name, result.Error)
+		case latency.StatusDegraded:
+			fmt.Printf("%s is degraded: %s\n", result.Name, result.Error)
+		default:
+			fmt.Printf("%s is %s (%s): %s\n", result.Name, result.Status, result.ErrorKind, result.Error)
 		}
 	}
 }
@@ -61,9 +64,9 @@ func ExampleTarget() {
 	result := tester.TestOne(ctx, target)
 
 	// 处理结果
-	if result.IsOnline {
-		fmt.Printf("Target is online with latency: %v\n", result.Latency)
+	if result.Reachable() {
+		fmt.Printf("Target is %s with latency: %v\n", result.Status, result.Latency)
 	} else {
-		fmt.Printf("Target is offline: %s\n", result.Error)
+		fmt.Printf("Target is %s: %s\n", result.Status, result.Error)
 	}
 }
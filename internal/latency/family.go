@@ -0,0 +1,143 @@
+package latency
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"nrmgo/internal/config"
+)
+
+const (
+	// slowIPv6Ratio IPv6 延迟超过 IPv4 的倍数时视为明显更慢
+	slowIPv6Ratio = 1.5
+	// slowIPv6Margin IPv6 比 IPv4 至少慢这么多时才视为明显更慢，避免低延迟时误报
+	slowIPv6Margin = 50 * time.Millisecond
+)
+
+// FamilyVerdict 表示双栈对比的结论
+type FamilyVerdict int
+
+const (
+	// VerdictOK 两个地址族都正常且延迟相近
+	VerdictOK FamilyVerdict = iota
+	// VerdictIPv4Only 没有 IPv6 地址
+	VerdictIPv4Only
+	// VerdictIPv6Only 只有 IPv6 可用
+	VerdictIPv6Only
+	// VerdictIPv6Broken 有 IPv6 地址但无法访问，IPv4 正常
+	VerdictIPv6Broken
+	// VerdictIPv6Slow IPv6 明显慢于 IPv4
+	VerdictIPv6Slow
+	// VerdictDown 两个地址族都无法访问
+	VerdictDown
+)
+
+// String 实现 fmt.Stringer 接口
+func (v FamilyVerdict) String() string {
+	switch v {
+	case VerdictOK:
+		return "ok"
+	case VerdictIPv4Only:
+		return "ipv4-only"
+	case VerdictIPv6Only:
+		return "ipv6-only"
+	case VerdictIPv6Broken:
+		return "ipv6-broken"
+	case VerdictIPv6Slow:
+		return "ipv6-slow"
+	default:
+		return "down"
+	}
+}
+
+// Problem 判断该结论是否说明 IPv6 路径有问题，这类 registry 建议优先使用 IPv4
+func (v FamilyVerdict) Problem() bool {
+	return v == VerdictIPv6Broken || v == VerdictIPv6Slow
+}
+
+// FamilyResult 表示一个目标的 IPv4 与 IPv6 测试结果
+type FamilyResult struct {
+	Name    string        // 目标名称
+	URL     string        // 目标 URL
+	IPv4    *Result       // IPv4 测试结果
+	IPv6    *Result       // IPv6 测试结果
+	HasIPv6 bool          // 是否有 IPv6 地址
+	Verdict FamilyVerdict // 对比结论
+}
+
+// CompareFamilies 分别通过 IPv4 与 IPv6 测试所有目标，结果顺序与 targets 一致
+// 两个地址族依次测试，避免互相影响；ctx 取消后只返回两个地址族都已完成的目标
+// 通过代理只能测得到代理的连接，对比时总是直接连接 registry，忽略 opts 中的代理
+func CompareFamilies(ctx context.Context, opts *Options, targets []Target) []*FamilyResult {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	direct := *opts
+	direct.Proxy = ProxyConfig{}
+	opts = &direct
+
+	ipv4 := testFamily(ctx, opts, config.FamilyIPv4, targets)
+	ipv6 := testFamily(ctx, opts, config.FamilyIPv6, targets)
+	d := newDialer(opts)
+
+	results := make([]*FamilyResult, 0, len(targets))
+	for i, target := range targets {
+		if ipv4[i] == nil || ipv6[i] == nil {
+			continue
+		}
+
+		result := &FamilyResult{
+			Name: target.Name,
+			URL:  target.URL,
+			IPv4: ipv4[i],
+			IPv6: ipv6[i],
+		}
+
+		// IPv6 可用时一定有 IPv6 地址，否则查询 AAAA 记录区分“没有地址”与“地址不可用”
+		result.HasIPv6 = result.IPv6.Reachable()
+		if !result.HasIPv6 {
+			if u, err := url.Parse(target.URL); err == nil {
+				result.HasIPv6, _ = d.lookupIPv6(ctx, u.Hostname())
+			}
+		}
+		result.Verdict = verdict(result)
+		results = append(results, result)
+	}
+	return results
+}
+
+// testFamily 只使用指定地址族测试所有目标，未完成的目标对应 nil
+func testFamily(ctx context.Context, opts *Options, family string, targets []Target) []*Result {
+	familyOpts := *opts
+	familyOpts.Family = family
+	tester := NewTester(&familyOpts).(*DefaultTester)
+
+	results := make([]*Result, len(targets))
+	tester.run(ctx, targets, func(index int, result *Result) {
+		if !result.Cancelled() {
+			results[index] = result
+		}
+	})
+	return results
+}
+
+// verdict 根据两个地址族的测试结果得出结论
+func verdict(r *FamilyResult) FamilyVerdict {
+	ipv4, ipv6 := r.IPv4.Reachable(), r.IPv6.Reachable()
+	switch {
+	case !ipv4 && !ipv6:
+		return VerdictDown
+	case !r.HasIPv6:
+		return VerdictIPv4Only
+	case !ipv4:
+		return VerdictIPv6Only
+	case !ipv6:
+		return VerdictIPv6Broken
+	case float64(r.IPv6.Latency) > float64(r.IPv4.Latency)*slowIPv6Ratio &&
+		r.IPv6.Latency-r.IPv4.Latency > slowIPv6Margin:
+		return VerdictIPv6Slow
+	default:
+		return VerdictOK
+	}
+}
@@ -0,0 +1,248 @@
+package latency
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"nrmgo/internal/config"
+)
+
+// ProxyConfig 代理配置，与 npm 的 proxy、https-proxy、noproxy 对应
+// 代理地址支持 http、https 与 socks5 协议
+type ProxyConfig struct {
+	HTTPProxy  string // HTTP 请求使用的代理
+	HTTPSProxy string // HTTPS 请求使用的代理，为空时使用 HTTPProxy
+	NoProxy    string // 不使用代理的主机列表，以逗号分隔
+}
+
+// ProxyFromEnvironment 从 HTTP_PROXY、HTTPS_PROXY、NO_PROXY 环境变量读取代理配置
+func ProxyFromEnvironment() ProxyConfig {
+	return ProxyConfig{
+		HTTPProxy:  getEnvAny("HTTP_PROXY", "http_proxy"),
+		HTTPSProxy: getEnvAny("HTTPS_PROXY", "https_proxy"),
+		NoProxy:    getEnvAny("NO_PROXY", "no_proxy"),
+	}
+}
+
+// getEnvAny 返回第一个非空的环境变量
+func getEnvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// ProxyFunc 返回 http.Transport 使用的代理函数，没有配置代理时返回 nil
+func (p ProxyConfig) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if p.HTTPProxy == "" && p.HTTPSProxy == "" {
+		return nil
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if p.bypass(req.URL) {
+			return nil, nil
+		}
+
+		proxy := p.HTTPProxy
+		if req.URL.Scheme == "https" && p.HTTPSProxy != "" {
+			proxy = p.HTTPSProxy
+		}
+		if proxy == "" {
+			return nil, nil
+		}
+
+		// 与 npm 一致，没有协议的代理地址视为 http 代理
+		if !strings.Contains(proxy, "://") {
+			proxy = "http://" + proxy
+		}
+		return url.Parse(proxy)
+	}
+}
+
+// bypass 判断目标是否匹配 NoProxy，规则与 npm 一致：
+// "*" 匹配所有主机，"example.com" 与 ".example.com" 匹配该域名及其子域名，
+// 也可以指定端口（example.com:8080）或 CIDR（10.0.0.0/8）
+func (p ProxyConfig) bypass(target *url.URL) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	for _, entry := range strings.Split(p.NoProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+
+		// CIDR
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		// 指定端口时端口必须一致
+		if h, entryPort, err := net.SplitHostPort(entry); err == nil {
+			if entryPort != port {
+				continue
+			}
+			entry = h
+		}
+
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialer 支持自定义主机解析与 DNS 服务器的拨号器
+type dialer struct {
+	net.Dialer
+	resolve map[string]string // 主机（或 host:port）到 IP 的映射
+	network string            // 指定地址族时使用的网络类型：tcp4 或 tcp6
+}
+
+// newDialer 根据测试选项创建拨号器
+func newDialer(opts *Options) *dialer {
+	d := &dialer{resolve: opts.Resolve}
+	switch opts.Family {
+	case config.FamilyIPv4:
+		d.network = "tcp4"
+	case config.FamilyIPv6:
+		d.network = "tcp6"
+	}
+	d.Timeout = 30 * time.Second
+	d.KeepAlive = 30 * time.Second
+
+	// 使用指定的 DNS 服务器解析域名
+	if opts.DNSServer != "" {
+		server := opts.DNSServer
+		d.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var nd net.Dialer
+				return nd.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return d
+}
+
+// DialContext 建立连接，命中自定义解析时直接连接指定的 IP
+// 指定了地址族时只使用该地址族，不会回退到另一个地址族
+func (d *dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if d.network != "" && strings.HasPrefix(network, "tcp") {
+		network = d.network
+	}
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		if ip, ok := d.resolve[address]; ok {
+			address = net.JoinHostPort(ip, port)
+		} else if ip, ok := d.resolve[host]; ok {
+			address = net.JoinHostPort(ip, port)
+		}
+	}
+	return d.Dialer.DialContext(ctx, network, address)
+}
+
+// lookupIPv6 判断主机是否有 IPv6 地址（AAAA 记录）
+func (d *dialer) lookupIPv6(ctx context.Context, host string) (bool, error) {
+	if ip, ok := d.resolve[host]; ok {
+		return net.ParseIP(ip).To4() == nil, nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.To4() == nil, nil
+	}
+
+	resolver := d.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ips, err := resolver.LookupIP(ctx, "ip6", host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(ips) > 0, nil
+}
+
+// DetectOnline 判断网络是否可用：在 timeout 内能连接任一非本机地址时返回 true
+// 配置了代理时连接代理地址；没有可检测的地址时视为在线
+func DetectOnline(ctx context.Context, opts *Options, urls []string, timeout time.Duration) bool {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	d := newDialer(opts)
+	proxy := opts.Proxy.ProxyFunc()
+
+	// 收集需要检测的地址，本机地址在离线时同样可以访问，不作为判断依据
+	addresses := make(map[string]bool)
+	for _, rawURL := range urls {
+		u, err := url.Parse(rawURL)
+		if err != nil || isLoopback(u.Hostname()) {
+			continue
+		}
+		if proxy != nil {
+			if proxyURL, err := proxy(&http.Request{URL: u}); err == nil && proxyURL != nil {
+				u = proxyURL
+			}
+		}
+		if address, err := hostPort(u.String()); err == nil {
+			addresses[address] = true
+		}
+	}
+	if len(addresses) == 0 {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	online := make(chan bool, len(addresses))
+	for address := range addresses {
+		go func(address string) {
+			conn, err := d.DialContext(ctx, "tcp", address)
+			if err == nil {
+				conn.Close()
+			}
+			online <- err == nil
+		}(address)
+	}
+
+	// 任一地址连接成功即返回
+	for range addresses {
+		if <-online {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopback 判断主机是否为本机地址
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,117 @@
+package latency
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ProbeKind 表示探测方式
+type ProbeKind int
+
+const (
+	// ProbeHTTP 请求指定路径，默认探测方式
+	ProbeHTTP ProbeKind = iota
+	// ProbeTCP 只建立 TCP 连接
+	ProbeTCP
+)
+
+// abbreviatedAccept 请求精简版包元数据的 Accept 头
+const abbreviatedAccept = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8, */*"
+
+// Probe 表示一个 registry 的探测方式
+type Probe struct {
+	Kind         ProbeKind // 探测方式
+	Path         string    // 请求路径，相对于 registry URL
+	Accept       string    // Accept 头，为空时不设置
+	ExpectStatus []int     // 视为成功的 HTTP 状态码，为空时接受所有 2xx
+}
+
+// ParseProbe 解析探测配置
+// 支持以下格式：
+//   - ping：请求 /-/ping
+//   - packument:<name>：请求指定包的精简版元数据
+//   - root：请求 registry 根路径
+//   - tcp：只建立 TCP 连接
+//   - /<path>：请求自定义路径
+//
+// 为空时使用 packument:package.json
+func ParseProbe(spec string, expectStatus []int) (Probe, error) {
+	probe := Probe{ExpectStatus: expectStatus}
+
+	switch {
+	case spec == "":
+		probe.Path = "package.json"
+		probe.Accept = abbreviatedAccept
+	case spec == "ping":
+		probe.Path = "-/ping"
+		probe.Accept = "application/json"
+	case spec == "root":
+		probe.Accept = "application/json"
+	case spec == "tcp":
+		probe.Kind = ProbeTCP
+		if len(expectStatus) > 0 {
+			return Probe{}, fmt.Errorf("probe tcp does not support expect_status")
+		}
+	case strings.HasPrefix(spec, "packument:"):
+		name := strings.TrimPrefix(spec, "packument:")
+		if name == "" {
+			return Probe{}, fmt.Errorf("probe %q: package name is required", spec)
+		}
+		// scoped 包名中的 / 需要转义
+		probe.Path = strings.Replace(name, "/", "%2f", 1)
+		probe.Accept = abbreviatedAccept
+	case strings.HasPrefix(spec, "/"):
+		probe.Path = strings.TrimPrefix(spec, "/")
+	default:
+		return Probe{}, fmt.Errorf("unknown probe %q, expected ping, packument:<name>, root, tcp or /<path>", spec)
+	}
+
+	for _, code := range expectStatus {
+		if code < 100 || code > 599 {
+			return Probe{}, fmt.Errorf("invalid expected status: %d", code)
+		}
+	}
+
+	return probe, nil
+}
+
+// check 根据 HTTP 状态码判断探测结果
+func (p Probe) check(code int) (Status, ErrorKind) {
+	if len(p.ExpectStatus) == 0 {
+		return classifyStatus(code)
+	}
+	for _, expected := range p.ExpectStatus {
+		if code == expected {
+			return StatusOnline, ErrorNone
+		}
+	}
+
+	// 不在预期范围内的 2xx 响应同样视为失败
+	status, kind := classifyStatus(code)
+	if status == StatusOnline {
+		return StatusDown, ErrorHTTPOther
+	}
+	return status, kind
+}
+
+// hostPort 从 registry URL 中获取 TCP 连接地址
+func hostPort(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("missing host in url %q", rawURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
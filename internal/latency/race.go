@@ -0,0 +1,265 @@
+package latency
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RaceOptions 竞速模式的配置选项
+type RaceOptions struct {
+	Budget     time.Duration // 时间预算，超时后选择当前领先者
+	MinSamples int           // 领先者判定胜出前至少需要的成功样本数
+	Z          float64       // 置信区间的 z 值，越大越保守
+	Tolerance  time.Duration // 小于该差值的延迟视为相同，避免在几乎一样快的目标间无限测试
+}
+
+// DefaultRaceOptions 返回默认的竞速选项
+func DefaultRaceOptions() RaceOptions {
+	return RaceOptions{
+		Budget:     1500 * time.Millisecond,
+		MinSamples: 2,
+		Z:          1.96,
+		Tolerance:  20 * time.Millisecond,
+	}
+}
+
+// RaceEntry 表示一个目标在竞速中的统计
+type RaceEntry struct {
+	Name     string        // 目标名称
+	URL      string        // 目标 URL
+	Samples  int           // 成功样本数
+	Mean     time.Duration // 成功样本的平均延迟
+	Failures int           // 失败次数
+	Error    string        // 最近一次失败的错误信息
+}
+
+// RaceResult 表示竞速的结果
+type RaceResult struct {
+	Winner  string        // 胜出的目标名称，为空表示没有可用目标
+	Decided bool          // 是否在预算内明确分出胜负，false 表示预算耗尽后按当前领先者选择
+	Elapsed time.Duration // 竞速耗时
+	Entries []RaceEntry   // 每个目标的统计，按平均延迟排序
+	Results []*Result     // 所有已完成的测试结果（不含被取消的测试）
+}
+
+// raceState 单个目标的竞速状态
+type raceState struct {
+	target   Target
+	samples  []time.Duration
+	failures int
+	err      string
+	pending  time.Time // 当前进行中的测试开始时间
+}
+
+// raceEvent 竞速过程中的事件
+type raceEvent struct {
+	index   int
+	started time.Time // 非零表示开始了一次新的测试
+	result  *Result   // 非空表示完成了一次测试
+}
+
+// Race 同时反复测试所有目标，使用序贯统计在某个目标明显领先或预算耗尽时停止，
+// 并通过 ctx 取消所有进行中的请求。失败的目标会退出竞速。
+func Race(ctx context.Context, tester Tester, targets []Target, opts RaceOptions) *RaceResult {
+	if opts.Budget <= 0 {
+		opts.Budget = DefaultRaceOptions().Budget
+	}
+	if opts.MinSamples <= 0 {
+		opts.MinSamples = DefaultRaceOptions().MinSamples
+	}
+	if opts.Z <= 0 {
+		opts.Z = DefaultRaceOptions().Z
+	}
+	if opts.Tolerance < 0 {
+		opts.Tolerance = 0
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, opts.Budget)
+	defer cancel()
+
+	states := make([]*raceState, len(targets))
+	events := make(chan raceEvent, len(targets)*2)
+	var wg sync.WaitGroup
+
+	// 每个目标一个 goroutine，不断发起测试直到失败或竞速结束
+	for i, target := range targets {
+		states[i] = &raceState{target: target}
+		wg.Add(1)
+		go func(index int, tgt Target) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if !sendEvent(ctx, events, raceEvent{index: index, started: time.Now()}) {
+					return
+				}
+				result := tester.TestOne(ctx, tgt)
+				if !sendEvent(ctx, events, raceEvent{index: index, result: result}) {
+					return
+				}
+				if !result.Reachable() {
+					return
+				}
+			}
+		}(i, target)
+	}
+
+	race := &RaceResult{}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case event := <-events:
+			state := states[event.index]
+			switch {
+			case event.result != nil:
+				state.pending = time.Time{}
+				if event.result.Cancelled() {
+					continue
+				}
+				race.Results = append(race.Results, event.result)
+				if event.result.Reachable() {
+					state.samples = append(state.samples, event.result.Latency)
+				} else {
+					state.failures++
+					state.err = event.result.Error
+				}
+			default:
+				state.pending = event.started
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			break loop
+		}
+
+		if winner, ok := decide(states, opts); ok {
+			race.Winner = winner
+			race.Decided = true
+			break
+		}
+	}
+
+	// 取消所有进行中的请求并等待退出
+	cancel()
+	wg.Wait()
+
+	race.Elapsed = time.Since(start)
+	race.Entries = raceEntries(states)
+	if race.Winner == "" {
+		// 预算耗尽时选择平均延迟最低的目标
+		for _, entry := range race.Entries {
+			if entry.Samples > 0 {
+				race.Winner = entry.Name
+				break
+			}
+		}
+	}
+	return race
+}
+
+// sendEvent 发送事件，竞速结束时返回 false
+func sendEvent(ctx context.Context, events chan<- raceEvent, event raceEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// decide 判断是否已有目标明显领先
+// 领先者的置信上界必须低于其他所有仍在竞速的目标的置信下界加上容差；
+// 尚无成功样本的目标，以其进行中测试已耗费的时间作为延迟下界
+func decide(states []*raceState, opts RaceOptions) (string, bool) {
+	var leader *raceState
+	for _, state := range states {
+		if len(state.samples) >= opts.MinSamples && (leader == nil || mean(state.samples) < mean(leader.samples)) {
+			leader = state
+		}
+	}
+	if leader == nil {
+		return "", false
+	}
+
+	upper := float64(mean(leader.samples)) + opts.Z*stdErr(leader.samples)
+	now := time.Now()
+	for _, state := range states {
+		if state == leader {
+			continue
+		}
+
+		var lower float64
+		switch {
+		case len(state.samples) > 0:
+			lower = float64(mean(state.samples)) - opts.Z*stdErr(state.samples)
+		case state.failures > 0:
+			continue // 已失败退出竞速
+		case !state.pending.IsZero():
+			lower = float64(now.Sub(state.pending))
+		default:
+			return "", false // 尚未开始测试
+		}
+
+		if upper >= lower+float64(opts.Tolerance) {
+			return "", false
+		}
+	}
+	return leader.target.Name, true
+}
+
+// raceEntries 汇总每个目标的统计，按平均延迟排序，无成功样本的排在后面
+func raceEntries(states []*raceState) []RaceEntry {
+	entries := make([]RaceEntry, len(states))
+	for i, state := range states {
+		entries[i] = RaceEntry{
+			Name:     state.target.Name,
+			URL:      state.target.URL,
+			Samples:  len(state.samples),
+			Mean:     mean(state.samples),
+			Failures: state.failures,
+			Error:    state.err,
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if (entries[i].Samples > 0) != (entries[j].Samples > 0) {
+			return entries[i].Samples > 0
+		}
+		return entries[i].Mean < entries[j].Mean
+	})
+	return entries
+}
+
+// mean 计算平均值
+func mean(samples []time.Duration) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, s := range samples {
+		sum += s
+	}
+	return sum / time.Duration(len(samples))
+}
+
+// stdErr 计算平均值的标准误差
+// 只有一个样本时无法估计方差，假设标准差为平均值的 25%
+func stdErr(samples []time.Duration) float64 {
+	n := float64(len(samples))
+	m := float64(mean(samples))
+	if len(samples) < 2 {
+		return m * 0.25
+	}
+
+	var variance float64
+	for _, s := range samples {
+		d := float64(s) - m
+		variance += d * d
+	}
+	variance /= n - 1
+	return math.Sqrt(variance / n)
+}
@@ -0,0 +1,196 @@
+package latency
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// Status 表示测试目标的状态
+type Status int
+
+const (
+	// StatusUnknown 未知状态
+	StatusUnknown Status = iota
+	// StatusOnline 正常响应
+	StatusOnline
+	// StatusDegraded 有响应，但延迟超过最大限制或未通过自定义验证
+	StatusDegraded
+	// StatusAuthRequired 需要认证（HTTP 401/403）
+	StatusAuthRequired
+	// StatusNotFound 测试路径不存在（HTTP 404）
+	StatusNotFound
+	// StatusDown 无法访问（网络错误或其他 HTTP 错误）
+	StatusDown
+	// StatusCancelled 测试被调用方取消
+	StatusCancelled
+)
+
+// String 实现 fmt.Stringer 接口
+func (s Status) String() string {
+	switch s {
+	case StatusOnline:
+		return "online"
+	case StatusDegraded:
+		return "degraded"
+	case StatusAuthRequired:
+		return "auth-required"
+	case StatusNotFound:
+		return "not-found"
+	case StatusDown:
+		return "down"
+	case StatusCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseStatus 将 String 返回的名称解析为状态，无法识别时返回 StatusUnknown
+func ParseStatus(name string) Status {
+	for s := StatusOnline; s <= StatusCancelled; s++ {
+		if s.String() == name {
+			return s
+		}
+	}
+	return StatusUnknown
+}
+
+// Icon 返回状态对应的图标
+func (s Status) Icon() string {
+	switch s {
+	case StatusOnline:
+		return "✅"
+	case StatusDegraded:
+		return "🐢"
+	case StatusAuthRequired:
+		return "🔒"
+	case StatusNotFound:
+		return "❓"
+	case StatusDown:
+		return "❌"
+	case StatusCancelled:
+		return "⏹️"
+	default:
+		return "-"
+	}
+}
+
+// Reachable 判断目标是否可用（正常或降级）
+func (s Status) Reachable() bool {
+	return s == StatusOnline || s == StatusDegraded
+}
+
+// ErrorKind 表示错误的类别
+type ErrorKind int
+
+const (
+	// ErrorNone 没有错误
+	ErrorNone ErrorKind = iota
+	// ErrorDNS DNS 解析失败
+	ErrorDNS
+	// ErrorConnect TCP 连接失败
+	ErrorConnect
+	// ErrorTLS TLS 握手或证书校验失败
+	ErrorTLS
+	// ErrorTimeout 请求超时
+	ErrorTimeout
+	// ErrorHTTPClient HTTP 4xx 响应
+	ErrorHTTPClient
+	// ErrorHTTPServer HTTP 5xx 响应
+	ErrorHTTPServer
+	// ErrorHTTPOther 其他非 2xx 的 HTTP 响应
+	ErrorHTTPOther
+	// ErrorSlow 延迟超过最大限制
+	ErrorSlow
+	// ErrorValidation 未通过自定义验证
+	ErrorValidation
+	// ErrorOther 其他错误
+	ErrorOther
+)
+
+// String 实现 fmt.Stringer 接口
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorNone:
+		return ""
+	case ErrorDNS:
+		return "dns"
+	case ErrorConnect:
+		return "connect"
+	case ErrorTLS:
+		return "tls"
+	case ErrorTimeout:
+		return "timeout"
+	case ErrorHTTPClient:
+		return "http-4xx"
+	case ErrorHTTPServer:
+		return "http-5xx"
+	case ErrorHTTPOther:
+		return "http"
+	case ErrorSlow:
+		return "slow"
+	case ErrorValidation:
+		return "validation"
+	default:
+		return "other"
+	}
+}
+
+// classifyError 根据请求错误判断错误类别
+func classifyError(err error) ErrorKind {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorDNS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTimeout
+	}
+
+	var (
+		certErr     *tls.CertificateVerificationError
+		recordErr   tls.RecordHeaderError
+		unknownAuth x509.UnknownAuthorityError
+		hostErr     x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+	)
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &unknownAuth) ||
+		errors.As(err, &hostErr) || errors.As(err, &invalidErr) || strings.Contains(err.Error(), "tls: ") {
+		return ErrorTLS
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return ErrorConnect
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorConnect
+	}
+
+	return ErrorOther
+}
+
+// classifyStatus 根据 HTTP 状态码判断目标状态与错误类别
+func classifyStatus(code int) (Status, ErrorKind) {
+	switch {
+	case code >= 200 && code < 300:
+		return StatusOnline, ErrorNone
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return StatusAuthRequired, ErrorHTTPClient
+	case code == http.StatusNotFound:
+		return StatusNotFound, ErrorHTTPClient
+	case code >= 400 && code < 500:
+		return StatusDown, ErrorHTTPClient
+	case code >= 500:
+		return StatusDown, ErrorHTTPServer
+	default:
+		return StatusDown, ErrorHTTPOther
+	}
+}
@@ -2,18 +2,31 @@ package latency
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"sort"
 	"sync"
 	"time"
 
 	"nrmgo/internal/config"
 )
 
+const (
+	// maxProbeBody 读取响应体的最大字节数
+	maxProbeBody = 4 << 20
+	// minThroughputBytes 计算下载速度所需的最小响应体大小，太小的响应体测得的速度没有意义
+	minThroughputBytes = 16 << 10
+)
+
 // Tester 定义延迟测试器接口
 type Tester interface {
 	// Test 测试多个目标的延迟
 	Test(ctx context.Context, targets []Target) []*Result
+	// TestStream 测试多个目标的延迟，每完成一个目标就通过 channel 返回结果
+	TestStream(ctx context.Context, targets []Target) <-chan *Result
 	// TestOne 测试单个目标的延迟
 	TestOne(ctx context.Context, target Target) *Result
 }
@@ -22,6 +35,7 @@ type Tester interface {
 type DefaultTester struct {
 	opts   *Options
 	client *http.Client
+	dialer *dialer
 }
 
 // NewTester 创建新的延迟测试器
@@ -30,9 +44,29 @@ func NewTester(opts *Options) Tester {
 		opts = DefaultOptions()
 	}
 
-	client := &http.Client{
+	// 超时由 TestOne 通过 context 控制，以便目标可以设置比全局更长的超时时间
+	client := NewHTTPClient(opts)
+	client.Timeout = 0
+
+	return &DefaultTester{
+		opts:   opts,
+		client: client,
+		dialer: newDialer(opts),
+	}
+}
+
+// NewHTTPClient 根据测试选项创建 HTTP 客户端
+// 其他需要访问 registry 的模块复用该客户端配置，保证行为一致
+func NewHTTPClient(opts *Options) *http.Client {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	return &http.Client{
 		Timeout: opts.Timeout,
 		Transport: &http.Transport{
+			Proxy:               opts.Proxy.ProxyFunc(),
+			DialContext:         newDialer(opts).DialContext,
 			MaxIdleConns:        100,
 			IdleConnTimeout:     90 * time.Second,
 			DisableCompression:  true,
@@ -40,29 +74,83 @@ func NewTester(opts *Options) Tester {
 			MaxIdleConnsPerHost: 10,
 		},
 	}
+}
 
-	return &DefaultTester{
-		opts:   opts,
-		client: client,
+// OptionsFromConfig 从配置创建测试选项，未配置的项使用默认值，不包含代理
+func OptionsFromConfig(cfg *config.Config) *Options {
+	opts := DefaultOptions()
+	if cfg == nil {
+		return opts
+	}
+
+	if concurrency := cfg.LatencyConcurrency(); concurrency > 0 {
+		opts.Concurrency = concurrency
+	}
+	if timeout := cfg.Latency.TimeoutDuration(); timeout > 0 {
+		opts.Timeout = timeout
+	}
+	if cfg.Latency.MaxLatency != "" {
+		opts.MaxLatency = cfg.Latency.MaxLatencyDuration()
 	}
+	if cfg.Latency.Samples > 0 {
+		opts.Samples = cfg.Latency.Samples
+	}
+	if cfg.Latency.UserAgent != "" {
+		opts.UserAgent = cfg.Latency.UserAgent
+	}
+
+	// 网络配置在加载时已验证，代理由调用方按 npm 的规则解析后设置
+	opts.Resolve, _ = config.ParseResolve(cfg.Latency.Resolve)
+	if cfg.Latency.DNSServer != "" {
+		opts.DNSServer, _ = config.ParseDNSServer(cfg.Latency.DNSServer)
+	}
+	opts.Family = cfg.Latency.Family
+	return opts
 }
 
-// NewTesterFromConfig 从配置创建新的延迟测试器
+// NewTesterFromConfig 从配置创建新的延迟测试器，不使用代理
+// 需要与 npm 相同的代理时由调用方解析代理后通过 NewTester 创建
 func NewTesterFromConfig(cfg *config.Config) Tester {
-	opts := DefaultOptions()
-	if cfg != nil && cfg.MaxConcurrentRequests > 0 {
-		opts.Concurrency = cfg.MaxConcurrentRequests
-	}
-	return NewTester(opts)
+	return NewTester(OptionsFromConfig(cfg))
 }
 
-// Test 并发测试多个目标的延迟
+// Test 并发测试多个目标的延迟，结果顺序与 targets 一致
+// ctx 取消后只返回已完成的测试结果
 func (t *DefaultTester) Test(ctx context.Context, targets []Target) []*Result {
 	if len(targets) == 0 {
 		return nil
 	}
 
 	results := make([]*Result, len(targets))
+	t.run(ctx, targets, func(index int, result *Result) {
+		results[index] = result
+	})
+
+	// 去掉因取消而未执行的目标
+	completed := results[:0]
+	for _, result := range results {
+		if result != nil {
+			completed = append(completed, result)
+		}
+	}
+	return completed
+}
+
+// TestStream 并发测试多个目标的延迟，每完成一个目标就通过 channel 发送结果
+// ctx 取消后不再启动新的测试，进行中的请求会被中断；所有测试结束后关闭 channel
+func (t *DefaultTester) TestStream(ctx context.Context, targets []Target) <-chan *Result {
+	out := make(chan *Result, len(targets))
+	go func() {
+		defer close(out)
+		t.run(ctx, targets, func(_ int, result *Result) {
+			out <- result
+		})
+	}()
+	return out
+}
+
+// run 按并发限制执行测试，每完成一个目标调用一次 emit
+func (t *DefaultTester) run(ctx context.Context, targets []Target, emit func(index int, result *Result)) {
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, t.opts.Concurrency)
 
@@ -70,15 +158,45 @@ func (t *DefaultTester) Test(ctx context.Context, targets []Target) []*Result {
 		wg.Add(1)
 		go func(index int, tgt Target) {
 			defer wg.Done()
-			semaphore <- struct{}{}        // 获取信号量
+
+			// 获取信号量，已取消时不再启动新的测试
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-semaphore }() // 释放信号量
+			if ctx.Err() != nil {
+				return
+			}
 
-			results[index] = t.TestOne(ctx, tgt)
+			emit(index, t.sample(ctx, tgt))
 		}(i, target)
 	}
 
 	wg.Wait()
-	return results
+}
+
+// sample 多次测试同一目标，返回延迟为中位数的那次结果
+// 任意一次测试失败时直接返回该次结果
+func (t *DefaultTester) sample(ctx context.Context, target Target) *Result {
+	if t.opts.Samples <= 1 {
+		return t.TestOne(ctx, target)
+	}
+
+	results := make([]*Result, 0, t.opts.Samples)
+	for i := 0; i < t.opts.Samples; i++ {
+		result := t.TestOne(ctx, target)
+		if !result.Reachable() {
+			return result
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Latency < results[j].Latency
+	})
+	return results[len(results)/2]
 }
 
 // TestOne 测试单个目标的延迟
@@ -96,17 +214,96 @@ func (t *DefaultTester) TestOne(ctx context.Context, target Target) *Result {
 	}
 
 	// 创建带超时的上下文
+	parent := ctx
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// 获取完整的测试 URL
-	testURL := target.GetTestURL()
+	// 执行探测，失败时 result 中已记录错误信息
+	var ok bool
+	if target.Probe.Kind == ProbeTCP {
+		ok = t.probeTCP(ctx, parent, target, result)
+	} else {
+		ok = t.probeHTTP(ctx, parent, target, result)
+	}
+	if !ok {
+		return result
+	}
+
+	// 检查延迟是否超过最大限制，超过时视为降级而非离线
+	if t.opts.MaxLatency > 0 && result.Latency > t.opts.MaxLatency {
+		result.Error = fmt.Sprintf("latency too high: %v > %v", result.Latency, t.opts.MaxLatency)
+		result.Status = StatusDegraded
+		result.ErrorKind = ErrorSlow
+		return result
+	}
+
+	// 执行自定义验证
+	if target.Validate != nil {
+		if err := target.Validate(result); err != nil {
+			result.Error = fmt.Sprintf("validation failed: %v", err)
+			result.Status = StatusDegraded
+			result.ErrorKind = ErrorValidation
+			return result
+		}
+	}
 
+	return result
+}
+
+// newPhaseTrace 创建记录请求各阶段耗时的 ClientTrace
+// 并行拨号时回调可能在多个 goroutine 中执行，因此需要加锁，并通过返回的函数读取结果
+func newPhaseTrace() (*httptrace.ClientTrace, func() Phases) {
+	var (
+		mu                                             sync.Mutex
+		phases                                         Phases
+		dnsStart, connectStart, tlsStart, wroteRequest time.Time
+	)
+	mark := func(t *time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		if t.IsZero() {
+			*t = time.Now()
+		}
+	}
+	since := func(start *time.Time, d *time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !start.IsZero() && *d == 0 {
+			*d = time.Since(*start)
+		}
+	}
+
+	snapshot := func() Phases {
+		mu.Lock()
+		defer mu.Unlock()
+		return phases
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { mark(&dnsStart) },
+		DNSDone:      func(httptrace.DNSDoneInfo) { since(&dnsStart, &phases.DNS) },
+		ConnectStart: func(string, string) { mark(&connectStart) },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				since(&connectStart, &phases.Connect)
+			}
+		},
+		TLSHandshakeStart:    func() { mark(&tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { since(&tlsStart, &phases.TLS) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { mark(&wroteRequest) },
+		GotFirstResponseByte: func() { since(&wroteRequest, &phases.TTFB) },
+	}, snapshot
+}
+
+// probeHTTP 通过 HTTP 请求探测目标，并测量下载速度
+func (t *DefaultTester) probeHTTP(ctx, parent context.Context, target Target, result *Result) bool {
 	// 创建请求
-	req, err := http.NewRequestWithContext(ctx, "GET", testURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", target.GetTestURL(), nil)
 	if err != nil {
 		result.Error = fmt.Sprintf("failed to create request: %v", err)
-		return result
+		result.Status = StatusDown
+		result.ErrorKind = ErrorOther
+		return false
 	}
 
 	// 设置请求头
@@ -117,41 +314,71 @@ func (t *DefaultTester) TestOne(ctx context.Context, target Target) *Result {
 		}
 	}
 
+	// 记录各阶段耗时
+	trace, phases := newPhaseTrace()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
 	// 执行请求并测量延迟
 	start := time.Now()
 	resp, err := t.client.Do(req)
 	result.Latency = time.Since(start)
-
+	result.Phases = phases()
 	if err != nil {
-		result.Error = fmt.Sprintf("request failed: %v", err)
-		result.IsOnline = false
-		return result
+		setFailure(parent, result, err)
+		return false
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
+	result.StatusCode = resp.StatusCode
+	if result.Status, result.ErrorKind = target.Probe.check(resp.StatusCode); result.Status != StatusOnline {
 		result.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, resp.Status)
-		result.IsOnline = false
-		return result
+		return false
 	}
 
-	// 检查延迟是否超过最大限制
-	if t.opts.MaxLatency > 0 && result.Latency > t.opts.MaxLatency {
-		result.Error = fmt.Sprintf("latency too high: %v > %v", result.Latency, t.opts.MaxLatency)
-		result.IsOnline = false
-		return result
+	// 读取响应体并测量下载速度
+	transferStart := time.Now()
+	n, _ := io.Copy(io.Discard, io.LimitReader(resp.Body, maxProbeBody))
+	result.Bytes = n
+	if transfer := time.Since(transferStart); n >= minThroughputBytes && transfer > 0 {
+		result.Throughput = float64(n) / transfer.Seconds()
 	}
+	return true
+}
 
-	// 执行自定义验证
-	if target.Validate != nil {
-		if err := target.Validate(result); err != nil {
-			result.Error = fmt.Sprintf("validation failed: %v", err)
-			result.IsOnline = false
-			return result
-		}
+// probeTCP 只建立 TCP 连接探测目标，延迟包含 DNS 解析时间
+// 直接连接目标，不经过代理
+func (t *DefaultTester) probeTCP(ctx, parent context.Context, target Target, result *Result) bool {
+	address, err := hostPort(target.URL)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid url: %v", err)
+		result.Status = StatusDown
+		result.ErrorKind = ErrorOther
+		return false
 	}
 
-	result.IsOnline = true
-	return result
+	start := time.Now()
+	conn, err := t.dialer.DialContext(ctx, "tcp", address)
+	result.Latency = time.Since(start)
+	if err != nil {
+		setFailure(parent, result, err)
+		return false
+	}
+	conn.Close()
+
+	result.Phases.Connect = result.Latency
+	result.Status = StatusOnline
+	return true
+}
+
+// setFailure 记录请求失败，调用方取消（而非单个请求超时）时标记为已取消
+func setFailure(parent context.Context, result *Result, err error) {
+	if parent.Err() != nil {
+		result.Error = "test cancelled"
+		result.Status = StatusCancelled
+		return
+	}
+	result.Error = fmt.Sprintf("request failed: %v", err)
+	result.Status = StatusDown
+	result.ErrorKind = classifyError(err)
 }
@@ -8,12 +8,37 @@ import (
 
 // Result 表示延迟测试的结果
 type Result struct {
-	Name     string        // 目标名称
-	URL      string        // 测试的 URL
-	IsOnline bool          // 是否在线
-	Latency  time.Duration // 延迟时间
-	Error    string        // 错误信息
-	TestTime time.Time     // 测试时间
+	Name       string        // 目标名称
+	URL        string        // 测试的 URL
+	Status     Status        // 目标状态
+	ErrorKind  ErrorKind     // 错误类别
+	StatusCode int           // HTTP 状态码，未收到响应时为 0
+	Latency    time.Duration // 延迟时间
+	Error      string        // 错误信息
+	TestTime   time.Time     // 测试时间
+	Phases     Phases        // 各阶段耗时
+
+	Bytes      int64   // 读取的响应体字节数
+	Throughput float64 // 响应体下载速度（字节/秒），响应体太小时为 0
+}
+
+// Phases 表示一次请求中各阶段的耗时
+// 复用已有连接时 DNS、Connect、TLS 为 0
+type Phases struct {
+	DNS     time.Duration // DNS 解析耗时
+	Connect time.Duration // TCP 连接耗时
+	TLS     time.Duration // TLS 握手耗时
+	TTFB    time.Duration // 从发送请求到收到首字节的耗时
+}
+
+// Reachable 判断目标是否可用（正常或降级）
+func (r *Result) Reachable() bool {
+	return r.Status.Reachable()
+}
+
+// Cancelled 判断测试是否因取消而中断
+func (r *Result) Cancelled() bool {
+	return r.Status == StatusCancelled
 }
 
 // Target 表示一个测试目标
@@ -21,6 +46,7 @@ type Target struct {
 	Name     string         // 目标名称
 	URL      string         // 目标 URL
 	TestPath string         // 测试路径
+	Probe    Probe          // 探测方式
 	Headers  http.Header    // 请求头
 	Timeout  time.Duration  // 超时时间
 	Validate ValidationFunc // 自定义验证函数
@@ -35,6 +61,12 @@ type Options struct {
 	MaxLatency  time.Duration // 最大可接受延迟
 	UserAgent   string        // User-Agent 头
 	Concurrency int           // 并发测试数量
+	Samples     int           // 每个目标的测试次数，取延迟的中位数
+
+	Proxy     ProxyConfig       // 代理配置
+	Resolve   map[string]string // 自定义主机解析，主机（或 host:port）到 IP 的映射
+	DNSServer string            // 自定义 DNS 服务器（ip:port），为空时使用系统解析
+	Family    string            // 地址族："4"、"6"，为空时由系统决定
 }
 
 // DefaultOptions 返回默认的测试选项
@@ -44,6 +76,7 @@ func DefaultOptions() *Options {
 		MaxLatency:  3 * time.Second,
 		UserAgent:   "NRMG-Latency-Tester/1.0",
 		Concurrency: 5,
+		Samples:     1,
 	}
 }
 
@@ -62,6 +95,22 @@ func (t Target) WithTestPath(path string) Target {
 	return t
 }
 
+// WithProbe 设置探测方式，同时设置对应的测试路径与 Accept 头
+func (t Target) WithProbe(probe Probe) Target {
+	t.Probe = probe
+	t.TestPath = probe.Path
+
+	headers := t.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	if probe.Accept != "" {
+		headers.Set("Accept", probe.Accept)
+	}
+	t.Headers = headers
+	return t
+}
+
 // WithTimeout 设置超时时间
 func (t Target) WithTimeout(timeout time.Duration) Target {
 	t.Timeout = timeout
@@ -114,3 +163,15 @@ func (o *Options) WithConcurrency(concurrency int) *Options {
 	o.Concurrency = concurrency
 	return o
 }
+
+// WithSamples 设置每个目标的测试次数
+func (o *Options) WithSamples(samples int) *Options {
+	o.Samples = samples
+	return o
+}
+
+// WithFamily 设置连接使用的地址族
+func (o *Options) WithFamily(family string) *Options {
+	o.Family = family
+	return o
+}
@@ -1,54 +1,64 @@
 package registry
 
 // builtinRegistries 定义内置的 registry 列表
+// 公共镜像都支持包元数据接口，默认请求体积较小的 package.json 包；
+// skimdb 是 CouchDB 副本，不支持精简版元数据，请求根路径即可
 var builtinRegistries = map[string]*Info{
 	"npm": {
 		Name:        "npm",
 		URL:         "https://registry.npmjs.org/",
 		Home:        "https://www.npmjs.org",
 		Description: "npm official",
+		Probe:       "packument:package.json",
 	},
 	"yarn": {
 		Name:        "yarn",
 		URL:         "https://registry.yarnpkg.com/",
 		Home:        "https://yarnpkg.com",
 		Description: "yarn official",
+		Probe:       "packument:package.json",
 	},
 	"taobao": {
 		Name:        "taobao",
 		URL:         "https://registry.npmmirror.com/",
 		Home:        "https://npmmirror.com",
 		Description: "Taobao npm mirror",
+		Probe:       "packument:package.json",
 	},
 	"tencent": {
 		Name:        "tencent",
 		URL:         "https://mirrors.tencent.com/npm/",
 		Home:        "https://mirrors.tencent.com/help/npm.html",
 		Description: "Tencent npm mirror",
+		Probe:       "packument:package.json",
 	},
 	"npmMirror": {
 		Name:        "npmMirror",
 		URL:         "https://skimdb.npmjs.com/registry/",
 		Home:        "https://skimdb.npmjs.com/",
 		Description: "npm mirror",
+		Probe:       "root",
 	},
 	"huawei": {
 		Name:        "huawei",
 		URL:         "https://repo.huaweicloud.com/repository/npm/",
 		Home:        "https://www.huaweicloud.com/special/npm-jingxiang.html",
 		Description: "Huawei npm mirror",
+		Probe:       "packument:package.json",
 	},
 	"ustc": {
 		Name:        "ustc",
 		URL:         "https://npmreg.proxy.ustclug.org/",
 		Home:        "https://mirrors.ustc.edu.cn/help/npm.html",
 		Description: "USTC npm mirror",
+		Probe:       "packument:package.json",
 	},
 	"nju": {
 		Name:        "nju",
 		URL:         "https://repo.nju.edu.cn/repository/npm/",
 		Home:        "https://doc.nju.edu.cn/books/35f4a/page/npm",
 		Description: "NJU npm mirror",
+		Probe:       "packument:package.json",
 	},
 }
 
@@ -40,3 +40,13 @@ type ErrInvalidRegistry struct {
 func (e *ErrInvalidRegistry) Error() string {
 	return fmt.Sprintf("invalid registry %s: %s", e.Name, e.Reason)
 }
+
+// ErrInsecureRegistry 表示 registry 使用明文 HTTP，且配置要求拒绝切换
+type ErrInsecureRegistry struct {
+	Name string
+	URL  string
+}
+
+func (e *ErrInsecureRegistry) Error() string {
+	return fmt.Sprintf("registry %s uses plain http (%s), refused by security.refuse_insecure", e.Name, e.URL)
+}
@@ -3,13 +3,24 @@ package registry
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"sort"
+	"time"
 
+	"nrmgo/internal/audit"
+	"nrmgo/internal/bench"
 	"nrmgo/internal/checker"
 	"nrmgo/internal/config"
+	"nrmgo/internal/freshness"
+	"nrmgo/internal/history"
 	"nrmgo/internal/latency"
+	"nrmgo/internal/verify"
 )
 
+// onlineCheckTimeout 检测网络是否可用的超时时间
+const onlineCheckTimeout = 1500 * time.Millisecond
+
 // manager 实现了 Manager 接口
 type manager struct {
 	cfg *config.Config
@@ -20,6 +31,44 @@ func NewManager(cfg *config.Config) Manager {
 	return &manager{cfg: cfg}
 }
 
+// options 返回访问 registry 使用的网络选项，代理按 npm 的规则解析
+func (m *manager) options() *latency.Options {
+	opts := latency.OptionsFromConfig(m.cfg)
+	opts.Proxy = ResolveProxy(m.cfg)
+	return opts
+}
+
+// probeOptions 返回延迟测试使用的网络选项
+// 指定地址族时直接连接 registry：通过代理时地址族只作用于到代理的连接
+func (m *manager) probeOptions() *latency.Options {
+	opts := m.options()
+	if opts.Family != "" {
+		opts.Proxy = latency.ProxyConfig{}
+	}
+	return opts
+}
+
+// Proxied 返回指定 registry 中按代理设置通过代理访问的 registry，未指定时检查所有 registry
+// 不考虑指定地址族时绕过代理的情况
+func (m *manager) Proxied(names ...string) []*Info {
+	proxy := ResolveProxy(m.cfg).ProxyFunc()
+	if proxy == nil {
+		return nil
+	}
+
+	var proxied []*Info
+	for _, reg := range m.resolve(names) {
+		u, err := url.Parse(reg.URL)
+		if err != nil {
+			continue
+		}
+		if proxyURL, err := proxy(&http.Request{URL: u}); err == nil && proxyURL != nil {
+			proxied = append(proxied, reg)
+		}
+	}
+	return proxied
+}
+
 // List 列出所有可用的 registry
 func (m *manager) List() []*Info {
 	// 获取所有内置 registry
@@ -83,8 +132,9 @@ func (m *manager) Remove(name string) error {
 		return fmt.Errorf("registry not found: %s", name)
 	}
 
-	// 删除 registry
+	// 删除 registry 以及按名称保存的设置
 	delete(m.cfg.CustomRegistries, name)
+	m.cfg.RenameRegistrySettings(name, "")
 
 	// 保存配置
 	return config.SaveConfig(m.cfg)
@@ -98,6 +148,11 @@ func (m *manager) Use(name string) error {
 		return fmt.Errorf("registry not found: %s", name)
 	}
 
+	// 配置要求时拒绝切换到明文 HTTP 的 registry
+	if m.cfg.Security.RefuseInsecure && IsInsecureURL(reg.URL) {
+		return &ErrInsecureRegistry{Name: reg.Name, URL: reg.URL}
+	}
+
 	// 获取已安装的包管理器
 	installedPMs := checker.DetectPackageManagers()
 
@@ -136,51 +191,213 @@ func (m *manager) Current() (*Info, error) {
 	}, nil
 }
 
-// Test 测试指定 registry 的延迟
-func (m *manager) Test(names ...string) []*TestResult {
-	var registries []*Info
-
-	// 如果没有指定名称，测试所有 registry
+// resolve 根据名称获取 registry 列表，未指定名称时返回所有 registry
+func (m *manager) resolve(names []string) []*Info {
 	if len(names) == 0 {
-		registries = m.List()
-	} else {
-		// 否则只测试指定的 registry
-		for _, name := range names {
-			if reg, ok := m.Get(name); ok {
-				registries = append(registries, reg)
-			}
+		return m.List()
+	}
+
+	var registries []*Info
+	for _, name := range names {
+		if reg, ok := m.Get(name); ok {
+			registries = append(registries, reg)
 		}
 	}
+	return registries
+}
+
+// targets 为 registry 创建延迟测试目标
+// 包管理器配置中有匹配的凭据时，测试请求会携带认证信息
+func (m *manager) targets(registries []*Info) []latency.Target {
+	credentials := checker.LoadCredentials()
 
-	// 创建测试目标
 	targets := make([]latency.Target, len(registries))
 	for i, reg := range registries {
 		targets[i] = latency.NewTarget(reg.Name, reg.URL).
-			WithTestPath("package.json").
-			WithHeaders(map[string][]string{
-				"Accept": {"application/json"},
-			})
+			WithProbe(m.probe(reg)).
+			WithTimeout(m.cfg.Latency.TimeoutFor(reg.Name))
+
+		// 私有 registry 需要认证才能访问，凭据只用于请求头，不会被记录或输出
+		if credential, ok := credentials.Lookup(reg.URL); ok {
+			targets[i].Headers.Set("Authorization", credential.Header())
+		}
+	}
+	return targets
+}
+
+// probe 获取 registry 的探测方式，[probes] 中的配置优先
+// 配置在加载时已验证，解析失败时退回到默认探测方式
+func (m *manager) probe(reg *Info) latency.Probe {
+	spec, expectStatus := reg.Probe, reg.ExpectStatus
+	if override, ok := m.cfg.Probes[reg.Name]; ok && override != nil {
+		spec, expectStatus = override.Probe, override.ExpectStatus
 	}
 
+	probe, err := latency.ParseProbe(spec, expectStatus)
+	if err != nil {
+		probe, _ = latency.ParseProbe("", nil)
+	}
+	return probe
+}
+
+// Test 测试指定 registry 的延迟
+func (m *manager) Test(ctx context.Context, names ...string) []*TestResult {
 	// 创建测试器并执行测试
-	tester := latency.NewTesterFromConfig(m.cfg)
-	results := tester.Test(context.Background(), targets)
+	tester := latency.NewTester(m.probeOptions())
+	results := tester.Test(ctx, m.targets(m.resolve(names)))
+
+	// 保存测试结果到历史记录
+	m.record(results)
 
 	// 转换结果
 	testResults := make([]*TestResult, len(results))
 	for i, result := range results {
-		testResults[i] = &TestResult{
-			Name:     result.Name,
-			URL:      result.URL,
-			IsOnline: result.IsOnline,
-			Latency:  result.Latency,
-			Error:    result.Error,
-		}
+		testResults[i] = toTestResult(result)
 	}
 
 	return testResults
 }
 
+// TestStream 测试指定 registry 的延迟，每完成一个 registry 就通过 channel 返回结果
+// 所有测试结束（或 ctx 取消）后关闭 channel，并保存已完成的结果到历史记录
+func (m *manager) TestStream(ctx context.Context, names ...string) <-chan *TestResult {
+	tester := latency.NewTester(m.probeOptions())
+	stream := tester.TestStream(ctx, m.targets(m.resolve(names)))
+
+	out := make(chan *TestResult)
+	go func() {
+		defer close(out)
+
+		var results []*latency.Result
+		for result := range stream {
+			results = append(results, result)
+			out <- toTestResult(result)
+		}
+
+		// 保存测试结果到历史记录
+		m.record(results)
+	}()
+	return out
+}
+
+// Race 以竞速模式测试指定 registry，在某个 registry 明显领先或预算耗尽时停止
+func (m *manager) Race(ctx context.Context, budget time.Duration, names ...string) *latency.RaceResult {
+	opts := latency.DefaultRaceOptions()
+	opts.Budget = budget
+
+	tester := latency.NewTester(m.probeOptions())
+	race := latency.Race(ctx, tester, m.targets(m.resolve(names)), opts)
+
+	// 保存测试结果到历史记录
+	m.record(race.Results)
+
+	return race
+}
+
+// CompareFamilies 分别通过 IPv4 与 IPv6 测试指定 registry 的延迟
+// 结果只用于对比地址族，不保存到历史记录
+func (m *manager) CompareFamilies(ctx context.Context, names ...string) []*latency.FamilyResult {
+	return latency.CompareFamilies(ctx, m.probeOptions(), m.targets(m.resolve(names)))
+}
+
+// toTestResult 将延迟测试结果转换为 registry 测试结果
+func toTestResult(result *latency.Result) *TestResult {
+	return &TestResult{
+		Name:       result.Name,
+		URL:        result.URL,
+		Status:     result.Status,
+		ErrorKind:  result.ErrorKind,
+		StatusCode: result.StatusCode,
+		Latency:    result.Latency,
+		Error:      result.Error,
+
+		Throughput: result.Throughput,
+	}
+}
+
+// record 将测试结果保存到历史记录
+// 历史记录只用于统计，写入失败不影响测试结果
+func (m *manager) record(results []*latency.Result) {
+	store, err := history.NewStoreFromConfig(m.cfg)
+	if err != nil {
+		return
+	}
+
+	records := make([]history.Record, 0, len(results))
+	for _, result := range results {
+		// 被取消的测试不代表 registry 的真实状态，不写入历史记录
+		if result != nil && !result.Cancelled() {
+			records = append(records, history.FromResult(result))
+		}
+	}
+	_ = store.Append(records...)
+}
+
+// Freshness 检测指定 registry 相对上游的同步延迟，ctx 取消时停止剩余的请求
+func (m *manager) Freshness(ctx context.Context, names ...string) []*freshness.Result {
+	registries := m.resolve(names)
+
+	targets := make([]freshness.Target, len(registries))
+	for i, reg := range registries {
+		targets[i] = freshness.Target{Name: reg.Name, URL: reg.URL}
+	}
+
+	checker := freshness.NewCheckerFromConfig(m.cfg, m.options())
+	return checker.Check(ctx, targets)
+}
+
+// Bench 依次在指定 registry 上解析项目的完整依赖元数据
+// 与延迟测试一样，匹配的凭据只用于请求头
+func (m *manager) Bench(ctx context.Context, project *bench.Project, concurrency int, names ...string) <-chan *bench.Result {
+	registries := m.resolve(names)
+	credentials := checker.LoadCredentials()
+
+	targets := make([]bench.Target, len(registries))
+	for i, reg := range registries {
+		targets[i] = bench.Target{Name: reg.Name, URL: reg.URL, Headers: make(http.Header)}
+		if credential, ok := credentials.Lookup(reg.URL); ok {
+			targets[i].Headers.Set("Authorization", credential.Header())
+		}
+	}
+
+	return bench.NewBencher(m.options(), concurrency).Bench(ctx, project, targets)
+}
+
+// DetectOnline 检测网络是否可用，无法访问任何 registry 时返回 false
+func (m *manager) DetectOnline(ctx context.Context) bool {
+	registries := m.List()
+	urls := make([]string, len(registries))
+	for i, reg := range registries {
+		urls[i] = reg.URL
+	}
+	return latency.DetectOnline(ctx, m.options(), urls, onlineCheckTimeout)
+}
+
+// Audit 审计指定 registry 的传输安全
+func (m *manager) Audit(ctx context.Context, names ...string) []*audit.Result {
+	registries := m.resolve(names)
+
+	targets := make([]audit.Target, len(registries))
+	for i, reg := range registries {
+		targets[i] = audit.Target{Name: reg.Name, URL: reg.URL}
+	}
+
+	return audit.NewAuditorFromConfig(m.cfg, m.options()).Audit(ctx, targets)
+}
+
+// Verify 校验指定包版本在各个 registry 上的元数据与 tarball，ctx 取消时停止剩余的请求
+func (m *manager) Verify(ctx context.Context, spec verify.Spec, names ...string) ([]*verify.Result, error) {
+	registries := m.resolve(names)
+
+	targets := make([]verify.Target, len(registries))
+	for i, reg := range registries {
+		targets[i] = verify.Target{Name: reg.Name, URL: reg.URL}
+	}
+
+	verifier := verify.NewVerifierFromConfig(m.cfg, m.options())
+	return verifier.Verify(ctx, spec, targets)
+}
+
 // Rename 重命名 registry
 func (m *manager) Rename(oldName, newName string) error {
 	// 检查 old registry 是否存在
@@ -210,6 +427,9 @@ func (m *manager) Rename(oldName, newName string) error {
 		URL:         oldReg.URL,
 		Home:        oldReg.Home,
 		Description: oldReg.Description,
+
+		Probe:        oldReg.Probe,
+		ExpectStatus: oldReg.ExpectStatus,
 	}
 
 	// 获取当前使用的 registry
@@ -218,14 +438,16 @@ func (m *manager) Rename(oldName, newName string) error {
 	// 删除旧的 registry
 	delete(m.cfg.CustomRegistries, oldName)
 
-	// 添加新的 registry
+	// 添加新的 registry，偏好、超时与探测方式随之改名
 	m.cfg.CustomRegistries[newName] = newReg.ToConfig()
+	m.cfg.RenameRegistrySettings(oldName, newName)
 
 	// 保存配置
 	if err := config.SaveConfig(m.cfg); err != nil {
 		// 如果保存失败，恢复原状态
 		delete(m.cfg.CustomRegistries, newName)
 		m.cfg.CustomRegistries[oldName] = oldReg.ToConfig()
+		m.cfg.RenameRegistrySettings(newName, oldName)
 		return fmt.Errorf("failed to save config: %v", err)
 	}
 
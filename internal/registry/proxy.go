@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"nrmgo/internal/checker"
+	"nrmgo/internal/config"
+	"nrmgo/internal/latency"
+)
+
+// ResolveProxy 解析访问 registry 使用的代理，与 npm 一致：
+// npm_config_* 环境变量与项目、用户、全局 .npmrc 中的 proxy、https-proxy、noproxy（或 no-proxy）
+// 优先于 HTTP_PROXY、HTTPS_PROXY、NO_PROXY 环境变量，配置文件中的 latency.proxy 优先于以上所有设置
+func ResolveProxy(cfg *config.Config) latency.ProxyConfig {
+	if cfg != nil && cfg.Latency.Proxy == config.ProxyDirect {
+		return latency.ProxyConfig{}
+	}
+
+	proxy := latency.ProxyFromEnvironment()
+	values := checker.ResolveNPMConfig("proxy", "https-proxy", "noproxy", "no-proxy")
+	if value := values["proxy"]; value != "" {
+		proxy.HTTPProxy = value
+	}
+	if value := values["https-proxy"]; value != "" {
+		proxy.HTTPSProxy = value
+	}
+	if value := values["noproxy"]; value != "" {
+		proxy.NoProxy = value
+	} else if value := values["no-proxy"]; value != "" {
+		proxy.NoProxy = value
+	}
+
+	if cfg != nil && cfg.Latency.Proxy != "" {
+		proxy.HTTPProxy = cfg.Latency.Proxy
+		proxy.HTTPSProxy = cfg.Latency.Proxy
+	}
+	return proxy
+}
@@ -1,12 +1,19 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"regexp"
+	"strings"
 	"time"
 
+	"nrmgo/internal/audit"
+	"nrmgo/internal/bench"
 	"nrmgo/internal/config"
+	"nrmgo/internal/freshness"
+	"nrmgo/internal/latency"
+	"nrmgo/internal/verify"
 )
 
 // Info 表示一个 npm registry 的完整信息
@@ -15,6 +22,9 @@ type Info struct {
 	URL         string `toml:"url"`         // registry URL
 	Home        string `toml:"home"`        // 主页地址
 	Description string `toml:"description"` // 描述信息
+
+	Probe        string `toml:"probe"`         // 延迟测试的探测方式，为空时使用默认探测方式
+	ExpectStatus []int  `toml:"expect_status"` // 视为成功的 HTTP 状态码
 }
 
 // FromConfig 从配置创建 Info
@@ -24,6 +34,9 @@ func FromConfig(name string, cfg *config.Registry) *Info {
 		URL:         cfg.URL,
 		Home:        cfg.Home,
 		Description: cfg.Description,
+
+		Probe:        cfg.Probe,
+		ExpectStatus: cfg.ExpectStatus,
 	}
 }
 
@@ -33,16 +46,33 @@ func (r *Info) ToConfig() *config.Registry {
 		URL:         r.URL,
 		Home:        r.Home,
 		Description: r.Description,
+
+		Probe:        r.Probe,
+		ExpectStatus: r.ExpectStatus,
 	}
 }
 
 // TestResult 表示 registry 的测试结果
 type TestResult struct {
-	Name     string        // registry 名称
-	URL      string        // registry URL
-	IsOnline bool          // 是否在线
-	Latency  time.Duration // 延迟时间
-	Error    string        // 错误信息
+	Name       string            // registry 名称
+	URL        string            // registry URL
+	Status     latency.Status    // 状态
+	ErrorKind  latency.ErrorKind // 错误类别
+	StatusCode int               // HTTP 状态码，未收到响应时为 0
+	Latency    time.Duration     // 延迟时间
+	Error      string            // 错误信息
+
+	Throughput float64 // 下载速度（字节/秒），0 表示没有数据
+}
+
+// Cancelled 判断测试是否因取消而中断
+func (r *TestResult) Cancelled() bool {
+	return r.Status == latency.StatusCancelled
+}
+
+// Reachable 判断 registry 是否可用（正常或降级）
+func (r *TestResult) Reachable() bool {
+	return r.Status.Reachable()
 }
 
 // Manager 定义 registry 管理器接口
@@ -56,7 +86,7 @@ type Manager interface {
 	// Add 添加自定义 registry
 	Add(name string, reg *Info) error
 
-	// Remove 移除自定义 registry
+	// Remove 移除自定义 registry，同时删除按名称保存的偏好、超时与探测方式
 	Remove(name string) error
 
 	// Use 切换当前使用的 registry
@@ -65,14 +95,42 @@ type Manager interface {
 	// Current 获取当前使用的 registry
 	Current() (*Info, error)
 
-	// Test 测试指定 registry 的延迟
-	Test(names ...string) []*TestResult
+	// Test 测试指定 registry 的延迟，ctx 取消时只返回已完成的结果
+	Test(ctx context.Context, names ...string) []*TestResult
+
+	// TestStream 测试指定 registry 的延迟，每完成一个 registry 就通过 channel 返回结果
+	TestStream(ctx context.Context, names ...string) <-chan *TestResult
+
+	// Race 以竞速模式测试指定 registry，在某个 registry 明显领先或预算耗尽时停止
+	Race(ctx context.Context, budget time.Duration, names ...string) *latency.RaceResult
+
+	// CompareFamilies 分别通过 IPv4 与 IPv6 测试指定 registry 的延迟
+	CompareFamilies(ctx context.Context, names ...string) []*latency.FamilyResult
+
+	// Bench 依次在指定 registry 上解析项目的完整依赖元数据，每完成一个 registry 就通过 channel 返回结果
+	Bench(ctx context.Context, project *bench.Project, concurrency int, names ...string) <-chan *bench.Result
+
+	// Proxied 返回指定 registry 中按代理设置通过代理访问的 registry，未指定时检查所有 registry
+	Proxied(names ...string) []*Info
+
+	// DetectOnline 检测网络是否可用，无法访问任何 registry 时返回 false
+	DetectOnline(ctx context.Context) bool
+
+	// Audit 审计指定 registry 的传输安全
+	Audit(ctx context.Context, names ...string) []*audit.Result
+
+	// Freshness 检测指定 registry 相对上游的同步延迟
+	Freshness(ctx context.Context, names ...string) []*freshness.Result
+
+	// Verify 校验指定包版本在各个 registry 上的元数据与 tarball
+	Verify(ctx context.Context, spec verify.Spec, names ...string) ([]*verify.Result, error)
 
 	// Rename 重命名 registry
 	// 规则：
 	// 1. oldName 必须存在且不能是内置 registry
 	// 2. newName 不能是内置 registry 名称且不能已存在
 	// 3. 如果重命名的是当前使用的 registry，会自动更新
+	// 4. 按名称保存的偏好、超时与探测方式随之改名
 	Rename(oldName, newName string) error
 }
 
@@ -86,6 +144,12 @@ func NewRegistry(name, url, home, description string) *Info {
 	}
 }
 
+// IsInsecureURL 判断 registry URL 是否使用明文 HTTP
+func IsInsecureURL(registryURL string) bool {
+	parsedURL, err := url.Parse(registryURL)
+	return err == nil && strings.EqualFold(parsedURL.Scheme, "http")
+}
+
 // IsValidName 验证 registry name 是否合法
 // 规则：只允许字母、数字和下划线
 func IsValidName(name string) error {
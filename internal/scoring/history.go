@@ -0,0 +1,53 @@
+package scoring
+
+import (
+	"sort"
+	"time"
+
+	"nrmgo/internal/history"
+)
+
+// InputFromHistory 根据历史记录计算 registry 的延迟中位数、成功率与下载速度中位数
+func InputFromHistory(name string, records []history.Record) Input {
+	in := Input{Name: name}
+	if len(records) == 0 {
+		return in
+	}
+
+	var (
+		latencies   []time.Duration
+		throughputs []float64
+		success     int
+	)
+	for _, record := range records {
+		if !record.Online {
+			continue
+		}
+		success++
+		latencies = append(latencies, record.Latency.Duration())
+		if record.Throughput > 0 {
+			throughputs = append(throughputs, record.Throughput)
+		}
+	}
+
+	in.Latency = history.Median(latencies)
+	in.SuccessRate = float64(success) / float64(len(records))
+	in.Throughput = medianFloat(throughputs)
+	return in
+}
+
+// medianFloat 计算浮点数的中位数
+func medianFloat(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
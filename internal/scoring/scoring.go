@@ -0,0 +1,90 @@
+package scoring
+
+import (
+	"sort"
+
+	"nrmgo/internal/config"
+)
+
+// Score 根据策略为候选 registry 评分，结果按总分降序排列
+//
+// 每项指标先归一化到 0-1：延迟与下载速度相对最优者计算比例，成功率直接使用，
+// 同步延迟按 1/(1+小时数) 衰减。没有任何候选提供数据的指标不参与评分，
+// 其余权重按比例重新分配。最后根据 prefer/avoid 加减 BiasWeight。
+func (p Policy) Score(inputs []Input) []Breakdown {
+	var (
+		bestLatency    float64
+		bestThroughput float64
+		hasLag         bool
+	)
+	for _, in := range inputs {
+		if in.Latency > 0 && (bestLatency == 0 || float64(in.Latency) < bestLatency) {
+			bestLatency = float64(in.Latency)
+		}
+		if in.Throughput > bestThroughput {
+			bestThroughput = in.Throughput
+		}
+		if in.HasLag {
+			hasLag = true
+		}
+	}
+
+	// 没有数据的指标不参与评分
+	weights := p.Weights
+	if bestLatency == 0 {
+		weights.Latency = 0
+	}
+	if bestThroughput == 0 {
+		weights.Throughput = 0
+	}
+	if !hasLag {
+		weights.Freshness = 0
+	}
+	total := weights.Latency + weights.SuccessRate + weights.Freshness + weights.Throughput
+
+	breakdowns := make([]Breakdown, len(inputs))
+	for i, in := range inputs {
+		c := Components{SuccessRate: in.SuccessRate}
+		if in.Latency > 0 {
+			c.Latency = bestLatency / float64(in.Latency)
+		}
+		if bestThroughput > 0 {
+			c.Throughput = in.Throughput / bestThroughput
+		}
+		if in.HasLag && !in.LagFailed {
+			c.Freshness = 1 / (1 + in.Lag.Hours())
+		}
+
+		score := 0.0
+		if total > 0 {
+			score = (weights.Latency*c.Latency +
+				weights.SuccessRate*c.SuccessRate +
+				weights.Freshness*c.Freshness +
+				weights.Throughput*c.Throughput) / total
+		}
+
+		bias := p.Bias[in.Name]
+		switch bias {
+		case config.BiasPrefer:
+			score += p.BiasWeight
+		case config.BiasAvoid:
+			score -= p.BiasWeight
+		}
+
+		breakdowns[i] = Breakdown{
+			Input:      in,
+			Components: c,
+			Bias:       bias,
+			Score:      score,
+		}
+	}
+
+	// 降级的 registry 总是排在正常的 registry 之后
+	sort.SliceStable(breakdowns, func(i, j int) bool {
+		if breakdowns[i].Degraded != breakdowns[j].Degraded {
+			return !breakdowns[i].Degraded
+		}
+		return breakdowns[i].Score > breakdowns[j].Score
+	})
+	return breakdowns
+}
@@ -0,0 +1,57 @@
+package scoring
+
+import (
+	"time"
+
+	"nrmgo/internal/config"
+)
+
+// Input 表示一个候选 registry 的评分依据
+type Input struct {
+	Name        string        // registry 名称
+	Latency     time.Duration // 延迟中位数
+	SuccessRate float64       // 历史成功率（0-1）
+	Throughput  float64       // 下载速度中位数（字节/秒），0 表示没有数据
+	Lag         time.Duration // 同步延迟
+	HasLag      bool          // 是否检测了同步延迟
+	LagFailed   bool          // 同步延迟检测失败
+	Degraded    bool          // 本次测试为降级状态，排在正常的 registry 之后
+}
+
+// Components 表示归一化后的各项得分（0-1）
+type Components struct {
+	Latency     float64
+	SuccessRate float64
+	Freshness   float64
+	Throughput  float64
+}
+
+// Breakdown 表示一个 registry 的评分明细
+type Breakdown struct {
+	Input
+	Components Components // 各项得分
+	Bias       string     // prefer、avoid 或空
+	Score      float64    // 总分
+}
+
+// Policy 评分策略
+type Policy struct {
+	Weights    config.ScoringWeights // 各项指标的权重
+	BiasWeight float64               // prefer/avoid 的加减值
+	Bias       map[string]string     // 每个 registry 的偏好
+}
+
+// PolicyFromConfig 从配置创建评分策略
+func PolicyFromConfig(cfg *config.Config) Policy {
+	if cfg == nil {
+		return Policy{
+			Weights:    config.DefaultScoringWeights,
+			BiasWeight: config.DefaultBiasWeight,
+		}
+	}
+	return Policy{
+		Weights:    cfg.Scoring.Weights,
+		BiasWeight: cfg.Scoring.BiasWeightValue(),
+		Bias:       cfg.Scoring.Bias,
+	}
+}
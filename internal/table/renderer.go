@@ -29,11 +29,20 @@ func (t *TableRenderer) MustAddRow(row []string) {
 
 // Render 渲染表格
 func (t *TableRenderer) Render() error {
+	return t.printer().Render()
+}
+
+// Srender 将表格渲染为字符串，用于在动态区域中刷新表格
+func (t *TableRenderer) Srender() (string, error) {
+	return t.printer().Srender()
+}
+
+// printer 创建表格打印器
+func (t *TableRenderer) printer() *pterm.TablePrinter {
 	tableData := pterm.TableData{t.headers}
 	tableData = append(tableData, t.data...)
 	return pterm.DefaultTable.
 		WithHasHeader().
 		WithBoxed(true).
-		WithData(tableData).
-		Render()
+		WithData(tableData)
 }
@@ -0,0 +1,98 @@
+package verify
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strings"
+)
+
+// digests 保存下载内容的各类哈希值
+type digests struct {
+	sums map[string][]byte
+	size int64
+}
+
+// newHashers 创建 npm 使用到的哈希算法
+func newHashers() map[string]hash.Hash {
+	return map[string]hash.Hash{
+		"sha1":   sha1.New(),
+		"sha256": sha256.New(),
+		"sha384": sha512.New384(),
+		"sha512": sha512.New(),
+	}
+}
+
+// digest 读取全部内容并计算哈希
+func digest(r io.Reader) (*digests, error) {
+	hashers := newHashers()
+	writers := make([]io.Writer, 0, len(hashers))
+	for _, h := range hashers {
+		writers = append(writers, h)
+	}
+
+	size, err := io.Copy(io.MultiWriter(writers...), r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &digests{sums: make(map[string][]byte, len(hashers)), size: size}
+	for name, h := range hashers {
+		d.sums[name] = h.Sum(nil)
+	}
+	return d, nil
+}
+
+// matchIntegrity 校验 Subresource Integrity 字符串
+// 所有可识别的哈希都必须匹配，且至少需要一个可识别的哈希
+func (d *digests) matchIntegrity(integrity string) bool {
+	matched := false
+	for _, entry := range strings.Fields(integrity) {
+		alg, value, ok := strings.Cut(entry, "-")
+		if !ok {
+			continue
+		}
+		sum, known := d.sums[alg]
+		if !known {
+			continue
+		}
+		// 去掉可能存在的 ?opt 选项
+		value, _, _ = strings.Cut(value, "?")
+		expected, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || string(expected) != string(sum) {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// matchShasum 校验十六进制 SHA-1 哈希
+func (d *digests) matchShasum(shasum string) bool {
+	return shasum != "" && strings.EqualFold(shasum, hex.EncodeToString(d.sums["sha1"]))
+}
+
+// sameIntegrity 判断两个 integrity 字符串是否描述同一内容
+func sameIntegrity(a, b string) bool {
+	fields := func(s string) map[string]bool {
+		set := make(map[string]bool)
+		for _, f := range strings.Fields(s) {
+			set[f] = true
+		}
+		return set
+	}
+	fa, fb := fields(a), fields(b)
+	if len(fa) != len(fb) {
+		return false
+	}
+	for f := range fa {
+		if !fb[f] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Target 表示一个待校验的 registry
+type Target struct {
+	Name string // registry 名称
+	URL  string // registry 地址
+}
+
+// Spec 表示待校验的包及版本
+type Spec struct {
+	Name    string // 包名
+	Version string // 版本号
+}
+
+// String 实现 fmt.Stringer 接口
+func (s Spec) String() string {
+	return s.Name + "@" + s.Version
+}
+
+// ParseSpec 解析 <pkg>@<version> 格式的包描述，支持 scoped 包
+func ParseSpec(spec string) (Spec, error) {
+	index := strings.LastIndex(spec, "@")
+	if index <= 0 || index == len(spec)-1 {
+		return Spec{}, fmt.Errorf("invalid package spec %q, expected <pkg>@<version>", spec)
+	}
+	return Spec{Name: spec[:index], Version: spec[index+1:]}, nil
+}
+
+// Dist 表示版本元数据中的 dist 字段
+type Dist struct {
+	Integrity string `json:"integrity"` // Subresource Integrity 哈希
+	Shasum    string `json:"shasum"`    // SHA-1 哈希（十六进制）
+	Tarball   string `json:"tarball"`   // tarball 下载地址
+}
+
+// versionManifest 表示单个版本的元数据
+type versionManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Dist    Dist   `json:"dist"`
+}
+
+// Result 表示单个 registry 的校验结果
+type Result struct {
+	Name string // registry 名称
+	URL  string // registry 地址
+	Dist Dist   // registry 返回的 dist 信息
+	Size int64  // 下载的 tarball 大小
+
+	MetadataMatch bool // dist.integrity/shasum 与上游一致
+	IntegrityOK   bool // 下载内容与 dist.integrity 一致
+	ShasumOK      bool // 下载内容与 dist.shasum 一致
+	UpstreamOK    bool // 下载内容与上游的 dist.integrity 一致
+
+	Error string // 错误信息
+}
+
+// Passed 判断是否通过所有校验
+func (r *Result) Passed() bool {
+	return r.Error == "" && r.MetadataMatch && r.IntegrityOK && r.ShasumOK && r.UpstreamOK
+}
@@ -0,0 +1,214 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"nrmgo/internal/config"
+	"nrmgo/internal/latency"
+)
+
+const (
+	// defaultTimeout 下载 tarball 的超时时间
+	defaultTimeout = 60 * time.Second
+	// defaultConcurrency 默认并发校验数量
+	defaultConcurrency = 5
+)
+
+// Verifier 跨镜像 tarball 完整性校验器
+type Verifier struct {
+	client      *http.Client
+	userAgent   string
+	upstream    Target
+	concurrency int
+}
+
+// NewVerifier 创建校验器，upstream 为作为基准的上游 registry
+func NewVerifier(upstream Target) *Verifier {
+	return newVerifier(upstream, latency.DefaultOptions().WithTimeout(defaultTimeout))
+}
+
+// newVerifier 使用 opts 中的代理、解析配置与 User-Agent 创建校验器
+func newVerifier(upstream Target, opts *latency.Options) *Verifier {
+	return &Verifier{
+		client:      latency.NewHTTPClient(opts),
+		userAgent:   opts.UserAgent,
+		upstream:    upstream,
+		concurrency: defaultConcurrency,
+	}
+}
+
+// NewVerifierFromConfig 从配置创建校验器，上游 registry 与 freshness 检测共用
+// opts 为与延迟测试相同的网络选项
+func NewVerifierFromConfig(cfg *config.Config, opts *latency.Options) *Verifier {
+	upstream := Target{Name: "upstream", URL: config.DefaultUpstream}
+	concurrency := defaultConcurrency
+	if cfg != nil {
+		if cfg.Freshness.Upstream != "" {
+			upstream.URL = cfg.Freshness.Upstream
+		}
+		if n := cfg.LatencyConcurrency(); n > 0 {
+			concurrency = n
+		}
+	}
+
+	// 与延迟测试使用相同的代理、解析配置与 User-Agent
+	v := newVerifier(upstream, opts.WithTimeout(defaultTimeout))
+	v.concurrency = concurrency
+	return v
+}
+
+// Verify 校验指定包版本在各个 registry 上的元数据与 tarball
+func (v *Verifier) Verify(ctx context.Context, spec Spec, targets []Target) ([]*Result, error) {
+	// 获取上游的元数据作为基准
+	upstream, err := v.fetchManifest(ctx, v.upstream.URL, spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch upstream metadata: %w", err)
+	}
+	if upstream.Dist.Integrity == "" && upstream.Dist.Shasum == "" {
+		return nil, fmt.Errorf("upstream metadata of %s has no integrity or shasum", spec)
+	}
+
+	results := make([]*Result, len(targets))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, v.concurrency)
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(index int, tgt Target) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // 获取信号量
+			defer func() { <-semaphore }() // 释放信号量
+
+			results[index] = v.verifyOne(ctx, spec, tgt, upstream.Dist)
+		}(i, target)
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// verifyOne 校验单个 registry
+func (v *Verifier) verifyOne(ctx context.Context, spec Spec, target Target, upstream Dist) *Result {
+	result := &Result{
+		Name: target.Name,
+		URL:  target.URL,
+	}
+
+	manifest, err := v.fetchManifest(ctx, target.URL, spec)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Dist = manifest.Dist
+
+	// 比较元数据中的哈希
+	result.MetadataMatch = sameIntegrity(manifest.Dist.Integrity, upstream.Integrity) &&
+		strings.EqualFold(manifest.Dist.Shasum, upstream.Shasum)
+
+	// 下载 tarball 并计算哈希
+	if manifest.Dist.Tarball == "" {
+		result.Error = "metadata has no dist.tarball"
+		return result
+	}
+	d, err := v.download(ctx, manifest.Dist.Tarball)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Size = d.size
+
+	// 校验下载内容与 registry 自身声明的哈希，以及上游声明的哈希
+	result.IntegrityOK = d.matchIntegrity(manifest.Dist.Integrity)
+	result.ShasumOK = d.matchShasum(manifest.Dist.Shasum)
+	result.UpstreamOK = d.matchIntegrity(upstream.Integrity) || (upstream.Integrity == "" && d.matchShasum(upstream.Shasum))
+
+	// 旧版本的包可能只有 shasum 没有 integrity
+	if manifest.Dist.Integrity == "" && result.ShasumOK {
+		result.IntegrityOK = true
+	}
+
+	return result
+}
+
+// fetchManifest 获取指定版本的元数据
+// 部分镜像不支持 /<pkg>/<version> 接口，此时退回到完整 packument
+func (v *Verifier) fetchManifest(ctx context.Context, registryURL string, spec Spec) (*versionManifest, error) {
+	base := strings.TrimSuffix(registryURL, "/") + "/" + url.PathEscape(spec.Name)
+
+	var manifest versionManifest
+	status, err := v.getJSON(ctx, base+"/"+url.PathEscape(spec.Version), &manifest)
+	if err == nil && manifest.Version == spec.Version {
+		return &manifest, nil
+	}
+	if status == http.StatusNotFound || (err == nil && manifest.Version != spec.Version) {
+		var doc struct {
+			Versions map[string]versionManifest `json:"versions"`
+		}
+		if _, err := v.getJSON(ctx, base, &doc); err != nil {
+			return nil, err
+		}
+		manifest, ok := doc.Versions[spec.Version]
+		if !ok {
+			return nil, fmt.Errorf("version %s not found", spec.Version)
+		}
+		return &manifest, nil
+	}
+	return nil, err
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应，返回 HTTP 状态码
+func (v *Verifier) getJSON(ctx context.Context, rawURL string, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", v.userAgent)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// download 下载 tarball 并计算哈希
+func (v *Verifier) download(ctx context.Context, tarballURL string) (*digests, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tarballURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", v.userAgent)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tarball download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tarball download failed: HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	d, err := digest(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("tarball download failed: %w", err)
+	}
+	return d, nil
+}